@@ -61,6 +61,24 @@ func main() {
 	// Start background worker for running checks
 	go worker.StartCheckRunner(db)
 
+	// Start background worker for delivering queued notifications
+	go worker.StartNotificationDispatcher(db)
+
+	// Start background worker for escalating unacknowledged alerts
+	go worker.StartEscalationWorker(db)
+
+	// Start background worker for sending scheduled uptime digests
+	go worker.StartDigestWorker(db)
+
+	// Start background worker for evaluating log alert rules
+	go worker.StartLogAlertWorker(db)
+
+	// Start background worker for evaluating trace alert rules
+	go worker.StartTraceAlertWorker(db)
+
+	// Start background worker for notifying owners about plan usage thresholds
+	go worker.StartUsageThresholdWorker(db)
+
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
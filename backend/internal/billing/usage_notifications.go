@@ -0,0 +1,145 @@
+package billing
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sort"
+    "time"
+
+    "github.com/oFuterman/light-house/internal/models"
+    "github.com/oFuterman/light-house/internal/notifier"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// usageEmailTimeout bounds a single usage notification email
+const usageEmailTimeout = 30 * time.Second
+
+// usageResource describes a resource CheckEntitlements reports a threshold for
+type usageResource struct {
+    Label   string
+    AtLimit string // What stops working at the limit
+}
+
+var usageResources = map[string]usageResource{
+    "checks":       {"Uptime checks", "New checks can't be created until you upgrade or remove some."},
+    "log_volume":   {"Log volume", "Logs are still accepted up to 150% of the limit; after that ingestion is rejected until next month or an upgrade."},
+    "status_pages": {"Status pages", "New status pages can't be created until you upgrade or remove some."},
+    "api_keys":     {"API keys", "New API keys can't be created until you upgrade or revoke some."},
+    "ai_level1":    {"AI Level 1 requests", "AI Level 1 requests are refused until next month or an upgrade."},
+    "ai_level2":    {"AI Level 2 requests", "AI Level 2 requests are refused until next month or an upgrade."},
+    "ai_level3":    {"AI Level 3 requests", "AI Level 3 requests are refused until next month or an upgrade."},
+}
+
+// UsageLevels returns the usage levels a ratio has reached, lowest first
+func UsageLevels(ratio float64) []models.UsageLevel {
+    var levels []models.UsageLevel
+    if ratio >= models.UsageWarningRatio {
+        levels = append(levels, models.UsageLevelWarning)
+    }
+    if ratio >= 1 {
+        levels = append(levels, models.UsageLevelLimit)
+    }
+    if ratio > 1 {
+        levels = append(levels, models.UsageLevelOverage)
+    }
+    return levels
+}
+
+// NotifyUsageThresholds compares an org's usage with its plan and emails the owners
+// when a resource reaches a new usage level this month. Every level reached is
+// recorded (the unique index makes the record the claim, so replicas never send
+// twice), but only the highest newly reached one is announced: an org that jumps
+// straight past its limit gets one email, not three.
+func NotifyUsageThresholds(db *gorm.DB, org models.Organization, now time.Time) error {
+    usage, err := GetUsageSnapshot(db, org.ID)
+    if err != nil {
+        return fmt.Errorf("failed to load usage: %w", err)
+    }
+    result := CheckEntitlements(org.Plan, usage)
+
+    resources := make([]string, 0, len(result.Thresholds))
+    for resource := range result.Thresholds {
+        resources = append(resources, resource)
+    }
+    sort.Strings(resources)
+
+    now = now.UTC()
+    for _, resource := range resources {
+        ratio := result.Thresholds[resource]
+        var announce *models.UsageNotification
+        for _, level := range UsageLevels(ratio) {
+            record := models.UsageNotification{
+                OrgID:    org.ID,
+                Year:     now.Year(),
+                Month:    int(now.Month()),
+                Resource: resource,
+                Level:    level,
+                Ratio:    ratio,
+            }
+            claim := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+            if claim.Error != nil {
+                return fmt.Errorf("failed to record %s usage notification: %w", resource, claim.Error)
+            }
+            if claim.RowsAffected == 1 {
+                announce = &record
+            }
+        }
+        if announce == nil {
+            continue
+        }
+        if err := sendUsageEmail(db, org, *announce); err != nil {
+            // Release the claim so the next run tries again
+            if delErr := db.Delete(announce).Error; delErr != nil {
+                log.Printf("Error releasing usage notification %d: %v", announce.ID, delErr)
+            }
+            return fmt.Errorf("failed to send %s usage notification: %w", resource, err)
+        }
+        log.Printf("Usage notification sent: org=%d resource=%s level=%s ratio=%.2f", org.ID, resource, announce.Level, ratio)
+    }
+    return nil
+}
+
+// sendUsageEmail emails a usage notification to the org's owners
+func sendUsageEmail(db *gorm.DB, org models.Organization, record models.UsageNotification) error {
+    var owners []string
+    if err := db.Model(&models.User{}).
+        Where("org_id = ? AND role = ?", org.ID, models.RoleOwner).
+        Pluck("email", &owners).Error; err != nil {
+        return err
+    }
+    if len(owners) == 0 {
+        return nil
+    }
+    resource, ok := usageResources[record.Resource]
+    if !ok {
+        resource = usageResource{Label: record.Resource}
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), usageEmailTimeout)
+    defer cancel()
+    return notifier.SendUsageEmail(ctx, notifier.UsageEmail{
+        To:          owners,
+        OrgName:     org.Name,
+        PlanName:    models.GetPlanConfig(org.Plan).Name,
+        Resource:    resource.Label,
+        Level:       record.Level,
+        Percent:     int(record.Ratio * 100),
+        Consequence: resource.AtLimit,
+        Link:        "/settings?tab=billing",
+    })
+}
+
+// ProcessUsageThresholds runs NotifyUsageThresholds for every organization
+func ProcessUsageThresholds(db *gorm.DB, batchSize int) error {
+    now := time.Now()
+    var orgs []models.Organization
+    return db.Order("id ASC").FindInBatches(&orgs, batchSize, func(tx *gorm.DB, batch int) error {
+        for _, org := range orgs {
+            if err := NotifyUsageThresholds(db, org, now); err != nil {
+                log.Printf("Usage thresholds for org %d failed: %v", org.ID, err)
+            }
+        }
+        return nil
+    }).Error
+}
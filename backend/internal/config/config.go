@@ -3,17 +3,18 @@ package config
 import "os"
 
 type Config struct {
-	DatabaseURL  string
-	JWTSecret    string
-	CORSOrigins  string
-	SendGridKey  string
-	SMTPHost     string
-	SMTPPort     string
-	SMTPUser     string
-	SMTPPassword string
-	SMTPFrom     string
-	Environment  string
-	FrontendURL  string
+	DatabaseURL   string
+	JWTSecret     string
+	CORSOrigins   string
+	SendGridKey   string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUser      string
+	SMTPPassword  string
+	SMTPFrom      string
+	EmailProvider string // smtp or sendgrid; empty picks one from the environment
+	Environment   string
+	FrontendURL   string
 	// Stripe configuration
 	StripeSecretKey      string
 	StripeWebhookSecret  string
@@ -33,6 +34,7 @@ func Load() *Config {
 		SMTPUser:            getEnv("SMTP_USER", ""),
 		SMTPPassword:        getEnv("SMTP_PASSWORD", ""),
 		SMTPFrom:            getEnv("SMTP_FROM", "alerts@lighthouse.local"),
+		EmailProvider:       getEnv("EMAIL_PROVIDER", ""),
 		Environment:         getEnv("ENVIRONMENT", "development"),
 		FrontendURL:         getEnv("FRONTEND_URL", "http://localhost:3000"),
 		StripeSecretKey:     getEnv("STRIPE_SECRET_KEY", ""),
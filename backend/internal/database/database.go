@@ -30,17 +30,39 @@ func Migrate(db *gorm.DB) error {
     err := db.AutoMigrate(
         &models.Organization{},
         &models.User{},
+        &models.CheckGroup{},
         &models.Check{},
+        &models.CheckRevision{},
         &models.CheckResult{},
+        &models.ResponseTimeBaseline{},
         &models.LogEvent{},
         &models.LogEntry{},
         &models.TraceSpan{},
         &models.APIKey{},
         &models.Alert{},
+        &models.Incident{},
+        &models.IncidentEvent{},
         &models.NotificationSettings{},
+        &models.NotificationTemplate{},
+        &models.NotificationChannel{},
+        &models.DigestSubscription{},
+        &models.RoutingRule{},
+        &models.LogAlertRule{},
+        &models.TraceAlertRule{},
+        &models.ExternalAlert{},
+        &models.UserNotificationPreference{},
+        &models.OnCallSchedule{},
+        &models.OnCallOverride{},
+        &models.EscalationPolicy{},
+        &models.EscalationLevel{},
+        &models.AlertEscalation{},
+        &models.NotificationJob{},
+        &models.NotificationDelivery{},
         &models.Invite{},
         &models.AuditLog{},
         &models.MonthlyUsage{},
+        &models.UsageNotification{},
+        &models.MaintenanceWindow{},
     )
     if err != nil {
         return err
@@ -59,6 +81,24 @@ func Migrate(db *gorm.DB) error {
     if err := migrateOrganizationSlugs(db); err != nil {
         log.Printf("Warning: org slug migration may have failed: %v", err)
     }
+    if err := migrateNotificationSettingsToChannels(db); err != nil {
+        log.Printf("Warning: notification channel migration may have failed: %v", err)
+    }
+    if err := backfillWebhookSigningSecrets(db); err != nil {
+        log.Printf("Warning: webhook signing secret backfill may have failed: %v", err)
+    }
+    if err := backfillIncidents(db); err != nil {
+        log.Printf("Warning: incident backfill may have failed: %v", err)
+    }
+    if err := allowAlertsWithoutCheck(db); err != nil {
+        log.Printf("Warning: alerts.check_id migration may have failed: %v", err)
+    }
+    if err := allowIncidentsWithoutCheck(db); err != nil {
+        log.Printf("Warning: incidents.check_id migration may have failed: %v", err)
+    }
+    if err := raiseCrawlCheckIntervals(db); err != nil {
+        log.Printf("Warning: crawl check interval migration may have failed: %v", err)
+    }
     return nil
 }
 
@@ -82,6 +122,154 @@ func migrateExistingOrgsToFreePlan(db *gorm.DB) error {
     `).Error
 }
 
+// migrateNotificationSettingsToChannels creates channels for orgs whose legacy
+// notification settings have never been mirrored into notification channels
+func migrateNotificationSettingsToChannels(db *gorm.DB) error {
+    return db.Transaction(func(tx *gorm.DB) error {
+        var settings []models.NotificationSettings
+        if err := tx.Where("org_id NOT IN (?)",
+            tx.Unscoped().Model(&models.NotificationChannel{}).Select("org_id").Where("source = ?", models.ChannelSourceSettings),
+        ).Find(&settings).Error; err != nil {
+            return err
+        }
+        for _, s := range settings {
+            if err := models.SyncSettingsChannels(tx, s); err != nil {
+                return fmt.Errorf("failed to migrate notification settings for org %d: %w", s.OrgID, err)
+            }
+        }
+        if len(settings) > 0 {
+            log.Printf("Migrated notification settings to channels for %d organizations", len(settings))
+        }
+        return nil
+    })
+}
+
+// backfillWebhookSigningSecrets gives webhook channels and personal webhooks created
+// before signing existed a secret
+func backfillWebhookSigningSecrets(db *gorm.DB) error {
+    var channels []models.NotificationChannel
+    if err := db.Where("type = ? AND (signing_secret IS NULL OR signing_secret = '')", models.ChannelTypeWebhook).Find(&channels).Error; err != nil {
+        return err
+    }
+    for _, ch := range channels {
+        secret, err := models.GenerateSigningSecret()
+        if err != nil {
+            return err
+        }
+        if err := db.Model(&ch).Update("signing_secret", secret).Error; err != nil {
+            return fmt.Errorf("failed to set signing secret for channel %d: %w", ch.ID, err)
+        }
+    }
+
+    var prefs []models.UserNotificationPreference
+    if err := db.Where("webhook_url <> '' AND (webhook_signing_secret IS NULL OR webhook_signing_secret = '')").Find(&prefs).Error; err != nil {
+        return err
+    }
+    for _, pref := range prefs {
+        secret, err := models.GenerateSigningSecret()
+        if err != nil {
+            return err
+        }
+        if err := db.Model(&pref).Update("webhook_signing_secret", secret).Error; err != nil {
+            return fmt.Errorf("failed to set signing secret for user %d's webhook: %w", pref.UserID, err)
+        }
+    }
+    return nil
+}
+
+// backfillIncidents groups alerts raised before incidents existed: each DOWN opens an
+// incident (or joins the open one) and the next RECOVERY resolves it. Incidents left
+// open for checks that are up again are closed without a duration so MTTR isn't skewed.
+func backfillIncidents(db *gorm.DB) error {
+    alertTypes := []models.AlertType{models.AlertTypeDown, models.AlertTypeRecovery}
+    var checkIDs []uint
+    if err := db.Model(&models.Alert{}).
+        Where("incident_id IS NULL AND alert_type IN ?", alertTypes).
+        Distinct().
+        Pluck("check_id", &checkIDs).Error; err != nil {
+        return err
+    }
+    for _, checkID := range checkIDs {
+        err := db.Transaction(func(tx *gorm.DB) error {
+            var check models.Check
+            if err := tx.Unscoped().First(&check, checkID).Error; err != nil {
+                return err
+            }
+            var alerts []models.Alert
+            if err := tx.Where("check_id = ? AND incident_id IS NULL AND alert_type IN ?", checkID, alertTypes).
+                Order("created_at ASC, id ASC").
+                Find(&alerts).Error; err != nil {
+                return err
+            }
+            var open *models.Incident
+            for _, alert := range alerts {
+                if alert.AlertType == models.AlertTypeRecovery && open == nil {
+                    continue
+                }
+                if open == nil {
+                    incident := models.NewIncident(alert, check)
+                    if err := tx.Create(&incident).Error; err != nil {
+                        return err
+                    }
+                    open = &incident
+                }
+                if err := tx.Model(&alert).Update("incident_id", open.ID).Error; err != nil {
+                    return err
+                }
+                event := models.IncidentEvent{
+                    CreatedAt:  alert.CreatedAt,
+                    IncidentID: open.ID,
+                    Kind:       models.IncidentEventAlert,
+                    AlertID:    &alert.ID,
+                    Body:       alert.ErrorMessage,
+                }
+                if err := tx.Create(&event).Error; err != nil {
+                    return err
+                }
+                if alert.AlertType == models.AlertTypeRecovery {
+                    open.Resolve(alert.CreatedAt, nil)
+                    if err := tx.Save(open).Error; err != nil {
+                        return err
+                    }
+                    resolved := models.IncidentEvent{
+                        CreatedAt:  alert.CreatedAt,
+                        IncidentID: open.ID,
+                        Kind:       models.IncidentEventResolved,
+                    }
+                    if err := tx.Create(&resolved).Error; err != nil {
+                        return err
+                    }
+                    open = nil
+                }
+            }
+            if open != nil && check.LastSuccess != nil && *check.LastSuccess {
+                open.Status = models.IncidentResolved
+                open.ResolvedAt = check.LastCheckedAt
+                if err := tx.Save(open).Error; err != nil {
+                    return err
+                }
+            }
+            return nil
+        })
+        if err != nil {
+            return fmt.Errorf("failed to backfill incidents for check %d: %w", checkID, err)
+        }
+    }
+    return nil
+}
+
+// allowAlertsWithoutCheck drops the NOT NULL on alerts.check_id: log and trace alerts
+// belong to an alert rule instead of a check, external alerts to their source
+func allowAlertsWithoutCheck(db *gorm.DB) error {
+    return db.Exec(`ALTER TABLE alerts ALTER COLUMN check_id DROP NOT NULL`).Error
+}
+
+// allowIncidentsWithoutCheck drops the NOT NULL on incidents.check_id: incidents for
+// Alertmanager and Grafana alerts belong to the external alert instead of a check
+func allowIncidentsWithoutCheck(db *gorm.DB) error {
+    return db.Exec(`ALTER TABLE incidents ALTER COLUMN check_id DROP NOT NULL`).Error
+}
+
 func createObservabilityIndexes(db *gorm.DB) error {
     indexes := []string{
         // Check Results indexes
@@ -108,6 +296,14 @@ func createObservabilityIndexes(db *gorm.DB) error {
     return nil
 }
 
+// raiseCrawlCheckIntervals raises crawl checks created before the minimum crawl interval
+// existed, so a crawl never comes due while the previous one is still running
+func raiseCrawlCheckIntervals(db *gorm.DB) error {
+    return db.Model(&models.Check{}).
+        Where("check_type = ? AND interval_seconds < ?", models.CheckTypeCrawl, models.MinCrawlIntervalSeconds).
+        Update("interval_seconds", models.MinCrawlIntervalSeconds).Error
+}
+
 // migrateOrganizationSlugs generates slugs for existing orgs without them
 // Mitigates: B4 (partial migration) - runs in transaction for atomicity
 func migrateOrganizationSlugs(db *gorm.DB) error {
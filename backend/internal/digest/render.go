@@ -0,0 +1,137 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+)
+
+// renderFuncs format report values for both the text and HTML versions
+var renderFuncs = map[string]interface{}{
+	"pct": func(v float64) string {
+		return fmt.Sprintf("%.2f%%", v)
+	},
+	"duration": func(seconds *float64) string {
+		if seconds == nil {
+			return "n/a"
+		}
+		return (time.Duration(*seconds) * time.Second).Round(time.Second).String()
+	},
+	"trend": func(change *float64) string {
+		if change == nil {
+			return ""
+		}
+		switch {
+		case *change > 0.5:
+			return fmt.Sprintf("▲ %.0f%%", *change)
+		case *change < -0.5:
+			return fmt.Sprintf("▼ %.0f%%", -*change)
+		}
+		return "unchanged"
+	},
+	"label": frequencyLabel,
+}
+
+// frequencyLabel capitalises a frequency for headings, e.g. "Weekly"
+func frequencyLabel(f models.DigestFrequency) string {
+	s := string(f)
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+const textTemplate = `{{label .Report.Frequency}} uptime digest for {{.Report.OrgName}}
+{{.Start}} – {{.End}} ({{.Report.Timezone}})
+
+Overall uptime: {{pct .Report.UptimePercent}}
+Incidents: {{.Report.TotalIncidents}} (MTTR {{duration .Report.MTTRSeconds}})
+
+Checks
+{{- range .Report.Checks}}
+- {{.Name}}: {{if .TotalRuns}}{{pct .UptimePercentage}} uptime, p95 {{.P95ResponseMs}}ms{{with trend .P95ChangePercent}} ({{.}}){{end}}{{else}}no runs{{end}}, {{.Incidents}} incidents
+{{- else}}
+No active checks.
+{{- end}}
+{{- if .Report.Slowest}}
+
+Slowest checks (p95)
+{{- range .Report.Slowest}}
+- {{.Name}}: {{.P95ResponseMs}}ms (avg {{.AvgResponseMs}}ms)
+{{- end}}
+{{- end}}
+`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #1d1d1f;">
+  <h2 style="margin: 0;">{{label .Report.Frequency}} uptime digest for {{.Report.OrgName}}</h2>
+  <p style="color: #6e6e73; margin-top: 4px;">{{.Start}} – {{.End}} ({{.Report.Timezone}})</p>
+  <p><strong>Overall uptime:</strong> {{pct .Report.UptimePercent}}<br>
+  <strong>Incidents:</strong> {{.Report.TotalIncidents}} (MTTR {{duration .Report.MTTRSeconds}})</p>
+  <table cellpadding="6" style="border-collapse: collapse; font-size: 14px;">
+    <tr style="text-align: left; border-bottom: 1px solid #d2d2d7;"><th>Check</th><th>Uptime</th><th>p95</th><th>Trend</th><th>Incidents</th></tr>
+    {{- range .Report.Checks}}
+    <tr style="border-bottom: 1px solid #f5f5f7;">
+      <td>{{.Name}}</td>
+      {{- if .TotalRuns}}
+      <td>{{pct .UptimePercentage}}</td><td>{{.P95ResponseMs}}ms</td><td>{{trend .P95ChangePercent}}</td>
+      {{- else}}
+      <td colspan="3" style="color: #6e6e73;">no runs</td>
+      {{- end}}
+      <td>{{.Incidents}}</td>
+    </tr>
+    {{- end}}
+  </table>
+  {{- if .Report.Slowest}}
+  <h3>Slowest checks (p95)</h3>
+  <ol>
+    {{- range .Report.Slowest}}
+    <li>{{.Name}}: {{.P95ResponseMs}}ms (avg {{.AvgResponseMs}}ms)</li>
+    {{- end}}
+  </ol>
+  {{- end}}
+</body>
+</html>
+`
+
+var (
+	textDigest = template.Must(template.New("digest_text").Funcs(renderFuncs).Parse(textTemplate))
+	htmlDigest = htmltemplate.Must(htmltemplate.New("digest_html").Funcs(renderFuncs).Parse(htmlTemplate))
+)
+
+// Render turns a report into a multipart email (recipients are left to the caller)
+func Render(report Report) (notifier.Email, error) {
+	loc, err := time.LoadLocation(report.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	data := struct {
+		Report Report
+		Start  string
+		End    string
+	}{
+		Report: report,
+		Start:  report.PeriodStart.In(loc).Format("Mon, 02 Jan 15:04"),
+		End:    report.PeriodEnd.In(loc).Format("Mon, 02 Jan 15:04"),
+	}
+	var text, html bytes.Buffer
+	if err := textDigest.Execute(&text, data); err != nil {
+		return notifier.Email{}, fmt.Errorf("failed to render digest: %w", err)
+	}
+	if err := htmlDigest.Execute(&html, data); err != nil {
+		return notifier.Email{}, fmt.Errorf("failed to render digest: %w", err)
+	}
+	return notifier.Email{
+		Subject: fmt.Sprintf("%s uptime digest for %s: %.2f%% uptime, %d incidents",
+			frequencyLabel(report.Frequency), report.OrgName, report.UptimePercent, report.TotalIncidents),
+		Text: text.String(),
+		HTML: html.String(),
+	}, nil
+}
@@ -0,0 +1,127 @@
+// Package digest compiles and sends scheduled uptime summaries
+package digest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/incidents"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/stats"
+	"gorm.io/gorm"
+)
+
+// slowestCount is how many checks the "slowest checks" section lists
+const slowestCount = 5
+
+// CheckReport is one check's line in a digest
+type CheckReport struct {
+	CheckID uint   `json:"check_id"`
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	stats.CheckStats
+	Incidents int64 `json:"incidents"`
+	// P95 of the previous period, and the relative change; nil without data for both periods
+	PreviousP95Ms    *int     `json:"previous_p95_ms,omitempty"`
+	P95ChangePercent *float64 `json:"p95_change_percent,omitempty"`
+}
+
+// Report is the content of one digest
+type Report struct {
+	OrgName        string                 `json:"org_name"`
+	Timezone       string                 `json:"timezone"`
+	Frequency      models.DigestFrequency `json:"frequency"`
+	PeriodStart    time.Time              `json:"period_start"`
+	PeriodEnd      time.Time              `json:"period_end"`
+	UptimePercent  float64                `json:"uptime_percentage"` // Across all runs of all checks
+	TotalIncidents int64                  `json:"total_incidents"`
+	MTTRSeconds    *float64               `json:"mttr_seconds"`
+	Checks         []CheckReport          `json:"checks"`
+	Slowest        []CheckReport          `json:"slowest"` // By p95 response time
+}
+
+// Location loads the org's timezone, falling back to UTC
+func Location(org models.Organization) *time.Location {
+	loc, err := time.LoadLocation(org.Timezone)
+	if err != nil || org.Timezone == "" {
+		return time.UTC
+	}
+	return loc
+}
+
+// Build compiles the report for the period ending at end. Trends compare each
+// check's p95 with the period before.
+func Build(db *gorm.DB, org models.Organization, frequency models.DigestFrequency, end time.Time) (Report, error) {
+	start := end.Add(-frequency.Period())
+	previousStart := start.Add(-frequency.Period())
+	report := Report{
+		OrgName:     org.Name,
+		Timezone:    Location(org).String(),
+		Frequency:   frequency,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Checks:      []CheckReport{},
+		Slowest:     []CheckReport{},
+	}
+
+	var checks []models.Check
+	if err := db.Where("org_id = ? AND is_active = ?", org.ID, true).Order("name ASC").Find(&checks).Error; err != nil {
+		return report, err
+	}
+
+	var totalRuns, successfulRuns int
+	for _, check := range checks {
+		current, err := stats.ForCheck(db, check.ID, start, end)
+		if err != nil {
+			return report, err
+		}
+		previous, err := stats.ForCheck(db, check.ID, previousStart, start)
+		if err != nil {
+			return report, err
+		}
+		incidentStats, err := incidents.ComputeStats(db, org.ID, &check.ID, start)
+		if err != nil {
+			return report, err
+		}
+		line := CheckReport{
+			CheckID:    check.ID,
+			Name:       check.Name,
+			URL:        check.URL,
+			CheckStats: current,
+			Incidents:  incidentStats.Total,
+		}
+		if previous.TotalRuns > 0 && current.TotalRuns > 0 {
+			line.PreviousP95Ms = &previous.P95ResponseMs
+			if previous.P95ResponseMs > 0 {
+				change := float64(current.P95ResponseMs-previous.P95ResponseMs) / float64(previous.P95ResponseMs) * 100
+				line.P95ChangePercent = &change
+			}
+		}
+		report.Checks = append(report.Checks, line)
+		totalRuns += current.TotalRuns
+		successfulRuns += current.SuccessfulRuns
+	}
+	if totalRuns > 0 {
+		report.UptimePercent = float64(successfulRuns) / float64(totalRuns) * 100
+	}
+
+	orgIncidents, err := incidents.ComputeStats(db, org.ID, nil, start)
+	if err != nil {
+		return report, err
+	}
+	report.TotalIncidents = orgIncidents.Total
+	report.MTTRSeconds = orgIncidents.MTTRSeconds
+
+	for _, line := range report.Checks {
+		if line.TotalRuns > 0 {
+			report.Slowest = append(report.Slowest, line)
+		}
+	}
+	sort.SliceStable(report.Slowest, func(i, j int) bool {
+		return report.Slowest[i].P95ResponseMs > report.Slowest[j].P95ResponseMs
+	})
+	if len(report.Slowest) > slowestCount {
+		report.Slowest = report.Slowest[:slowestCount]
+	}
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+	"github.com/oFuterman/light-house/internal/schedule"
+	"github.com/oFuterman/light-house/internal/utils"
+	"gorm.io/gorm"
+)
+
+// sendTimeout bounds a single digest delivery
+const sendTimeout = 60 * time.Second
+
+// ScheduleNext sets the subscription's next run after `after` in the org's timezone
+func ScheduleNext(sub *models.DigestSubscription, org models.Organization, after time.Time) {
+	sub.NextRunAt = sub.NextRun(Location(org), after)
+}
+
+// Send builds and delivers one digest now. The report covers the period ending now.
+func Send(db *gorm.DB, sub models.DigestSubscription) error {
+	var org models.Organization
+	if err := db.First(&org, sub.OrgID).Error; err != nil {
+		return fmt.Errorf("failed to load organization: %w", err)
+	}
+	var channel models.NotificationChannel
+	if err := db.Where("id = ? AND org_id = ?", sub.ChannelID, sub.OrgID).First(&channel).Error; err != nil {
+		return fmt.Errorf("failed to load channel: %w", err)
+	}
+	if !channel.Enabled {
+		return fmt.Errorf("channel %q is disabled", channel.Name)
+	}
+
+	report, err := Build(db, org, sub.Frequency, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build digest: %w", err)
+	}
+	email, err := Render(report)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	return notifier.SendToEmailChannel(ctx, channel, email)
+}
+
+// Subscriptions are claimed and moved to their next scheduled time by schedule.Due
+// so replicas never send one twice; a failed send is recorded and retried at the
+// next scheduled time.
+var due = schedule.Due[models.DigestSubscription]{
+	Column:  "next_run_at",
+	Preload: []string{"Organization"},
+	Reschedule: func(tx *gorm.DB, sub *models.DigestSubscription, now time.Time) error {
+		ScheduleNext(sub, sub.Organization, now)
+		return tx.Model(sub).Update("next_run_at", sub.NextRunAt).Error
+	},
+	Run: func(db *gorm.DB, sub models.DigestSubscription, now time.Time) {
+		updates := map[string]interface{}{"last_error": ""}
+		if err := Send(db, sub); err != nil {
+			log.Printf("Digest %d for org %d failed: %v", sub.ID, sub.OrgID, err)
+			updates["last_error"] = utils.Truncate(err.Error(), 1024)
+		} else {
+			updates["last_sent_at"] = time.Now()
+		}
+		if err := db.Model(&sub).Updates(updates).Error; err != nil {
+			log.Printf("Error updating digest %d: %v", sub.ID, err)
+		}
+	},
+}
+
+// ProcessDue sends up to limit digests whose scheduled time has passed
+func ProcessDue(db *gorm.DB, limit int) (int, error) {
+	return due.ProcessDue(db, limit)
+}
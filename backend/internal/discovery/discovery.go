@@ -0,0 +1,146 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+)
+
+// Source types accepted by Discover
+const (
+	SourceOpenAPI = "openapi"
+	SourceSitemap = "sitemap"
+)
+
+const (
+	// MaxDocumentBytes caps uploaded or fetched documents
+	MaxDocumentBytes = 5 * 1024 * 1024
+	// MaxProposals caps how many checks a single import can propose
+	MaxProposals = 500
+	// maxNestedSitemaps caps how many child sitemaps of a sitemap index are fetched
+	maxNestedSitemaps = 10
+	fetchTimeout      = 15 * time.Second
+)
+
+// ErrUnknownSource is returned when the document is neither OpenAPI nor a sitemap
+var ErrUnknownSource = errors.New("document is not an OpenAPI 3 document or a sitemap")
+
+// Input describes a discovery request. Exactly one of Content or URL is used:
+// Content for uploaded documents, URL to fetch the document.
+type Input struct {
+	Source      string // openapi, sitemap, or empty to auto-detect
+	Content     []byte
+	URL         string
+	BaseURL     string // Overrides the OpenAPI servers list (or resolves relative servers)
+	ServiceName string
+}
+
+// Proposal is a suggested GET check awaiting review
+type Proposal struct {
+	Name        string         `json:"name"`
+	URL         string         `json:"url"`
+	Method      string         `json:"method"`
+	ServiceName string         `json:"service_name,omitempty"`
+	Tags        models.JSONMap `json:"tags,omitempty"`
+	Source      string         `json:"source"`
+	Exists      bool           `json:"exists"` // An org check already monitors this URL
+}
+
+// Discover parses (and, if needed, fetches) a document and returns proposed checks
+func Discover(ctx context.Context, in Input) ([]Proposal, error) {
+	content := in.Content
+	baseURL := in.BaseURL
+	if len(content) == 0 {
+		if in.URL == "" {
+			return nil, errors.New("either content or url is required")
+		}
+		fetched, err := Fetch(ctx, in.URL)
+		if err != nil {
+			return nil, err
+		}
+		content = fetched
+		if baseURL == "" {
+			baseURL = in.URL
+		}
+	}
+
+	source := in.Source
+	if source == "" {
+		source = detectSource(content)
+	}
+
+	var proposals []Proposal
+	var err error
+	switch source {
+	case SourceOpenAPI:
+		proposals, err = parseOpenAPI(content, baseURL, in.ServiceName)
+	case SourceSitemap:
+		proposals, err = discoverSitemap(ctx, content, in.ServiceName)
+	default:
+		return nil, ErrUnknownSource
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(proposals) > MaxProposals {
+		proposals = proposals[:MaxProposals]
+	}
+	return proposals, nil
+}
+
+// detectSource sniffs the document type from its content
+func detectSource(content []byte) string {
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		if bytes.Contains(trimmed, []byte("<urlset")) || bytes.Contains(trimmed, []byte("<sitemapindex")) {
+			return SourceSitemap
+		}
+		return ""
+	}
+	if bytes.Contains(trimmed, []byte("openapi")) {
+		return SourceOpenAPI
+	}
+	return ""
+}
+
+// Fetch downloads a document with a timeout and size cap
+func Fetch(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	req.Header.Set("User-Agent", "LightHouse-Discovery/1.0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetching %s returned status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if len(data) > MaxDocumentBytes {
+		return nil, fmt.Errorf("document exceeds %d bytes", MaxDocumentBytes)
+	}
+	return data, nil
+}
+
+// proposalTags builds the tags attached to every discovered check
+func proposalTags(serviceName, source string) models.JSONMap {
+	tags := models.JSONMap{"discovered_from": source}
+	if serviceName != "" {
+		tags["service"] = serviceName
+	}
+	return tags
+}
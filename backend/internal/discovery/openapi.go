@@ -0,0 +1,155 @@
+package discovery
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/oFuterman/light-house/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIDocument is the subset of an OpenAPI 3 document discovery needs.
+// yaml.v3 also parses JSON, so both encodings are accepted.
+type openAPIDocument struct {
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Title string `yaml:"title"`
+	} `yaml:"info"`
+	Servers    []openAPIServer            `yaml:"servers"`
+	Paths      map[string]openAPIPathItem `yaml:"paths"`
+	Components struct {
+		Parameters map[string]openAPIParameter `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+type openAPIServer struct {
+	URL       string `yaml:"url"`
+	Variables map[string]struct {
+		Default string `yaml:"default"`
+	} `yaml:"variables"`
+}
+
+type openAPIPathItem struct {
+	Get        *openAPIOperation  `yaml:"get"`
+	Parameters []openAPIParameter `yaml:"parameters"`
+}
+
+type openAPIOperation struct {
+	OperationID string             `yaml:"operationId"`
+	Summary     string             `yaml:"summary"`
+	Parameters  []openAPIParameter `yaml:"parameters"`
+}
+
+type openAPIParameter struct {
+	Ref      string `yaml:"$ref"`
+	Name     string `yaml:"name"`
+	In       string `yaml:"in"`
+	Required bool   `yaml:"required"`
+}
+
+// parseOpenAPI proposes a GET check for every path without required parameters
+func parseOpenAPI(content []byte, baseURL, serviceName string) ([]Proposal, error) {
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("only OpenAPI 3.x documents are supported (got %q)", doc.OpenAPI)
+	}
+	if serviceName == "" {
+		serviceName = doc.Info.Title
+	}
+
+	server, err := resolveServerURL(doc.Servers, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort paths for stable output
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	proposals := []Proposal{}
+	for _, path := range paths {
+		item := doc.Paths[path]
+		if item.Get == nil || strings.Contains(path, "{") {
+			continue
+		}
+		params := append(append([]openAPIParameter{}, item.Parameters...), item.Get.Parameters...)
+		if hasRequiredParameter(params, doc.Components.Parameters) {
+			continue
+		}
+
+		name := item.Get.Summary
+		if name == "" {
+			name = "GET " + path
+		}
+		if serviceName != "" {
+			name = serviceName + ": " + name
+		}
+
+		proposals = append(proposals, Proposal{
+			Name:        utils.Truncate(name, 255),
+			URL:         strings.TrimRight(server, "/") + path,
+			Method:      "GET",
+			ServiceName: serviceName,
+			Tags:        proposalTags(serviceName, SourceOpenAPI),
+			Source:      SourceOpenAPI,
+		})
+	}
+	return proposals, nil
+}
+
+// hasRequiredParameter reports whether any parameter (after resolving local $refs) is required
+func hasRequiredParameter(params []openAPIParameter, components map[string]openAPIParameter) bool {
+	for _, p := range params {
+		if p.Ref != "" {
+			resolved, ok := components[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+			if !ok {
+				// Unresolvable reference: be conservative and skip the path
+				return true
+			}
+			p = resolved
+		}
+		if p.Required || p.In == "path" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveServerURL picks the first server, substitutes variable defaults and
+// resolves relative server URLs against baseURL
+func resolveServerURL(servers []openAPIServer, baseURL string) (string, error) {
+	server := ""
+	if len(servers) > 0 {
+		server = servers[0].URL
+		for name, v := range servers[0].Variables {
+			server = strings.ReplaceAll(server, "{"+name+"}", v.Default)
+		}
+	}
+
+	if strings.HasPrefix(server, "http://") || strings.HasPrefix(server, "https://") {
+		return server, nil
+	}
+	if baseURL == "" {
+		return "", fmt.Errorf("the document has no absolute server URL; provide base_url")
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return "", fmt.Errorf("invalid base_url")
+	}
+	if server == "" {
+		return base.Scheme + "://" + base.Host, nil
+	}
+	ref, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q", server)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
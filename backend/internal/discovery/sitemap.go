@@ -0,0 +1,97 @@
+package discovery
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/oFuterman/light-house/internal/utils"
+)
+
+type sitemapURLSet struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+type sitemapIndex struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// discoverSitemap proposes a GET check per sitemap URL, following one level of sitemap index
+func discoverSitemap(ctx context.Context, content []byte, serviceName string) ([]Proposal, error) {
+	locs, err := parseSitemapURLs(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// A sitemap index lists other sitemaps rather than pages
+	if len(locs) == 0 {
+		var index sitemapIndex
+		if err := xml.Unmarshal(content, &index); err != nil {
+			return nil, fmt.Errorf("invalid sitemap: %w", err)
+		}
+		for i, sm := range index.Sitemaps {
+			if i >= maxNestedSitemaps || len(locs) >= MaxProposals {
+				break
+			}
+			child, err := Fetch(ctx, strings.TrimSpace(sm.Loc))
+			if err != nil {
+				return nil, err
+			}
+			childLocs, err := parseSitemapURLs(child)
+			if err != nil {
+				return nil, err
+			}
+			locs = append(locs, childLocs...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	proposals := []Proposal{}
+	for _, loc := range locs {
+		loc = strings.TrimSpace(loc)
+		parsed, err := url.Parse(loc)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || seen[loc] {
+			continue
+		}
+		seen[loc] = true
+
+		name := parsed.Path
+		if name == "" {
+			name = "/"
+		}
+		if serviceName != "" {
+			name = serviceName + ": " + name
+		} else {
+			name = parsed.Host + name
+		}
+
+		proposals = append(proposals, Proposal{
+			Name:        utils.Truncate(name, 255),
+			URL:         loc,
+			Method:      "GET",
+			ServiceName: serviceName,
+			Tags:        proposalTags(serviceName, SourceSitemap),
+			Source:      SourceSitemap,
+		})
+	}
+	return proposals, nil
+}
+
+// parseSitemapURLs extracts <loc> entries from a <urlset>
+func parseSitemapURLs(content []byte) ([]string, error) {
+	var set sitemapURLSet
+	if err := xml.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("invalid sitemap: %w", err)
+	}
+	locs := make([]string, 0, len(set.URLs))
+	for _, u := range set.URLs {
+		locs = append(locs, u.Loc)
+	}
+	return locs, nil
+}
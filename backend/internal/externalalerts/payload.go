@@ -0,0 +1,170 @@
+// Package externalalerts turns alerts received from Prometheus Alertmanager and
+// Grafana into Light House alerts and incidents.
+package externalalerts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/utils"
+)
+
+// Alert statuses used by Alertmanager and Grafana unified alerting
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// AlertmanagerAlert is one alert of an Alertmanager webhook
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"` // Zero while firing
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertmanagerPayload is the body of an Alertmanager webhook (version 4)
+type AlertmanagerPayload struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts"`
+}
+
+// GrafanaPayload is the body of a Grafana webhook contact point. Grafana unified
+// alerting sends the Alertmanager shape; legacy dashboard alerting sends one rule
+// with its state instead.
+type GrafanaPayload struct {
+	AlertmanagerPayload
+	// Legacy alerting
+	RuleID   int64             `json:"ruleId"`
+	RuleName string            `json:"ruleName"`
+	RuleURL  string            `json:"ruleUrl"`
+	State    string            `json:"state"` // alerting, ok, no_data, paused or pending
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Tags     map[string]string `json:"tags"`
+}
+
+// Alerts returns the payload's alerts in the Alertmanager shape. Legacy states other
+// than alerting and ok say nothing about whether the rule fires and yield no alerts.
+func (p GrafanaPayload) Alerts() []AlertmanagerAlert {
+	if len(p.AlertmanagerPayload.Alerts) > 0 || p.State == "" {
+		return p.AlertmanagerPayload.Alerts
+	}
+	var status string
+	switch p.State {
+	case "alerting":
+		status = StatusFiring
+	case "ok":
+		status = StatusResolved
+	default:
+		return nil
+	}
+	labels := map[string]string{}
+	for key, value := range p.Tags {
+		labels[key] = value
+	}
+	name := p.RuleName
+	if name == "" {
+		name = p.Title
+	}
+	labels["alertname"] = name
+	annotations := map[string]string{}
+	if p.Message != "" {
+		annotations["summary"] = p.Message
+	} else if p.Title != "" {
+		annotations["summary"] = p.Title
+	}
+	alert := AlertmanagerAlert{
+		Status:       status,
+		Labels:       labels,
+		Annotations:  annotations,
+		GeneratorURL: p.RuleURL,
+	}
+	if p.RuleID != 0 {
+		alert.Fingerprint = fmt.Sprintf("grafana-rule-%d", p.RuleID)
+	}
+	return []AlertmanagerAlert{alert}
+}
+
+// Fingerprint identifies the alert across notifications: the sender's fingerprint
+// when there is one, otherwise a hash of its labels (as Alertmanager does)
+func Fingerprint(alert AlertmanagerAlert) string {
+	if alert.Fingerprint != "" {
+		return utils.Truncate(alert.Fingerprint, 128)
+	}
+	keys := make([]string, 0, len(alert.Labels))
+	for key := range alert.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s\xff%s\xff", key, alert.Labels[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// firstLabel returns the first non-empty value among the given label names
+func firstLabel(labels map[string]string, names ...string) string {
+	for _, name := range names {
+		if value := strings.TrimSpace(labels[name]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Name is the alert's alertname label
+func Name(alert AlertmanagerAlert) string {
+	if name := firstLabel(alert.Labels, "alertname"); name != "" {
+		return utils.Truncate(name, 255)
+	}
+	return "External alert"
+}
+
+// ServiceName maps the service_name, service or job label onto a service name
+func ServiceName(alert AlertmanagerAlert) string {
+	return utils.Truncate(firstLabel(alert.Labels, "service_name", "service", "job"), 255)
+}
+
+// Environment maps the environment or env label onto an environment
+func Environment(alert AlertmanagerAlert) string {
+	return utils.Truncate(firstLabel(alert.Labels, "environment", "env"), 50)
+}
+
+// Describe is the alert message: its summary (or description) annotation,
+// prefixed with the severity label, e.g. "[critical] Disk almost full on db-1"
+func Describe(alert AlertmanagerAlert) string {
+	message := firstLabel(alert.Annotations, "summary", "description", "message")
+	if message == "" {
+		message = Name(alert) + " is " + alert.Status
+	}
+	if severity := firstLabel(alert.Labels, "severity"); severity != "" {
+		message = "[" + severity + "] " + message
+	}
+	return utils.Truncate(message, 1024)
+}
+
+// SourceURL is the alert's generator URL when it is an http(s) URL, so links in
+// notifications can't point anywhere else
+func SourceURL(alert AlertmanagerAlert) string {
+	url := strings.TrimSpace(alert.GeneratorURL)
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return ""
+	}
+	return utils.Truncate(url, 2048)
+}
@@ -0,0 +1,139 @@
+package externalalerts
+
+import (
+	"log"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/incidents"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Result counts what a webhook delivery changed
+type Result struct {
+	Received  int `json:"received"`
+	Triggered int `json:"triggered"` // Alerts that started firing
+	Resolved  int `json:"resolved"`  // Alerts that stopped firing
+}
+
+// labelsMap converts labels to the JSON map stored on the external alert
+func labelsMap(labels map[string]string) models.JSONMap {
+	m := make(models.JSONMap, len(labels))
+	for key, value := range labels {
+		m[key] = value
+	}
+	return m
+}
+
+// Receive records one received alert. When it starts firing it raises an
+// EXTERNAL_FIRING alert and opens an incident; when it stops it raises an
+// EXTERNAL_RESOLVED alert and closes the incident. Repeat notifications for an
+// alert that is still firing (Alertmanager's repeat_interval) only refresh its state,
+// and resolutions of alerts never seen firing are ignored.
+func Receive(db *gorm.DB, orgID uint, source models.ExternalAlertSource, in AlertmanagerAlert, now time.Time) (*models.Alert, error) {
+	firing := in.Status != StatusResolved
+	var external models.ExternalAlert
+	var raised *models.Alert
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("org_id = ? AND source = ? AND fingerprint = ?", orgID, source, Fingerprint(in)).
+			First(&external).Error
+		if err == gorm.ErrRecordNotFound {
+			if !firing {
+				return nil
+			}
+			external = models.ExternalAlert{OrgID: orgID, Source: source, Fingerprint: Fingerprint(in)}
+		} else if err != nil {
+			return err
+		}
+
+		wasFiring := external.Firing
+		external.Name = Name(in)
+		external.ServiceName = ServiceName(in)
+		external.Environment = Environment(in)
+		external.Labels = labelsMap(in.Labels)
+		external.Annotations = labelsMap(in.Annotations)
+		external.GeneratorURL = SourceURL(in)
+		external.Firing = firing
+		external.ReceivedAt = now
+		if firing && !wasFiring {
+			external.StartsAt = now
+			if !in.StartsAt.IsZero() {
+				external.StartsAt = in.StartsAt
+			}
+			external.EndsAt = nil
+		}
+		if !firing && wasFiring {
+			endsAt := now
+			if !in.EndsAt.IsZero() {
+				endsAt = in.EndsAt
+			}
+			external.EndsAt = &endsAt
+		}
+		if err := tx.Save(&external).Error; err != nil {
+			return err
+		}
+		if firing == wasFiring {
+			return nil
+		}
+
+		alert := models.Alert{
+			OrgID:           orgID,
+			ExternalAlertID: &external.ID,
+			AlertType:       models.AlertTypeExternalResolved,
+			ErrorMessage:    Describe(in),
+			Link:            external.GeneratorURL,
+		}
+		if firing {
+			alert.AlertType = models.AlertTypeExternalFiring
+		}
+		if err := tx.Create(&alert).Error; err != nil {
+			return err
+		}
+		// Enqueued in the same transaction: the sender's retry only alerts again if
+		// this rolls back, so the alert must never be stored without its jobs
+		if _, err := notifier.EnqueueAlert(tx, alert, external.Subject()); err != nil {
+			return err
+		}
+		raised = &alert
+		return nil
+	})
+	if err != nil || raised == nil {
+		return nil, err
+	}
+	log.Printf("Alert created: external=%d source=%s type=%s", external.ID, source, raised.AlertType)
+
+	if firing {
+		if _, err := incidents.OpenForExternalAlert(db, raised, external); err != nil {
+			log.Printf("Failed to open incident for external alert %d: %v", external.ID, err)
+		}
+	} else if err := incidents.ResolveForExternalAlert(db, external.ID, raised, now); err != nil {
+		log.Printf("Failed to resolve incident for external alert %d: %v", external.ID, err)
+	}
+	return raised, nil
+}
+
+// ReceiveAll records every alert of a webhook delivery. It stops at the first
+// error so the sender retries the delivery; alerts already recorded are not
+// alerted on again.
+func ReceiveAll(db *gorm.DB, orgID uint, source models.ExternalAlertSource, alerts []AlertmanagerAlert) (Result, error) {
+	result := Result{Received: len(alerts)}
+	now := time.Now()
+	for _, in := range alerts {
+		raised, err := Receive(db, orgID, source, in, now)
+		if err != nil {
+			return result, err
+		}
+		if raised == nil {
+			continue
+		}
+		if raised.AlertType == models.AlertTypeExternalFiring {
+			result.Triggered++
+		} else {
+			result.Resolved++
+		}
+	}
+	return result, nil
+}
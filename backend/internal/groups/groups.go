@@ -0,0 +1,77 @@
+package groups
+
+import (
+	"errors"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrParentNotFound is returned when a parent group does not exist in the org
+var ErrParentNotFound = errors.New("parent group not found")
+
+// ErrCycle is returned when a group would be nested inside itself
+var ErrCycle = errors.New("a group cannot be nested inside itself or one of its descendants")
+
+// DescendantIDs returns the ID of the group plus the IDs of every group nested below it.
+// UNION (rather than UNION ALL) keeps the recursion finite even if bad data forms a loop.
+func DescendantIDs(db *gorm.DB, orgID, groupID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id FROM check_groups
+			WHERE id = ? AND org_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT g.id FROM check_groups g
+			JOIN tree t ON g.parent_id = t.id
+			WHERE g.deleted_at IS NULL
+		)
+		SELECT id FROM tree
+	`, groupID, orgID).Scan(&ids).Error
+	return ids, err
+}
+
+// AncestorIDs returns the ID of the group plus the IDs of all of its parents up to the root
+func AncestorIDs(db *gorm.DB, groupID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id FROM check_groups
+			WHERE id = ? AND deleted_at IS NULL
+			UNION
+			SELECT g.id, g.parent_id FROM check_groups g
+			JOIN chain c ON g.id = c.parent_id
+			WHERE g.deleted_at IS NULL
+		)
+		SELECT id FROM chain
+	`, groupID).Scan(&ids).Error
+	return ids, err
+}
+
+// ValidateParent ensures parentID belongs to the org and is not the group itself
+// or one of its descendants. groupID is 0 for groups that don't exist yet.
+func ValidateParent(db *gorm.DB, orgID, groupID uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	var parent models.CheckGroup
+	if err := db.Where("id = ? AND org_id = ?", *parentID, orgID).First(&parent).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrParentNotFound
+		}
+		return err
+	}
+	if groupID == 0 {
+		return nil
+	}
+	descendants, err := DescendantIDs(db, orgID, groupID)
+	if err != nil {
+		return err
+	}
+	for _, id := range descendants {
+		if id == *parentID {
+			return ErrCycle
+		}
+	}
+	return nil
+}
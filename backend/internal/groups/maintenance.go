@@ -0,0 +1,31 @@
+package groups
+
+import (
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// InMaintenance returns true if an active maintenance window covers the check,
+// either directly or through its group or any of the group's ancestors
+func InMaintenance(db *gorm.DB, check models.Check, at time.Time) (bool, error) {
+	query := db.Model(&models.MaintenanceWindow{}).
+		Where("org_id = ? AND starts_at <= ? AND ends_at > ?", check.OrgID, at, at)
+
+	if check.GroupID != nil {
+		groupIDs, err := AncestorIDs(db, *check.GroupID)
+		if err != nil {
+			return false, err
+		}
+		query = query.Where("check_id = ? OR group_id IN ?", check.ID, groupIDs)
+	} else {
+		query = query.Where("check_id = ?", check.ID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
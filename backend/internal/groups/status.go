@@ -0,0 +1,147 @@
+package groups
+
+import (
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// Member states used when aggregating a group
+const (
+	MemberUp      = "up"
+	MemberDown    = "down"
+	MemberUnknown = "unknown" // Never checked yet
+	MemberPaused  = "paused"  // Inactive checks are ignored by aggregation
+)
+
+// Summary is the aggregate status of a group and the member counts behind it
+type Summary struct {
+	GroupID          uint                    `json:"group_id"`
+	Status           models.GroupStatus      `json:"status"`
+	Aggregation      models.GroupAggregation `json:"aggregation"`
+	ThresholdPercent int                     `json:"threshold_percent,omitempty"`
+	Total            int                     `json:"total"`
+	Up               int                     `json:"up"`
+	Down             int                     `json:"down"`
+	Unknown          int                     `json:"unknown"`
+	Paused           int                     `json:"paused"`
+	DownPercentage   float64                 `json:"down_percentage"`
+}
+
+// MemberStatus classifies a single check for aggregation
+func MemberStatus(check models.Check) string {
+	if !check.IsActive {
+		return MemberPaused
+	}
+	if check.LastSuccess != nil {
+		if *check.LastSuccess {
+			return MemberUp
+		}
+		return MemberDown
+	}
+	if check.LastStatus == nil {
+		return MemberUnknown
+	}
+	if *check.LastStatus >= 200 && *check.LastStatus < 300 {
+		return MemberUp
+	}
+	return MemberDown
+}
+
+// Aggregate computes a group's status from its member checks
+func Aggregate(group models.CheckGroup, checks []models.Check) Summary {
+	summary := Summary{
+		GroupID:     group.ID,
+		Aggregation: group.Aggregation,
+		Total:       len(checks),
+	}
+	for _, check := range checks {
+		switch MemberStatus(check) {
+		case MemberUp:
+			summary.Up++
+		case MemberDown:
+			summary.Down++
+		case MemberUnknown:
+			summary.Unknown++
+		case MemberPaused:
+			summary.Paused++
+		}
+	}
+	evaluated := summary.Up + summary.Down
+	if evaluated == 0 {
+		summary.Status = models.GroupStatusUnknown
+		return summary
+	}
+	summary.DownPercentage = float64(summary.Down) / float64(evaluated) * 100
+
+	switch group.Aggregation {
+	case models.GroupAggregationThreshold:
+		summary.ThresholdPercent = group.ThresholdPercent
+		switch {
+		case summary.DownPercentage >= float64(group.ThresholdPercent):
+			summary.Status = models.GroupStatusDown
+		case summary.Down > 0:
+			summary.Status = models.GroupStatusDegraded
+		default:
+			summary.Status = models.GroupStatusUp
+		}
+	default:
+		// Worst-of: a single failing member takes the group down
+		if summary.Down > 0 {
+			summary.Status = models.GroupStatusDown
+		} else {
+			summary.Status = models.GroupStatusUp
+		}
+	}
+	return summary
+}
+
+// ComputeStatus loads every check in the group and its nested groups and aggregates them
+func ComputeStatus(db *gorm.DB, group models.CheckGroup) (Summary, error) {
+	ids, err := DescendantIDs(db, group.OrgID, group.ID)
+	if err != nil {
+		return Summary{}, err
+	}
+	var checks []models.Check
+	if len(ids) > 0 {
+		if err := db.Where("org_id = ? AND group_id IN ?", group.OrgID, ids).Find(&checks).Error; err != nil {
+			return Summary{}, err
+		}
+	}
+	return Aggregate(group, checks), nil
+}
+
+// AggregateAll computes summaries for every group in one pass from preloaded
+// groups and checks, so listing a whole tree doesn't cost a query per group
+func AggregateAll(groups []models.CheckGroup, checks []models.Check) map[uint]Summary {
+	children := make(map[uint][]uint)
+	for _, g := range groups {
+		if g.ParentID != nil {
+			children[*g.ParentID] = append(children[*g.ParentID], g.ID)
+		}
+	}
+	checksByGroup := make(map[uint][]models.Check)
+	for _, check := range checks {
+		if check.GroupID != nil {
+			checksByGroup[*check.GroupID] = append(checksByGroup[*check.GroupID], check)
+		}
+	}
+
+	summaries := make(map[uint]Summary, len(groups))
+	for _, g := range groups {
+		var members []models.Check
+		visited := map[uint]bool{}
+		stack := []uint{g.ID}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			members = append(members, checksByGroup[id]...)
+			stack = append(stack, children[id]...)
+		}
+		summaries[g.ID] = Aggregate(g, members)
+	}
+	return summaries
+}
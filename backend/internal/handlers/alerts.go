@@ -11,13 +11,19 @@ import (
 
 // AlertResponse is the DTO for alert API responses
 type AlertResponse struct {
-    ID           uint             `json:"id"`
-    CreatedAt    time.Time        `json:"created_at"`
-    CheckID      uint             `json:"check_id"`
-    CheckName    string           `json:"check_name,omitempty"`
-    AlertType    models.AlertType `json:"alert_type"`
-    StatusCode   int              `json:"status_code"`
-    ErrorMessage string           `json:"error_message,omitempty"`
+    ID               uint             `json:"id"`
+    CreatedAt        time.Time        `json:"created_at"`
+    CheckID          *uint            `json:"check_id"`
+    CheckName        string           `json:"check_name,omitempty"` // Rule name for log and trace alerts, alertname for external ones
+    LogAlertRuleID   *uint            `json:"log_alert_rule_id,omitempty"`
+    Link             string           `json:"link,omitempty"` // Dashboard path of the matching log search, or the external source URL
+    TraceAlertRuleID *uint            `json:"trace_alert_rule_id,omitempty"`
+    TraceIDs         []string         `json:"trace_ids,omitempty"`
+    ExternalAlertID  *uint            `json:"external_alert_id,omitempty"`
+    AlertType        models.AlertType `json:"alert_type"`
+    StatusCode       int              `json:"status_code"`
+    ErrorMessage     string           `json:"error_message,omitempty"`
+    IncidentID       *uint            `json:"incident_id,omitempty"`
 }
 
 // AlertsListResponse wraps the alerts array for consistent API responses
@@ -48,16 +54,38 @@ func parseAlertQueryParams(c *fiber.Ctx) (limit int, cutoff time.Time) {
     return limit, cutoff
 }
 
+// alertSubjectName is the name of the check, rule or external alert an alert is about.
+// The matching relation must be preloaded.
+func alertSubjectName(alert models.Alert) string {
+    switch {
+    case alert.Check.ID != 0:
+        return alert.Check.Name
+    case alert.LogAlertRule != nil:
+        return alert.LogAlertRule.Name
+    case alert.TraceAlertRule != nil:
+        return alert.TraceAlertRule.Name
+    case alert.ExternalAlert != nil:
+        return alert.ExternalAlert.Name
+    }
+    return ""
+}
+
 // toAlertResponse converts a model to DTO
 func toAlertResponse(alert models.Alert, checkName string) AlertResponse {
     return AlertResponse{
-        ID:           alert.ID,
-        CreatedAt:    alert.CreatedAt,
-        CheckID:      alert.CheckID,
-        CheckName:    checkName,
-        AlertType:    alert.AlertType,
-        StatusCode:   alert.StatusCode,
-        ErrorMessage: alert.ErrorMessage,
+        ID:               alert.ID,
+        CreatedAt:        alert.CreatedAt,
+        CheckID:          alert.CheckID,
+        CheckName:        checkName,
+        LogAlertRuleID:   alert.LogAlertRuleID,
+        Link:             alert.Link,
+        TraceAlertRuleID: alert.TraceAlertRuleID,
+        TraceIDs:         alert.TraceIDs,
+        ExternalAlertID:  alert.ExternalAlertID,
+        AlertType:        alert.AlertType,
+        StatusCode:       alert.StatusCode,
+        ErrorMessage:     alert.ErrorMessage,
+        IncidentID:       alert.IncidentID,
     }
 }
 
@@ -103,15 +131,25 @@ func GetCheckAlerts(db *gorm.DB) fiber.Handler {
     }
 }
 
-// GetOrgAlerts returns all alerts for the current organization
+// GetOrgAlerts returns all alerts for the current organization.
+// Pass ?incident_id=<id> to list the alerts of one incident.
 func GetOrgAlerts(db *gorm.DB) fiber.Handler {
     return func(c *fiber.Ctx) error {
         orgID := c.Locals("orgID").(uint)
         limit, cutoff := parseAlertQueryParams(c)
-        // Query alerts for this org within time window, preload check for name
+        // Query alerts for this org within time window, preload check, rule or external alert for name
+        query := db.Preload("Check").Preload("LogAlertRule").Preload("TraceAlertRule").Preload("ExternalAlert").Where("org_id = ? AND created_at >= ?", orgID, cutoff)
+        if incidentParam := c.Query("incident_id"); incidentParam != "" {
+            incidentID, err := strconv.ParseUint(incidentParam, 10, 32)
+            if err != nil {
+                return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                    "error": "invalid incident ID",
+                })
+            }
+            query = query.Where("incident_id = ?", incidentID)
+        }
         var alerts []models.Alert
-        if err := db.Preload("Check").
-            Where("org_id = ? AND created_at >= ?", orgID, cutoff).
+        if err := query.
             Order("created_at DESC").
             Limit(limit).
             Find(&alerts).Error; err != nil {
@@ -122,11 +160,7 @@ func GetOrgAlerts(db *gorm.DB) fiber.Handler {
         // Convert to response DTOs
         response := make([]AlertResponse, len(alerts))
         for i, alert := range alerts {
-            checkName := ""
-            if alert.Check.ID != 0 {
-                checkName = alert.Check.Name
-            }
-            response[i] = toAlertResponse(alert, checkName)
+            response[i] = toAlertResponse(alert, alertSubjectName(alert))
         }
         return c.JSON(AlertsListResponse{Alerts: response})
     }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -8,39 +9,88 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/oFuterman/light-house/internal/billing"
+	"github.com/oFuterman/light-house/internal/groups"
+	"github.com/oFuterman/light-house/internal/incidents"
 	"github.com/oFuterman/light-house/internal/models"
 	"github.com/oFuterman/light-house/internal/search"
+	"github.com/oFuterman/light-house/internal/stats"
 	"gorm.io/gorm"
 )
 
 type CreateCheckRequest struct {
-	Name            string         `json:"name"`
-	URL             string         `json:"url"`
-	IntervalSeconds int            `json:"interval_seconds"`
-	ServiceName     string         `json:"service_name,omitempty"`
-	Environment     string         `json:"environment,omitempty"`
-	Region          string         `json:"region,omitempty"`
-	Tags            models.JSONMap `json:"tags,omitempty"`
+	Name                    string         `json:"name"`
+	URL                     string         `json:"url"`
+	IntervalSeconds         int            `json:"interval_seconds"`
+	ServiceName             string         `json:"service_name,omitempty"`
+	Environment             string         `json:"environment,omitempty"`
+	Region                  string         `json:"region,omitempty"`
+	Tags                    models.JSONMap `json:"tags,omitempty"`
+	GroupID                 *uint          `json:"group_id,omitempty"`
+	SecurityAudit           bool           `json:"security_audit,omitempty"`
+	CheckType               string         `json:"check_type,omitempty"` // http (default) or crawl
+	CrawlMaxDepth           int            `json:"crawl_max_depth,omitempty"`
+	CrawlMaxPages           int            `json:"crawl_max_pages,omitempty"`
+	EscalationPolicyID      *uint          `json:"escalation_policy_id,omitempty"`
+	AlertSuppressionSeconds *int           `json:"alert_suppression_seconds,omitempty"` // Omit for the default
+	FlapThresholdPercent    *int           `json:"flap_threshold_percent,omitempty"`    // Omit for the default, 0 disables
+	AnomalyDetection        bool           `json:"anomaly_detection,omitempty"`
+	AnomalySensitivity      *float64       `json:"anomaly_sensitivity,omitempty"`      // Standard deviations; omit for the default
+	AnomalyConsecutiveRuns  *int           `json:"anomaly_consecutive_runs,omitempty"` // Omit for the default
 }
 
 type UpdateCheckRequest struct {
-	Name            *string         `json:"name,omitempty"`
-	URL             *string         `json:"url,omitempty"`
-	IntervalSeconds *int            `json:"interval_seconds,omitempty"`
-	IsActive        *bool           `json:"is_active,omitempty"`
-	ServiceName     *string         `json:"service_name,omitempty"`
-	Environment     *string         `json:"environment,omitempty"`
-	Region          *string         `json:"region,omitempty"`
-	Tags            *models.JSONMap `json:"tags,omitempty"`
+	Name                    *string         `json:"name,omitempty"`
+	URL                     *string         `json:"url,omitempty"`
+	IntervalSeconds         *int            `json:"interval_seconds,omitempty"`
+	IsActive                *bool           `json:"is_active,omitempty"`
+	ServiceName             *string         `json:"service_name,omitempty"`
+	Environment             *string         `json:"environment,omitempty"`
+	Region                  *string         `json:"region,omitempty"`
+	Tags                    *models.JSONMap `json:"tags,omitempty"`
+	GroupID                 *uint           `json:"group_id,omitempty"` // 0 removes the check from its group
+	SecurityAudit           *bool           `json:"security_audit,omitempty"`
+	CheckType               *string         `json:"check_type,omitempty"`
+	CrawlMaxDepth           *int            `json:"crawl_max_depth,omitempty"`
+	CrawlMaxPages           *int            `json:"crawl_max_pages,omitempty"`
+	EscalationPolicyID      *uint           `json:"escalation_policy_id,omitempty"` // 0 detaches the policy
+	AlertSuppressionSeconds *int            `json:"alert_suppression_seconds,omitempty"`
+	FlapThresholdPercent    *int            `json:"flap_threshold_percent,omitempty"` // 0 disables flap detection
+	AnomalyDetection        *bool           `json:"anomaly_detection,omitempty"`
+	AnomalySensitivity      *float64        `json:"anomaly_sensitivity,omitempty"`
+	AnomalyConsecutiveRuns  *int            `json:"anomaly_consecutive_runs,omitempty"`
 }
 
-// ListChecks returns all checks for the current organization
+// ListChecks returns all checks for the current organization.
+// Pass ?group_id=<id> to filter by group (add &recursive=true to include nested groups)
+// or ?group_id=none for ungrouped checks.
 func ListChecks(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		orgID := c.Locals("orgID").(uint)
 
+		query := db.Where("org_id = ?", orgID)
+		if groupParam := c.Query("group_id"); groupParam == "none" {
+			query = query.Where("group_id IS NULL")
+		} else if groupParam != "" {
+			groupID, err := strconv.ParseUint(groupParam, 10, 32)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "invalid group ID",
+				})
+			}
+			groupIDs := []uint{uint(groupID)}
+			if c.Query("recursive") == "true" {
+				groupIDs, err = groups.DescendantIDs(db, orgID, uint(groupID))
+				if err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"error": "failed to fetch check group tree",
+					})
+				}
+			}
+			query = query.Where("group_id IN ?", groupIDs)
+		}
+
 		var checks []models.Check
-		if err := db.Where("org_id = ?", orgID).Order("created_at DESC").Find(&checks).Error; err != nil {
+		if err := query.Order("created_at DESC").Find(&checks).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "failed to fetch checks",
 			})
@@ -50,92 +100,189 @@ func ListChecks(db *gorm.DB) fiber.Handler {
 	}
 }
 
-// CreateCheck creates a new uptime check
-func CreateCheck(db *gorm.DB) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		orgID := c.Locals("orgID").(uint)
+// validateCheckType normalizes a check's type and crawl limits, filling in defaults.
+// Crawl checks are raised to the minimum crawl interval so runs never overlap.
+func validateCheckType(check *models.Check) error {
+	check.CheckType = strings.ToLower(strings.TrimSpace(check.CheckType))
+	if check.CheckType == "" {
+		check.CheckType = models.CheckTypeHTTP
+	}
+	if check.CheckType != models.CheckTypeHTTP && check.CheckType != models.CheckTypeCrawl {
+		return fiber.NewError(fiber.StatusBadRequest, "check_type must be http or crawl")
+	}
+	if check.CrawlMaxDepth == 0 {
+		check.CrawlMaxDepth = models.DefaultCrawlMaxDepth
+	}
+	if check.CrawlMaxPages == 0 {
+		check.CrawlMaxPages = models.DefaultCrawlMaxPages
+	}
+	if check.CrawlMaxDepth < 1 || check.CrawlMaxDepth > models.MaxCrawlDepth {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("crawl_max_depth must be between 1 and %d", models.MaxCrawlDepth))
+	}
+	if check.CrawlMaxPages < 1 || check.CrawlMaxPages > models.MaxCrawlPages {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("crawl_max_pages must be between 1 and %d", models.MaxCrawlPages))
+	}
+	if check.CheckType == models.CheckTypeCrawl && check.IntervalSeconds < models.MinCrawlIntervalSeconds {
+		check.IntervalSeconds = models.MinCrawlIntervalSeconds
+	}
+	return nil
+}
 
-		var req CreateCheckRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "invalid request body",
-			})
-		}
+// validateAlertNoiseSettings checks the suppression window, flap threshold and anomaly settings
+func validateAlertNoiseSettings(check *models.Check) error {
+	if s := check.AlertSuppressionSeconds; s != nil && (*s < 0 || *s > models.MaxAlertSuppressionSeconds) {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("alert_suppression_seconds must be between 0 and %d", models.MaxAlertSuppressionSeconds))
+	}
+	if t := check.FlapThresholdPercent; t != nil && (*t < 0 || *t > 100) {
+		return fiber.NewError(fiber.StatusBadRequest, "flap_threshold_percent must be between 0 and 100")
+	}
+	if k := check.AnomalySensitivity; k != nil && (*k < models.MinAnomalySensitivity || *k > models.MaxAnomalySensitivity) {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("anomaly_sensitivity must be between %g and %g", models.MinAnomalySensitivity, models.MaxAnomalySensitivity))
+	}
+	if n := check.AnomalyConsecutiveRuns; n != nil && (*n < 1 || *n > models.MaxAnomalyConsecutiveRuns) {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("anomaly_consecutive_runs must be between 1 and %d", models.MaxAnomalyConsecutiveRuns))
+	}
+	return nil
+}
 
-		// Validate input
-		req.Name = strings.TrimSpace(req.Name)
-		req.URL = strings.TrimSpace(req.URL)
+// checkLimitError is returned by createCheck when the org is at its plan's check limit
+type checkLimitError struct {
+	Message string
+	Current int
+}
 
-		if req.Name == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "name is required",
-			})
-		}
+func (e *checkLimitError) Error() string {
+	return e.Message
+}
 
-		if req.URL == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "url is required",
-			})
-		}
+// createCheck validates the request and creates the check with its initial revision.
+// CreateCheck and the import accept step both go through here so they enforce the
+// same validation and plan limits.
+func createCheck(c *fiber.Ctx, db *gorm.DB, req CreateCheckRequest) (*models.Check, error) {
+	orgID := c.Locals("orgID").(uint)
+	userID := c.Locals("userID").(uint)
 
-		// Validate URL format
-		parsedURL, err := url.ParseRequestURI(req.URL)
-		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "invalid URL format (must be http or https)",
-			})
-		}
+	// Validate input
+	req.Name = strings.TrimSpace(req.Name)
+	req.URL = strings.TrimSpace(req.URL)
 
-		// Load org to get plan
-		var org models.Organization
-		if err := db.First(&org, orgID).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "failed to load organization",
-			})
-		}
+	if req.Name == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "name is required")
+	}
 
-		// Check plan limits - can we create another check?
-		currentCount, err := billing.GetCurrentCheckCount(db, orgID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "failed to check limits",
-			})
-		}
-		if allowed, msg := billing.CanCreateCheck(org.Plan, currentCount); !allowed {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":       msg,
-				"limit_type":  "checks",
-				"current":     currentCount,
-				"upgrade_url": "/settings?tab=billing",
-			})
-		}
+	if req.URL == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "url is required")
+	}
 
-		// Validate interval against plan minimum
-		planConfig := models.GetPlanConfig(org.Plan)
-		if req.IntervalSeconds < planConfig.CheckIntervalMinSeconds {
-			req.IntervalSeconds = planConfig.CheckIntervalMinSeconds
-		}
+	// Validate URL format
+	parsedURL, err := url.ParseRequestURI(req.URL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid URL format (must be http or https)")
+	}
+
+	groupID, err := validateCheckGroupID(db, orgID, req.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	policyID, err := validateEscalationPolicyID(db, orgID, req.EscalationPolicyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load org to get plan
+	var org models.Organization
+	if err := db.First(&org, orgID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to load organization")
+	}
+
+	// Check plan limits - can we create another check?
+	currentCount, err := billing.GetCurrentCheckCount(db, orgID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to check limits")
+	}
+	if allowed, msg := billing.CanCreateCheck(org.Plan, currentCount); !allowed {
+		return nil, &checkLimitError{Message: msg, Current: currentCount}
+	}
+
+	// Validate interval against plan minimum
+	planConfig := models.GetPlanConfig(org.Plan)
+	if req.IntervalSeconds < planConfig.CheckIntervalMinSeconds {
+		req.IntervalSeconds = planConfig.CheckIntervalMinSeconds
+	}
+
+	check := models.Check{
+		OrgID:                   orgID,
+		Name:                    req.Name,
+		URL:                     req.URL,
+		IntervalSeconds:         req.IntervalSeconds,
+		IsActive:                true,
+		ServiceName:             strings.TrimSpace(req.ServiceName),
+		Environment:             strings.TrimSpace(req.Environment),
+		Region:                  strings.TrimSpace(req.Region),
+		Tags:                    req.Tags,
+		GroupID:                 groupID,
+		SecurityAudit:           req.SecurityAudit,
+		CheckType:               req.CheckType,
+		CrawlMaxDepth:           req.CrawlMaxDepth,
+		CrawlMaxPages:           req.CrawlMaxPages,
+		EscalationPolicyID:      policyID,
+		AlertSuppressionSeconds: req.AlertSuppressionSeconds,
+		FlapThresholdPercent:    req.FlapThresholdPercent,
+		AnomalyDetection:        req.AnomalyDetection,
+		AnomalySensitivity:      req.AnomalySensitivity,
+		AnomalyConsecutiveRuns:  req.AnomalyConsecutiveRuns,
+	}
+	if err := validateCheckType(&check); err != nil {
+		return nil, err
+	}
+	if err := validateAlertNoiseSettings(&check); err != nil {
+		return nil, err
+	}
 
-		check := models.Check{
-			OrgID:           orgID,
-			Name:            req.Name,
-			URL:             req.URL,
-			IntervalSeconds: req.IntervalSeconds,
-			IsActive:        true,
-			ServiceName:     strings.TrimSpace(req.ServiceName),
-			Environment:     strings.TrimSpace(req.Environment),
-			Region:          strings.TrimSpace(req.Region),
-			Tags:            req.Tags,
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&check).Error; err != nil {
+			return err
 		}
+		_, err := recordCheckRevision(tx, check, nil, models.CheckRevisionCreated, &userID, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to create check")
+	}
 
-		if err := db.Create(&check).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "failed to create check",
+	// Sync usage counts after creating
+	billing.SyncResourceCounts(db, orgID)
+
+	logAuditEvent(db, orgID, &userID, models.AuditActionCheckCreated, "check", &check.ID, models.JSONMap{
+		"name":   check.Name,
+		"config": check.Config().ToMap(),
+	}, c.IP(), c.Get("User-Agent"))
+
+	return &check, nil
+}
+
+// CreateCheck creates a new uptime check
+func CreateCheck(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req CreateCheckRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
 			})
 		}
 
-		// Sync usage counts after creating
-		billing.SyncResourceCounts(db, orgID)
+		check, err := createCheck(c, db, req)
+		if err != nil {
+			if limitErr, ok := err.(*checkLimitError); ok {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error":       limitErr.Message,
+					"limit_type":  "checks",
+					"current":     limitErr.Current,
+					"upgrade_url": "/settings?tab=billing",
+				})
+			}
+			return respondError(c, err)
+		}
 
 		return c.Status(fiber.StatusCreated).JSON(check)
 	}
@@ -168,10 +315,111 @@ func GetCheck(db *gorm.DB) fiber.Handler {
 	}
 }
 
-// UpdateCheck updates an existing check
+// applyUpdateCheckRequest validates the request and applies the fields it sets to check
+func applyUpdateCheckRequest(db *gorm.DB, orgID uint, check *models.Check, req UpdateCheckRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "name cannot be empty")
+		}
+		check.Name = name
+	}
+
+	if req.URL != nil {
+		urlStr := strings.TrimSpace(*req.URL)
+		parsedURL, err := url.ParseRequestURI(urlStr)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid URL format")
+		}
+		check.URL = urlStr
+	}
+
+	if req.IntervalSeconds != nil {
+		interval := *req.IntervalSeconds
+		if interval < 60 {
+			interval = 60
+		}
+		check.IntervalSeconds = interval
+	}
+
+	if req.IsActive != nil {
+		check.IsActive = *req.IsActive
+	}
+
+	if req.ServiceName != nil {
+		check.ServiceName = strings.TrimSpace(*req.ServiceName)
+	}
+
+	if req.Environment != nil {
+		check.Environment = strings.TrimSpace(*req.Environment)
+	}
+
+	if req.Region != nil {
+		check.Region = strings.TrimSpace(*req.Region)
+	}
+
+	if req.Tags != nil {
+		check.Tags = *req.Tags
+	}
+
+	if req.SecurityAudit != nil {
+		check.SecurityAudit = *req.SecurityAudit
+	}
+
+	if req.GroupID != nil {
+		groupID, err := validateCheckGroupID(db, orgID, req.GroupID)
+		if err != nil {
+			return err
+		}
+		check.GroupID = groupID
+	}
+
+	if req.EscalationPolicyID != nil {
+		policyID, err := validateEscalationPolicyID(db, orgID, req.EscalationPolicyID)
+		if err != nil {
+			return err
+		}
+		check.EscalationPolicyID = policyID
+	}
+
+	if req.CheckType != nil {
+		check.CheckType = *req.CheckType
+	}
+	if req.CrawlMaxDepth != nil {
+		check.CrawlMaxDepth = *req.CrawlMaxDepth
+	}
+	if req.CrawlMaxPages != nil {
+		check.CrawlMaxPages = *req.CrawlMaxPages
+	}
+	if err := validateCheckType(check); err != nil {
+		return err
+	}
+	if req.AlertSuppressionSeconds != nil {
+		check.AlertSuppressionSeconds = req.AlertSuppressionSeconds
+	}
+	if req.FlapThresholdPercent != nil {
+		check.FlapThresholdPercent = req.FlapThresholdPercent
+	}
+	if req.AnomalyDetection != nil {
+		check.AnomalyDetection = *req.AnomalyDetection
+	}
+	if req.AnomalySensitivity != nil {
+		check.AnomalySensitivity = req.AnomalySensitivity
+	}
+	if req.AnomalyConsecutiveRuns != nil {
+		check.AnomalyConsecutiveRuns = req.AnomalyConsecutiveRuns
+	}
+	if err := validateAlertNoiseSettings(check); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateCheck updates an existing check and records a revision of the change
 func UpdateCheck(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
 		checkID, err := strconv.ParseUint(c.Params("id"), 10, 32)
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -199,62 +447,41 @@ func UpdateCheck(db *gorm.DB) fiber.Handler {
 			})
 		}
 
-		// Apply updates
-		if req.Name != nil {
-			name := strings.TrimSpace(*req.Name)
-			if name == "" {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "name cannot be empty",
-				})
+		var before models.CheckConfig
+		var changes models.JSONMap
+		err = db.Transaction(func(tx *gorm.DB) error {
+			locked, err := lockCheck(tx, check.ID)
+			if err != nil {
+				return err
 			}
-			check.Name = name
-		}
-
-		if req.URL != nil {
-			urlStr := strings.TrimSpace(*req.URL)
-			parsedURL, err := url.ParseRequestURI(urlStr)
-			if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "invalid URL format",
-				})
+			before = locked.Config()
+			if err := applyUpdateCheckRequest(tx, orgID, locked, req); err != nil {
+				return err
 			}
-			check.URL = urlStr
-		}
-
-		if req.IntervalSeconds != nil {
-			interval := *req.IntervalSeconds
-			if interval < 60 {
-				interval = 60
+			if changes, err = saveCheckWithRevision(tx, locked, before, &userID); err != nil {
+				return err
+			}
+			check = *locked
+			return nil
+		})
+		if err != nil {
+			if _, ok := err.(*fiber.Error); ok {
+				return respondError(c, err)
 			}
-			check.IntervalSeconds = interval
-		}
-
-		if req.IsActive != nil {
-			check.IsActive = *req.IsActive
-		}
-
-		if req.ServiceName != nil {
-			check.ServiceName = strings.TrimSpace(*req.ServiceName)
-		}
-
-		if req.Environment != nil {
-			check.Environment = strings.TrimSpace(*req.Environment)
-		}
-
-		if req.Region != nil {
-			check.Region = strings.TrimSpace(*req.Region)
-		}
-
-		if req.Tags != nil {
-			check.Tags = *req.Tags
-		}
-
-		if err := db.Save(&check).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "failed to update check",
 			})
 		}
 
+		if len(changes) > 0 {
+			logAuditEvent(db, orgID, &userID, models.AuditActionCheckUpdated, "check", &check.ID, models.JSONMap{
+				"name":    check.Name,
+				"before":  before.ToMap(),
+				"after":   check.Config().ToMap(),
+				"changes": changes,
+			}, c.IP(), c.Get("User-Agent"))
+		}
+
 		return c.JSON(check)
 	}
 }
@@ -263,30 +490,40 @@ func UpdateCheck(db *gorm.DB) fiber.Handler {
 func DeleteCheck(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		orgID := c.Locals("orgID").(uint)
-		checkID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		userID := c.Locals("userID").(uint)
+
+		check, err := findOrgCheck(c, db)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "invalid check ID",
-			})
+			return respondError(c, err)
 		}
 
-		// Find and delete check (soft delete due to gorm.DeletedAt)
-		result := db.Where("id = ? AND org_id = ?", checkID, orgID).Delete(&models.Check{})
-		if result.Error != nil {
+		// Soft delete (gorm.DeletedAt) and keep a final revision of the configuration
+		err = db.Transaction(func(tx *gorm.DB) error {
+			locked, err := lockCheck(tx, check.ID)
+			if err != nil {
+				return err
+			}
+			before := locked.Config()
+			if err := tx.Delete(locked).Error; err != nil {
+				return err
+			}
+			_, err = recordCheckRevision(tx, *locked, &before, models.CheckRevisionDeleted, &userID, nil)
+			return err
+		})
+		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "failed to delete check",
 			})
 		}
 
-		if result.RowsAffected == 0 {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "check not found",
-			})
-		}
-
 		// Sync usage counts after deleting
 		billing.SyncResourceCounts(db, orgID)
 
+		logAuditEvent(db, orgID, &userID, models.AuditActionCheckDeleted, "check", &check.ID, models.JSONMap{
+			"name":   check.Name,
+			"config": check.Config().ToMap(),
+		}, c.IP(), c.Get("User-Agent"))
+
 		return c.JSON(fiber.Map{
 			"message": "check deleted successfully",
 		})
@@ -354,16 +591,13 @@ func GetCheckResults(db *gorm.DB) fiber.Handler {
 
 // CheckSummaryResponse represents aggregated statistics for a check
 type CheckSummaryResponse struct {
-    CheckID          uint       `json:"check_id"`
-    WindowHours      int        `json:"window_hours"`
-    TotalRuns        int        `json:"total_runs"`
-    SuccessfulRuns   int        `json:"successful_runs"`
-    FailedRuns       int        `json:"failed_runs"`
-    UptimePercentage float64    `json:"uptime_percentage"`
-    AvgResponseMs    int        `json:"avg_response_ms"`
-    P95ResponseMs    int        `json:"p95_response_ms"`
-    LastStatus       *int       `json:"last_status"`
-    LastCheckedAt    *time.Time `json:"last_checked_at"`
+    CheckID     uint `json:"check_id"`
+    WindowHours int  `json:"window_hours"`
+    stats.CheckStats
+    LastStatus    *int       `json:"last_status"`
+    LastCheckedAt *time.Time `json:"last_checked_at"`
+    Incidents     int64      `json:"incidents"`
+    MTTRSeconds   *float64   `json:"mttr_seconds"` // Mean time to resolve incidents started in the window
 }
 
 // GetCheckSummary returns aggregated statistics for a check within a time window
@@ -399,11 +633,9 @@ func GetCheckSummary(db *gorm.DB) fiber.Handler {
             }
         }
         cutoff := time.Now().Add(-time.Duration(windowHours) * time.Hour)
-        // Fetch results within time window, ordered by response time for p95 calculation
-        var results []models.CheckResult
-        if err := db.Where("check_id = ? AND created_at >= ?", check.ID, cutoff).
-            Order("response_time_ms ASC").
-            Find(&results).Error; err != nil {
+        // Compute run statistics within the time window
+        checkStats, err := stats.ForCheck(db, check.ID, cutoff, time.Time{})
+        if err != nil {
             return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
                 "error": "failed to fetch results",
             })
@@ -412,42 +644,22 @@ func GetCheckSummary(db *gorm.DB) fiber.Handler {
         summary := CheckSummaryResponse{
             CheckID:       check.ID,
             WindowHours:   windowHours,
+            CheckStats:    checkStats,
             LastStatus:    check.LastStatus,
             LastCheckedAt: check.LastCheckedAt,
         }
-        totalRuns := len(results)
-        summary.TotalRuns = totalRuns
-        // Return early if no results
-        if totalRuns == 0 {
-            return c.JSON(summary)
-        }
-        // Compute statistics
-        var successfulRuns int
-        var totalResponseMs int64
-        for _, r := range results {
-            if r.StatusCode >= 200 && r.StatusCode <= 299 {
-                successfulRuns++
-            }
-            totalResponseMs += r.ResponseTimeMs
+        incidentStats, err := incidents.ComputeStats(db, orgID, &check.ID, cutoff)
+        if err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "failed to compute incident stats",
+            })
         }
-        summary.SuccessfulRuns = successfulRuns
-        summary.FailedRuns = totalRuns - successfulRuns
-        summary.UptimePercentage = float64(successfulRuns) / float64(totalRuns) * 100
-        summary.AvgResponseMs = int(totalResponseMs / int64(totalRuns))
-        summary.P95ResponseMs = int(results[p95Index(totalRuns)].ResponseTimeMs)
+        summary.Incidents = incidentStats.Total
+        summary.MTTRSeconds = incidentStats.MTTRSeconds
         return c.JSON(summary)
     }
 }
 
-// p95Index returns the index for the 95th percentile in a sorted slice
-func p95Index(length int) int {
-    idx := int(float64(length) * 0.95)
-    if idx >= length {
-        idx = length - 1
-    }
-    return idx
-}
-
 // CheckResultSearchDTO is the response DTO for check result search
 type CheckResultSearchDTO struct {
     ID             uint      `json:"id"`
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// BaselineBucketResponse is the expected response time for one hour of the week (UTC)
+type BaselineBucketResponse struct {
+	Bucket    int       `json:"bucket"`  // 0-167, hours since Sunday 00:00 UTC
+	Weekday   int       `json:"weekday"` // 0 = Sunday
+	Hour      int       `json:"hour"`
+	MeanMs    float64   `json:"mean_ms"`
+	StdDevMs  float64   `json:"stddev_ms"`
+	UpperMs   float64   `json:"upper_ms"` // Response times above this count toward an anomaly
+	Samples   int       `json:"samples"`
+	Ready     bool      `json:"ready"` // Enough samples to be used for detection
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetCheckBaseline returns a check's response time baseline for charting, one entry
+// per hour-of-week bucket that has samples
+func GetCheckBaseline(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		check, err := findOrgCheck(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var baselines []models.ResponseTimeBaseline
+		if err := db.Where("check_id = ?", check.ID).Order("bucket ASC").Find(&baselines).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch baseline",
+			})
+		}
+
+		threshold := check.AnomalyThreshold()
+		buckets := make([]BaselineBucketResponse, len(baselines))
+		for i, b := range baselines {
+			stdDev := b.StdDev()
+			buckets[i] = BaselineBucketResponse{
+				Bucket:    b.Bucket,
+				Weekday:   b.Bucket / 24,
+				Hour:      b.Bucket % 24,
+				MeanMs:    b.Mean,
+				StdDevMs:  stdDev,
+				UpperMs:   b.Mean + threshold*stdDev,
+				Samples:   b.Samples,
+				Ready:     b.Ready(),
+				UpdatedAt: b.UpdatedAt,
+			}
+		}
+
+		return c.JSON(fiber.Map{
+			"check_id":                 check.ID,
+			"anomaly_detection":        check.AnomalyDetection,
+			"anomaly_sensitivity":      threshold,
+			"anomaly_consecutive_runs": check.AnomalyRuns(),
+			"is_anomalous":             check.IsAnomalous,
+			"current_bucket":           models.BaselineBucket(time.Now()),
+			"buckets":                  buckets,
+		})
+	}
+}
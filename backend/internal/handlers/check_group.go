@@ -0,0 +1,485 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/billing"
+	"github.com/oFuterman/light-house/internal/groups"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+type CheckGroupRequest struct {
+	Name             *string                  `json:"name,omitempty"`
+	Description      *string                  `json:"description,omitempty"`
+	ParentID         *uint                    `json:"parent_id,omitempty"` // 0 moves the group to the top level
+	Aggregation      *models.GroupAggregation `json:"aggregation,omitempty"`
+	ThresholdPercent *int                     `json:"threshold_percent,omitempty"`
+}
+
+type CheckGroupResponse struct {
+	ID               uint                    `json:"id"`
+	ParentID         *uint                   `json:"parent_id"`
+	Name             string                  `json:"name"`
+	Description      string                  `json:"description,omitempty"`
+	Aggregation      models.GroupAggregation `json:"aggregation"`
+	ThresholdPercent int                     `json:"threshold_percent"`
+	CheckCount       int                     `json:"check_count"` // Direct members only
+	Status           groups.Summary          `json:"status"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+}
+
+type CheckGroupDetailResponse struct {
+	CheckGroupResponse
+	Children []CheckGroupResponse `json:"children"`
+	Checks   []models.Check       `json:"checks"`
+}
+
+// BulkCheckActionRequest is the request body for group bulk actions
+type BulkCheckActionRequest struct {
+	Action        string `json:"action"`                    // pause, resume, delete, move
+	TargetGroupID *uint  `json:"target_group_id,omitempty"` // Required for move (0 = ungrouped)
+	Recursive     *bool  `json:"recursive,omitempty"`       // Include nested groups (default true)
+}
+
+// toCheckGroupResponse converts a model to DTO
+func toCheckGroupResponse(group models.CheckGroup, checkCount int, status groups.Summary) CheckGroupResponse {
+	return CheckGroupResponse{
+		ID:               group.ID,
+		ParentID:         group.ParentID,
+		Name:             group.Name,
+		Description:      group.Description,
+		Aggregation:      group.Aggregation,
+		ThresholdPercent: group.ThresholdPercent,
+		CheckCount:       checkCount,
+		Status:           status,
+		CreatedAt:        group.CreatedAt,
+		UpdatedAt:        group.UpdatedAt,
+	}
+}
+
+// findCheckGroup loads a group by route param and verifies org ownership
+func findCheckGroup(c *fiber.Ctx, db *gorm.DB) (*models.CheckGroup, error) {
+	orgID := c.Locals("orgID").(uint)
+	groupID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid group ID")
+	}
+	var group models.CheckGroup
+	if err := db.Where("id = ? AND org_id = ?", groupID, orgID).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "check group not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch check group")
+	}
+	return &group, nil
+}
+
+// validateCheckGroupID verifies a group referenced by a check belongs to the org.
+// A zero ID means "no group" and is returned as nil.
+func validateCheckGroupID(db *gorm.DB, orgID uint, groupID *uint) (*uint, error) {
+	if groupID == nil || *groupID == 0 {
+		return nil, nil
+	}
+	var count int64
+	if err := db.Model(&models.CheckGroup{}).Where("id = ? AND org_id = ?", *groupID, orgID).Count(&count).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch check group")
+	}
+	if count == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "check group not found")
+	}
+	return groupID, nil
+}
+
+// applyCheckGroupRequest validates and copies request fields onto the group
+func applyCheckGroupRequest(db *gorm.DB, group *models.CheckGroup, req CheckGroupRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "name cannot be empty")
+		}
+		group.Name = name
+	}
+	if req.Description != nil {
+		group.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			group.ParentID = nil
+		} else {
+			if err := groups.ValidateParent(db, group.OrgID, group.ID, req.ParentID); err != nil {
+				if err == groups.ErrParentNotFound || err == groups.ErrCycle {
+					return fiber.NewError(fiber.StatusBadRequest, err.Error())
+				}
+				return fiber.NewError(fiber.StatusInternalServerError, "failed to validate parent group")
+			}
+			parentID := *req.ParentID
+			group.ParentID = &parentID
+		}
+	}
+	if req.Aggregation != nil {
+		if !req.Aggregation.IsValid() {
+			return fiber.NewError(fiber.StatusBadRequest, "aggregation must be worst_of or threshold")
+		}
+		group.Aggregation = *req.Aggregation
+	}
+	if req.ThresholdPercent != nil {
+		if *req.ThresholdPercent < 1 || *req.ThresholdPercent > 100 {
+			return fiber.NewError(fiber.StatusBadRequest, "threshold_percent must be between 1 and 100")
+		}
+		group.ThresholdPercent = *req.ThresholdPercent
+	}
+	return nil
+}
+
+// ListCheckGroups returns all check groups for the organization with their aggregate status
+func ListCheckGroups(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var checkGroups []models.CheckGroup
+		if err := db.Where("org_id = ?", orgID).Order("name ASC").Find(&checkGroups).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch check groups",
+			})
+		}
+
+		var checks []models.Check
+		if err := db.Where("org_id = ? AND group_id IS NOT NULL", orgID).Find(&checks).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch checks",
+			})
+		}
+
+		directCounts := make(map[uint]int)
+		for _, check := range checks {
+			directCounts[*check.GroupID]++
+		}
+		summaries := groups.AggregateAll(checkGroups, checks)
+
+		responses := make([]CheckGroupResponse, len(checkGroups))
+		for i, group := range checkGroups {
+			responses[i] = toCheckGroupResponse(group, directCounts[group.ID], summaries[group.ID])
+		}
+
+		return c.JSON(fiber.Map{
+			"groups": responses,
+		})
+	}
+}
+
+// CreateCheckGroup creates a new check group
+func CreateCheckGroup(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req CheckGroupRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "name is required",
+			})
+		}
+
+		group := models.CheckGroup{
+			OrgID:            orgID,
+			Aggregation:      models.GroupAggregationWorstOf,
+			ThresholdPercent: 50,
+		}
+		if err := applyCheckGroupRequest(db, &group, req); err != nil {
+			return respondError(c, err)
+		}
+
+		if err := db.Create(&group).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create check group",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionCheckGroupCreated, "check_group", &group.ID, models.JSONMap{
+			"name":      group.Name,
+			"parent_id": group.ParentID,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(toCheckGroupResponse(group, 0, groups.Aggregate(group, nil)))
+	}
+}
+
+// GetCheckGroup returns a group with its status, direct child groups and direct member checks
+func GetCheckGroup(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		group, err := findCheckGroup(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		// Load the whole subtree once and aggregate in memory
+		ids, err := groups.DescendantIDs(db, group.OrgID, group.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch check group tree",
+			})
+		}
+		var subtree []models.CheckGroup
+		if err := db.Where("id IN ?", ids).Find(&subtree).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch check groups",
+			})
+		}
+		var checks []models.Check
+		if err := db.Where("org_id = ? AND group_id IN ?", group.OrgID, ids).Order("name ASC").Find(&checks).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch checks",
+			})
+		}
+		summaries := groups.AggregateAll(subtree, checks)
+
+		directCounts := make(map[uint]int)
+		directChecks := []models.Check{}
+		for _, check := range checks {
+			directCounts[*check.GroupID]++
+			if *check.GroupID == group.ID {
+				directChecks = append(directChecks, check)
+			}
+		}
+
+		children := []CheckGroupResponse{}
+		for _, g := range subtree {
+			if g.ParentID != nil && *g.ParentID == group.ID {
+				children = append(children, toCheckGroupResponse(g, directCounts[g.ID], summaries[g.ID]))
+			}
+		}
+
+		return c.JSON(CheckGroupDetailResponse{
+			CheckGroupResponse: toCheckGroupResponse(*group, directCounts[group.ID], summaries[group.ID]),
+			Children:           children,
+			Checks:             directChecks,
+		})
+	}
+}
+
+// GetCheckGroupStatus returns the aggregate status for a group including nested groups
+func GetCheckGroupStatus(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		group, err := findCheckGroup(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		summary, err := groups.ComputeStatus(db, *group)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to compute group status",
+			})
+		}
+
+		return c.JSON(summary)
+	}
+}
+
+// UpdateCheckGroup updates a group's name, parent or aggregation settings
+func UpdateCheckGroup(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		group, err := findCheckGroup(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req CheckGroupRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if err := applyCheckGroupRequest(db, group, req); err != nil {
+			return respondError(c, err)
+		}
+
+		if err := db.Save(group).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update check group",
+			})
+		}
+
+		logAuditEvent(db, group.OrgID, &userID, models.AuditActionCheckGroupUpdated, "check_group", &group.ID, models.JSONMap{
+			"name":      group.Name,
+			"parent_id": group.ParentID,
+		}, c.IP(), c.Get("User-Agent"))
+
+		summary, err := groups.ComputeStatus(db, *group)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to compute group status",
+			})
+		}
+		var checkCount int64
+		db.Model(&models.Check{}).Where("group_id = ?", group.ID).Count(&checkCount)
+
+		return c.JSON(toCheckGroupResponse(*group, int(checkCount), summary))
+	}
+}
+
+// DeleteCheckGroup deletes a group. Its checks and child groups move up to the deleted group's parent.
+func DeleteCheckGroup(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		group, err := findCheckGroup(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.CheckGroup{}).
+				Where("parent_id = ?", group.ID).
+				Update("parent_id", group.ParentID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Check{}).
+				Where("group_id = ?", group.ID).
+				Update("group_id", group.ParentID).Error; err != nil {
+				return err
+			}
+			// Maintenance windows targeting this group no longer have anything to cover
+			if err := tx.Where("group_id = ?", group.ID).Delete(&models.MaintenanceWindow{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(group).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete check group",
+			})
+		}
+
+		logAuditEvent(db, group.OrgID, &userID, models.AuditActionCheckGroupDeleted, "check_group", &group.ID, models.JSONMap{
+			"name": group.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "check group deleted successfully",
+		})
+	}
+}
+
+// BulkCheckGroupAction pauses, resumes, deletes or moves every check in a group
+func BulkCheckGroupAction(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		group, err := findCheckGroup(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req BulkCheckActionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		groupIDs := []uint{group.ID}
+		if req.Recursive == nil || *req.Recursive {
+			groupIDs, err = groups.DescendantIDs(db, group.OrgID, group.ID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "failed to fetch check group tree",
+				})
+			}
+		}
+
+		// Resolve the target of a move before touching anything
+		var targetGroupID *uint
+		switch req.Action {
+		case "pause", "resume", "delete":
+		case "move":
+			if req.TargetGroupID == nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "target_group_id is required for move",
+				})
+			}
+			targetGroupID, err = validateCheckGroupID(db, group.OrgID, req.TargetGroupID)
+			if err != nil {
+				return respondError(c, err)
+			}
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "action must be one of: pause, resume, delete, move",
+			})
+		}
+
+		var checks []models.Check
+		if err := db.Where("org_id = ? AND group_id IN ?", group.OrgID, groupIDs).Find(&checks).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch checks",
+			})
+		}
+
+		// Apply per check so every change gets its own revision
+		affected := 0
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for i := range checks {
+				check, err := lockCheck(tx, checks[i].ID)
+				if err == gorm.ErrRecordNotFound {
+					continue // Deleted since the checks were listed
+				} else if err != nil {
+					return err
+				}
+				before := check.Config()
+				affected++
+				if req.Action == "delete" {
+					if err := tx.Delete(check).Error; err != nil {
+						return err
+					}
+					if _, err := recordCheckRevision(tx, *check, &before, models.CheckRevisionDeleted, &userID, nil); err != nil {
+						return err
+					}
+					continue
+				}
+				switch req.Action {
+				case "pause":
+					check.IsActive = false
+				case "resume":
+					check.IsActive = true
+				case "move":
+					check.GroupID = targetGroupID
+				}
+				if _, err := saveCheckWithRevision(tx, check, before, &userID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to apply bulk action",
+			})
+		}
+
+		if req.Action == "delete" {
+			billing.SyncResourceCounts(db, group.OrgID)
+		}
+
+		logAuditEvent(db, group.OrgID, &userID, models.AuditActionCheckBulk, "check_group", &group.ID, models.JSONMap{
+			"action":   req.Action,
+			"affected": affected,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"action":   req.Action,
+			"affected": affected,
+		})
+	}
+}
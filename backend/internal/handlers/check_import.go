@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/discovery"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// CheckImportPreviewRequest describes the document to discover checks from.
+// Send either content (the uploaded document) or url (to fetch it). Multipart
+// uploads with a "file" field and the same names as form fields are also accepted.
+type CheckImportPreviewRequest struct {
+	Source      string `json:"source" form:"source"` // openapi, sitemap, or empty to auto-detect
+	Content     string `json:"content" form:"content"`
+	URL         string `json:"url" form:"url"`
+	BaseURL     string `json:"base_url" form:"base_url"`
+	ServiceName string `json:"service_name" form:"service_name"`
+}
+
+// CheckImportAcceptRequest is the reviewed subset of proposals to create
+type CheckImportAcceptRequest struct {
+	Checks []CreateCheckRequest `json:"checks"`
+}
+
+// CheckImportFailure explains why a proposal was not created
+type CheckImportFailure struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// PreviewCheckImport parses an OpenAPI 3 document or sitemap and proposes GET checks for review.
+// Nothing is created until the proposals are sent to AcceptCheckImport.
+func PreviewCheckImport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var req CheckImportPreviewRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		content := []byte(req.Content)
+		if file, err := c.FormFile("file"); err == nil {
+			if file.Size > discovery.MaxDocumentBytes {
+				return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+					"error": "uploaded document is too large",
+				})
+			}
+			f, err := file.Open()
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "failed to read uploaded file",
+				})
+			}
+			defer f.Close()
+			if content, err = io.ReadAll(f); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "failed to read uploaded file",
+				})
+			}
+		}
+
+		source := strings.ToLower(strings.TrimSpace(req.Source))
+		if source != "" && source != discovery.SourceOpenAPI && source != discovery.SourceSitemap {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "source must be openapi or sitemap",
+			})
+		}
+
+		proposals, err := discovery.Discover(c.Context(), discovery.Input{
+			Source:      source,
+			Content:     content,
+			URL:         strings.TrimSpace(req.URL),
+			BaseURL:     strings.TrimSpace(req.BaseURL),
+			ServiceName: strings.TrimSpace(req.ServiceName),
+		})
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		// Flag proposals that duplicate an existing check so reviewers can skip them
+		var existingURLs []string
+		if err := db.Model(&models.Check{}).Where("org_id = ?", orgID).Pluck("url", &existingURLs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch checks",
+			})
+		}
+		existing := make(map[string]bool, len(existingURLs))
+		for _, u := range existingURLs {
+			existing[u] = true
+		}
+		for i := range proposals {
+			proposals[i].Exists = existing[proposals[i].URL]
+		}
+
+		return c.JSON(fiber.Map{
+			"proposals": proposals,
+			"total":     len(proposals),
+		})
+	}
+}
+
+// AcceptCheckImport creates the reviewed proposals through the same path as CreateCheck.
+// Creation stops at the plan's check limit; the remaining proposals are reported as failed.
+func AcceptCheckImport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req CheckImportAcceptRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if len(req.Checks) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "checks is required",
+			})
+		}
+		if len(req.Checks) > discovery.MaxProposals {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "too many checks in a single import",
+			})
+		}
+
+		created := []models.Check{}
+		failed := []CheckImportFailure{}
+		limitReached := false
+		for _, checkReq := range req.Checks {
+			if limitReached {
+				failed = append(failed, CheckImportFailure{Name: checkReq.Name, URL: checkReq.URL, Error: "check limit reached"})
+				continue
+			}
+			check, err := createCheck(c, db, checkReq)
+			if err != nil {
+				message := "failed to create check"
+				switch e := err.(type) {
+				case *checkLimitError:
+					limitReached = true
+					message = e.Message
+				case *fiber.Error:
+					message = e.Message
+				}
+				failed = append(failed, CheckImportFailure{Name: checkReq.Name, URL: checkReq.URL, Error: message})
+				continue
+			}
+			created = append(created, *check)
+		}
+
+		status := fiber.StatusOK
+		if len(created) > 0 {
+			status = fiber.StatusCreated
+		}
+		response := fiber.Map{
+			"created":       created,
+			"failed":        failed,
+			"limit_reached": limitReached,
+		}
+		if limitReached {
+			response["upgrade_url"] = "/settings?tab=billing"
+		}
+		return c.Status(status).JSON(response)
+	}
+}
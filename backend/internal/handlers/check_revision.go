@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CheckRevisionResponse is the DTO for check revision API responses
+type CheckRevisionResponse struct {
+	ID           uint                       `json:"id"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	Version      int                        `json:"version"`
+	Action       models.CheckRevisionAction `json:"action"`
+	AuthorEmail  string                     `json:"author_email,omitempty"`
+	Config       models.JSONMap             `json:"config"`
+	Changes      models.JSONMap             `json:"changes,omitempty"`
+	RestoredFrom *int                       `json:"restored_from,omitempty"`
+}
+
+// toCheckRevisionResponse converts a model to DTO
+func toCheckRevisionResponse(rev models.CheckRevision) CheckRevisionResponse {
+	response := CheckRevisionResponse{
+		ID:           rev.ID,
+		CreatedAt:    rev.CreatedAt,
+		Version:      rev.Version,
+		Action:       rev.Action,
+		Config:       rev.Config,
+		Changes:      rev.Changes,
+		RestoredFrom: rev.RestoredFrom,
+	}
+	if rev.Author != nil {
+		response.AuthorEmail = rev.Author.Email
+	}
+	return response
+}
+
+// lockCheck reloads the check with its row locked until the transaction ends. Changes
+// are applied to this fresh copy so concurrent edits can't overwrite each other or
+// interleave their diffs and revision versions.
+func lockCheck(tx *gorm.DB, checkID uint) (*models.Check, error) {
+	var check models.Check
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&check, checkID).Error; err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+// saveCheckConfig writes only the check's configuration columns; runtime state such as
+// LastStatus, IsFlapping or AnomalyStreak belongs to the check runner
+func saveCheckConfig(tx *gorm.DB, check *models.Check) error {
+	return tx.Model(check).Select(models.CheckConfigFields).Updates(check).Error
+}
+
+// recordCheckRevision appends an immutable revision for the check's current configuration.
+// before is the configuration prior to the change (nil for creation). The caller must hold
+// the check's row lock (see lockCheck) or have just created it in tx. Checks created
+// before revision history existed get a baseline revision of `before` so the first diff isn't lost.
+func recordCheckRevision(tx *gorm.DB, check models.Check, before *models.CheckConfig, action models.CheckRevisionAction, authorID *uint, restoredFrom *int) (models.JSONMap, error) {
+	var latest int
+	if err := tx.Model(&models.CheckRevision{}).
+		Where("check_id = ?", check.ID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error; err != nil {
+		return nil, err
+	}
+
+	if latest == 0 && before != nil {
+		baseline := models.CheckRevision{
+			OrgID:   check.OrgID,
+			CheckID: check.ID,
+			Version: 1,
+			Action:  models.CheckRevisionCreated,
+			Config:  before.ToMap(),
+		}
+		if err := tx.Create(&baseline).Error; err != nil {
+			return nil, err
+		}
+		latest = 1
+	}
+
+	after := check.Config()
+	var changes models.JSONMap
+	if before != nil {
+		changes = models.DiffCheckConfig(*before, after)
+	}
+
+	revision := models.CheckRevision{
+		OrgID:        check.OrgID,
+		CheckID:      check.ID,
+		Version:      latest + 1,
+		Action:       action,
+		AuthorID:     authorID,
+		Config:       after.ToMap(),
+		Changes:      changes,
+		RestoredFrom: restoredFrom,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// saveCheckWithRevision saves a check loaded with lockCheck and records an "updated"
+// revision if its configuration changed from before
+func saveCheckWithRevision(tx *gorm.DB, check *models.Check, before models.CheckConfig, authorID *uint) (models.JSONMap, error) {
+	if err := saveCheckConfig(tx, check); err != nil {
+		return nil, err
+	}
+	if len(models.DiffCheckConfig(before, check.Config())) == 0 {
+		return nil, nil
+	}
+	return recordCheckRevision(tx, *check, &before, models.CheckRevisionUpdated, authorID, nil)
+}
+
+// findOrgCheck loads a check by route param and verifies org ownership
+func findOrgCheck(c *fiber.Ctx, db *gorm.DB) (*models.Check, error) {
+	orgID := c.Locals("orgID").(uint)
+	checkID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid check ID")
+	}
+	var check models.Check
+	if err := db.Where("id = ? AND org_id = ?", checkID, orgID).First(&check).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "check not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch check")
+	}
+	return &check, nil
+}
+
+// ListCheckRevisions returns the revision history of a check, newest first
+func ListCheckRevisions(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		check, err := findOrgCheck(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var revisions []models.CheckRevision
+		if err := db.Preload("Author").
+			Where("check_id = ?", check.ID).
+			Order("version DESC").
+			Find(&revisions).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch revisions",
+			})
+		}
+
+		responses := make([]CheckRevisionResponse, len(revisions))
+		for i, rev := range revisions {
+			responses[i] = toCheckRevisionResponse(rev)
+		}
+
+		return c.JSON(fiber.Map{
+			"revisions": responses,
+		})
+	}
+}
+
+// GetCheckRevision returns a single revision by version number
+func GetCheckRevision(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		check, err := findOrgCheck(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		version, err := strconv.Atoi(c.Params("version"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid revision version",
+			})
+		}
+
+		var revision models.CheckRevision
+		if err := db.Preload("Author").
+			Where("check_id = ? AND version = ?", check.ID, version).
+			First(&revision).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "revision not found",
+			})
+		}
+
+		return c.JSON(toCheckRevisionResponse(revision))
+	}
+}
+
+// RestoreCheckRevision rolls a check's configuration back to a prior revision.
+// The restore itself is recorded as a new revision so history is never rewritten.
+func RestoreCheckRevision(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		check, err := findOrgCheck(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		version, err := strconv.Atoi(c.Params("version"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid revision version",
+			})
+		}
+
+		var revision models.CheckRevision
+		if err := db.Where("check_id = ? AND version = ?", check.ID, version).First(&revision).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "revision not found",
+			})
+		}
+
+		cfg, err := models.CheckConfigFromMap(revision.Config)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to decode revision",
+			})
+		}
+
+		// The group may have been deleted since this revision was taken
+		if cfg.GroupID != nil {
+			var count int64
+			db.Model(&models.CheckGroup{}).Where("id = ? AND org_id = ?", *cfg.GroupID, check.OrgID).Count(&count)
+			if count == 0 {
+				cfg.GroupID = nil
+			}
+		}
+
+		// Likewise the escalation policy
+		if cfg.EscalationPolicyID != nil {
+			var count int64
+			db.Model(&models.EscalationPolicy{}).Where("id = ? AND org_id = ?", *cfg.EscalationPolicyID, check.OrgID).Count(&count)
+			if count == 0 {
+				cfg.EscalationPolicyID = nil
+			}
+		}
+
+		// Plans may have changed since; never restore below the current minimum interval
+		var org models.Organization
+		if err := db.First(&org, check.OrgID).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to load organization",
+			})
+		}
+		planConfig := models.GetPlanConfig(org.Plan)
+		if cfg.IntervalSeconds < planConfig.CheckIntervalMinSeconds {
+			cfg.IntervalSeconds = planConfig.CheckIntervalMinSeconds
+		}
+		if cfg.CheckType == models.CheckTypeCrawl && cfg.IntervalSeconds < models.MinCrawlIntervalSeconds {
+			cfg.IntervalSeconds = models.MinCrawlIntervalSeconds
+		}
+
+		var before models.CheckConfig
+		var changes models.JSONMap
+		err = db.Transaction(func(tx *gorm.DB) error {
+			locked, err := lockCheck(tx, check.ID)
+			if err != nil {
+				return err
+			}
+			before = locked.Config()
+			locked.ApplyConfig(cfg)
+			if err := saveCheckConfig(tx, locked); err != nil {
+				return err
+			}
+			if changes, err = recordCheckRevision(tx, *locked, &before, models.CheckRevisionRestored, &userID, &revision.Version); err != nil {
+				return err
+			}
+			check = locked
+			return nil
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to restore revision",
+			})
+		}
+
+		logAuditEvent(db, check.OrgID, &userID, models.AuditActionCheckRestored, "check", &check.ID, models.JSONMap{
+			"restored_version": revision.Version,
+			"before":           before.ToMap(),
+			"after":            check.Config().ToMap(),
+			"changes":          changes,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(check)
+	}
+}
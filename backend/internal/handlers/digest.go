@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/digest"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+type DigestSubscriptionRequest struct {
+	ChannelID *uint                   `json:"channel_id,omitempty"`
+	Frequency *models.DigestFrequency `json:"frequency,omitempty"`
+	Weekday   *int                    `json:"weekday,omitempty"` // 0 = Sunday; weekly digests only
+	Hour      *int                    `json:"hour,omitempty"`    // Local hour in the org's timezone
+	Enabled   *bool                   `json:"enabled,omitempty"`
+}
+
+// findDigestSubscription loads a digest subscription by route param and verifies org ownership
+func findDigestSubscription(c *fiber.Ctx, db *gorm.DB) (*models.DigestSubscription, error) {
+	orgID := c.Locals("orgID").(uint)
+	subID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid digest subscription ID")
+	}
+	var sub models.DigestSubscription
+	if err := db.Where("id = ? AND org_id = ?", subID, orgID).First(&sub).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "digest subscription not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch digest subscription")
+	}
+	return &sub, nil
+}
+
+// parseDigestFrequency validates a frequency, defaulting to weekly
+func parseDigestFrequency(value string) (models.DigestFrequency, error) {
+	switch models.DigestFrequency(value) {
+	case "":
+		return models.DigestWeekly, nil
+	case models.DigestDaily, models.DigestWeekly:
+		return models.DigestFrequency(value), nil
+	}
+	return "", fiber.NewError(fiber.StatusBadRequest, "frequency must be daily or weekly")
+}
+
+// applyDigestSubscriptionRequest validates the request, copies set fields onto the
+// subscription and reschedules it in the org's timezone
+func applyDigestSubscriptionRequest(db *gorm.DB, org *models.Organization, sub *models.DigestSubscription, req DigestSubscriptionRequest) error {
+	if req.ChannelID != nil {
+		var channel models.NotificationChannel
+		if err := db.Where("id = ? AND org_id = ?", *req.ChannelID, org.ID).First(&channel).Error; err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "notification channel not found")
+		}
+		if channel.Type != models.ChannelTypeEmail {
+			return fiber.NewError(fiber.StatusBadRequest, "digests can only be sent to email channels")
+		}
+		sub.ChannelID = channel.ID
+	}
+	if req.Frequency != nil {
+		frequency, err := parseDigestFrequency(string(*req.Frequency))
+		if err != nil {
+			return err
+		}
+		sub.Frequency = frequency
+	}
+	if req.Weekday != nil {
+		if *req.Weekday < 0 || *req.Weekday > 6 {
+			return fiber.NewError(fiber.StatusBadRequest, "weekday must be between 0 (Sunday) and 6")
+		}
+		sub.Weekday = *req.Weekday
+	}
+	if req.Hour != nil {
+		if *req.Hour < 0 || *req.Hour > 23 {
+			return fiber.NewError(fiber.StatusBadRequest, "hour must be between 0 and 23")
+		}
+		sub.Hour = *req.Hour
+	}
+	if req.Enabled != nil {
+		sub.Enabled = *req.Enabled
+	}
+	digest.ScheduleNext(sub, *org, time.Now())
+	return nil
+}
+
+// ListDigestSubscriptions returns the org's digest subscriptions
+func ListDigestSubscriptions(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var subs []models.DigestSubscription
+		if err := db.Where("org_id = ?", orgID).Order("id ASC").Find(&subs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch digest subscriptions",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"subscriptions": subs,
+		})
+	}
+}
+
+// CreateDigestSubscription schedules a daily or weekly digest to an email channel
+func CreateDigestSubscription(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req DigestSubscriptionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.ChannelID == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "channel_id is required",
+			})
+		}
+
+		org, err := loadOrg(db, orgID)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		sub := models.DigestSubscription{
+			OrgID:     orgID,
+			Frequency: models.DigestWeekly,
+			Weekday:   int(time.Monday),
+			Hour:      9,
+			Enabled:   true,
+		}
+		if err := applyDigestSubscriptionRequest(db, org, &sub, req); err != nil {
+			return respondError(c, err)
+		}
+		if err := db.Create(&sub).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create digest subscription",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionDigestSubscriptionCreated, "digest_subscription", &sub.ID, models.JSONMap{
+			"channel_id": sub.ChannelID,
+			"frequency":  sub.Frequency,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(sub)
+	}
+}
+
+// UpdateDigestSubscription changes a digest's channel, schedule or enabled flag
+func UpdateDigestSubscription(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		sub, err := findDigestSubscription(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req DigestSubscriptionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		org, err := loadOrg(db, sub.OrgID)
+		if err != nil {
+			return respondError(c, err)
+		}
+		if err := applyDigestSubscriptionRequest(db, org, sub, req); err != nil {
+			return respondError(c, err)
+		}
+		if err := db.Save(sub).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update digest subscription",
+			})
+		}
+
+		logAuditEvent(db, sub.OrgID, &userID, models.AuditActionDigestSubscriptionUpdated, "digest_subscription", &sub.ID, models.JSONMap{
+			"channel_id": sub.ChannelID,
+			"frequency":  sub.Frequency,
+			"enabled":    sub.Enabled,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(sub)
+	}
+}
+
+// DeleteDigestSubscription stops a digest
+func DeleteDigestSubscription(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		sub, err := findDigestSubscription(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		if err := db.Delete(sub).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete digest subscription",
+			})
+		}
+
+		logAuditEvent(db, sub.OrgID, &userID, models.AuditActionDigestSubscriptionDeleted, "digest_subscription", &sub.ID, models.JSONMap{
+			"channel_id": sub.ChannelID,
+			"frequency":  sub.Frequency,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "digest subscription deleted successfully",
+		})
+	}
+}
+
+// SendDigestNow delivers a digest immediately without changing its schedule
+func SendDigestNow(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sub, err := findDigestSubscription(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		if err := digest.Send(db, *sub); err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		now := time.Now()
+		db.Model(sub).Updates(map[string]interface{}{"last_sent_at": now, "last_error": ""})
+
+		return c.JSON(fiber.Map{
+			"message": "digest sent",
+		})
+	}
+}
+
+// PreviewDigest compiles the org's digest for the period ending now and renders it.
+// Pass ?frequency=daily|weekly (defaults to weekly).
+func PreviewDigest(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		frequency, err := parseDigestFrequency(c.Query("frequency"))
+		if err != nil {
+			return respondError(c, err)
+		}
+		org, err := loadOrg(db, orgID)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		report, err := digest.Build(db, *org, frequency, time.Now())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to build digest",
+			})
+		}
+		email, err := digest.Render(report)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"report":  report,
+			"subject": email.Subject,
+			"text":    email.Text,
+			"html":    email.HTML,
+		})
+	}
+}
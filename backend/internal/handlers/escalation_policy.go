@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/oFuterman/light-house/internal/incidents"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/oncall"
+	"gorm.io/gorm"
+)
+
+// MaxEscalationLevels caps how many levels a policy can have
+const MaxEscalationLevels = 10
+
+type EscalationLevelRequest struct {
+	EscalateAfterMinutes int    `json:"escalate_after_minutes"`
+	UserIDs              []uint `json:"user_ids"`
+	ScheduleIDs          []uint `json:"schedule_ids"`
+}
+
+type EscalationPolicyRequest struct {
+	Name        *string                   `json:"name,omitempty"`
+	Description *string                   `json:"description,omitempty"`
+	Levels      *[]EscalationLevelRequest `json:"levels,omitempty"` // Replaces every level, in order
+}
+
+// validateEscalationPolicyID checks the policy belongs to the org. nil or 0 means no policy.
+func validateEscalationPolicyID(db *gorm.DB, orgID uint, policyID *uint) (*uint, error) {
+	if policyID == nil || *policyID == 0 {
+		return nil, nil
+	}
+	var count int64
+	if err := db.Model(&models.EscalationPolicy{}).Where("id = ? AND org_id = ?", *policyID, orgID).Count(&count).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch escalation policy")
+	}
+	if count == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "escalation policy not found")
+	}
+	return policyID, nil
+}
+
+// findEscalationPolicy loads a policy with its levels by route param and verifies org ownership
+func findEscalationPolicy(c *fiber.Ctx, db *gorm.DB) (*models.EscalationPolicy, error) {
+	orgID := c.Locals("orgID").(uint)
+	policyID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid escalation policy ID")
+	}
+	var policy models.EscalationPolicy
+	if err := db.Preload("Levels", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("level ASC")
+	}).Where("id = ? AND org_id = ?", policyID, orgID).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "escalation policy not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch escalation policy")
+	}
+	return &policy, nil
+}
+
+// buildEscalationLevels validates level requests and numbers them from 1
+func buildEscalationLevels(db *gorm.DB, orgID uint, reqs []EscalationLevelRequest) ([]models.EscalationLevel, error) {
+	if len(reqs) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "at least one level is required")
+	}
+	if len(reqs) > MaxEscalationLevels {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "too many escalation levels (max 10)")
+	}
+
+	levels := make([]models.EscalationLevel, 0, len(reqs))
+	for i, req := range reqs {
+		if req.EscalateAfterMinutes < 1 || req.EscalateAfterMinutes > 1440 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "escalate_after_minutes must be between 1 and 1440")
+		}
+		if len(req.UserIDs) == 0 && len(req.ScheduleIDs) == 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "each level needs at least one user or schedule")
+		}
+		userIDs, err := validateOrgUserIDs(db, orgID, req.UserIDs)
+		if err != nil {
+			return nil, err
+		}
+		scheduleIDs := pq.Int64Array{}
+		for _, id := range req.ScheduleIDs {
+			var count int64
+			if err := db.Model(&models.OnCallSchedule{}).Where("id = ? AND org_id = ?", id, orgID).Count(&count).Error; err != nil {
+				return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch schedule")
+			}
+			if count == 0 {
+				return nil, fiber.NewError(fiber.StatusBadRequest, "schedule not found")
+			}
+			scheduleIDs = append(scheduleIDs, int64(id))
+		}
+		levels = append(levels, models.EscalationLevel{
+			Level:                i + 1,
+			EscalateAfterMinutes: req.EscalateAfterMinutes,
+			UserIDs:              userIDs,
+			ScheduleIDs:          scheduleIDs,
+		})
+	}
+	return levels, nil
+}
+
+// ListEscalationPolicies returns the org's escalation policies with their levels
+func ListEscalationPolicies(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var policies []models.EscalationPolicy
+		if err := db.Preload("Levels", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("level ASC")
+		}).Where("org_id = ?", orgID).Order("name ASC").Find(&policies).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch escalation policies",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"escalation_policies": policies,
+		})
+	}
+}
+
+// GetEscalationPolicy returns a single policy with its levels
+func GetEscalationPolicy(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		policy, err := findEscalationPolicy(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(policy)
+	}
+}
+
+// CreateEscalationPolicy creates a policy and its levels
+func CreateEscalationPolicy(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req EscalationPolicyRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "name is required",
+			})
+		}
+		if req.Levels == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "at least one level is required",
+			})
+		}
+
+		levels, err := buildEscalationLevels(db, orgID, *req.Levels)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		policy := models.EscalationPolicy{
+			OrgID:  orgID,
+			Name:   strings.TrimSpace(*req.Name),
+			Levels: levels,
+		}
+		if req.Description != nil {
+			policy.Description = strings.TrimSpace(*req.Description)
+		}
+		if err := db.Create(&policy).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create escalation policy",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionEscalationPolicyCreated, "escalation_policy", &policy.ID, models.JSONMap{
+			"name":   policy.Name,
+			"levels": len(policy.Levels),
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(policy)
+	}
+}
+
+// UpdateEscalationPolicy renames a policy and/or replaces its levels.
+// Escalations already in progress pick up the new levels at their next step.
+func UpdateEscalationPolicy(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		policy, err := findEscalationPolicy(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req EscalationPolicyRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "name cannot be empty",
+				})
+			}
+			policy.Name = name
+		}
+		if req.Description != nil {
+			policy.Description = strings.TrimSpace(*req.Description)
+		}
+
+		var levels []models.EscalationLevel
+		if req.Levels != nil {
+			if levels, err = buildEscalationLevels(db, policy.OrgID, *req.Levels); err != nil {
+				return respondError(c, err)
+			}
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Omit("Levels").Save(policy).Error; err != nil {
+				return err
+			}
+			if req.Levels == nil {
+				return nil
+			}
+			if err := tx.Where("policy_id = ?", policy.ID).Delete(&models.EscalationLevel{}).Error; err != nil {
+				return err
+			}
+			for i := range levels {
+				levels[i].PolicyID = policy.ID
+			}
+			if err := tx.Create(&levels).Error; err != nil {
+				return err
+			}
+			policy.Levels = levels
+			return nil
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update escalation policy",
+			})
+		}
+
+		logAuditEvent(db, policy.OrgID, &userID, models.AuditActionEscalationPolicyUpdated, "escalation_policy", &policy.ID, models.JSONMap{
+			"name":   policy.Name,
+			"levels": len(policy.Levels),
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(policy)
+	}
+}
+
+// DeleteEscalationPolicy deletes a policy, detaches it from checks and stops its escalations
+func DeleteEscalationPolicy(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		policy, err := findEscalationPolicy(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Check{}).
+				Where("org_id = ? AND escalation_policy_id = ?", policy.OrgID, policy.ID).
+				Update("escalation_policy_id", nil).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.AlertEscalation{}).
+				Where("policy_id = ? AND status = ?", policy.ID, models.EscalationActive).
+				Updates(map[string]interface{}{
+					"status":             models.EscalationExhausted,
+					"next_escalation_at": nil,
+				}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("policy_id = ?", policy.ID).Delete(&models.EscalationLevel{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(policy).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete escalation policy",
+			})
+		}
+
+		logAuditEvent(db, policy.OrgID, &userID, models.AuditActionEscalationPolicyDeleted, "escalation_policy", &policy.ID, models.JSONMap{
+			"name": policy.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "escalation policy deleted successfully",
+		})
+	}
+}
+
+// AcknowledgeAlert marks an alert acknowledged, which stops its escalation.
+// The alert's incident is acknowledged along with it.
+func AcknowledgeAlert(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		alert, err := findOrgAlert(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		if alert.AcknowledgedAt != nil {
+			return c.JSON(alert)
+		}
+
+		if err := oncall.Acknowledge(db, alert, userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to acknowledge alert",
+			})
+		}
+		if alert.IncidentID != nil {
+			var incident models.Incident
+			if err := db.First(&incident, *alert.IncidentID).Error; err == nil {
+				if err := incidents.Acknowledge(db, &incident, userID); err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"error": "failed to acknowledge incident",
+					})
+				}
+			}
+		}
+
+		logAuditEvent(db, alert.OrgID, &userID, models.AuditActionAlertAcknowledged, "alert", &alert.ID, models.JSONMap{
+			"check_id":   alert.CheckID,
+			"alert_type": alert.AlertType,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(alert)
+	}
+}
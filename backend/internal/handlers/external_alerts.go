@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/externalalerts"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxExternalAlertsPerDelivery bounds the alerts accepted in one webhook delivery
+const maxExternalAlertsPerDelivery = 1000
+
+// receiveExternalAlerts records a delivery's alerts for the API key's org. Errors
+// return 500 so Alertmanager and Grafana retry the delivery.
+func receiveExternalAlerts(c *fiber.Ctx, db *gorm.DB, source models.ExternalAlertSource, alerts []externalalerts.AlertmanagerAlert) error {
+	orgID := c.Locals("orgID").(uint)
+	if len(alerts) > maxExternalAlertsPerDelivery {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": "too many alerts in one delivery",
+		})
+	}
+
+	result, err := externalalerts.ReceiveAll(db, orgID, source, alerts)
+	if err != nil {
+		log.Printf("Failed to receive %s alerts for org %d: %v", source, orgID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to record alerts",
+		})
+	}
+	return c.JSON(result)
+}
+
+// ReceiveAlertmanagerWebhook accepts Prometheus Alertmanager webhook notifications.
+// Configure a webhook receiver with the URL of this endpoint and an API key with
+// the alerts:write scope as its bearer credentials.
+func ReceiveAlertmanagerWebhook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var payload externalalerts.AlertmanagerPayload
+		if err := c.BodyParser(&payload); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		return receiveExternalAlerts(c, db, models.ExternalSourceAlertmanager, payload.Alerts)
+	}
+}
+
+// ReceiveGrafanaWebhook accepts Grafana webhook contact point notifications from
+// unified or legacy alerting, authenticated like ReceiveAlertmanagerWebhook
+func ReceiveGrafanaWebhook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var payload externalalerts.GrafanaPayload
+		if err := c.BodyParser(&payload); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		return receiveExternalAlerts(c, db, models.ExternalSourceGrafana, payload.Alerts())
+	}
+}
@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/incidents"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// IncidentResponse is the DTO for incident API responses
+type IncidentResponse struct {
+	models.Incident
+	CheckName     string `json:"check_name,omitempty"` // alertname for external alert incidents
+	AssigneeEmail string `json:"assignee_email,omitempty"`
+}
+
+// IncidentTimelineEntry is a timeline event with its alert and author resolved
+type IncidentTimelineEntry struct {
+	models.IncidentEvent
+	Alert     *AlertResponse `json:"alert,omitempty"`
+	UserEmail string         `json:"user_email,omitempty"`
+}
+
+type AssignIncidentRequest struct {
+	AssigneeID *uint `json:"assignee_id"` // null or 0 unassigns
+}
+
+type AddIncidentNoteRequest struct {
+	Body string `json:"body"`
+}
+
+// userEmails maps user IDs to emails for display
+func userEmails(db *gorm.DB, ids []uint) map[uint]string {
+	emails := map[uint]string{}
+	if len(ids) == 0 {
+		return emails
+	}
+	var users []models.User
+	db.Select("id", "email").Where("id IN ?", ids).Find(&users)
+	for _, u := range users {
+		emails[u.ID] = u.Email
+	}
+	return emails
+}
+
+// toIncidentResponses attaches check (or external alert) names and assignee emails
+func toIncidentResponses(db *gorm.DB, list []models.Incident) []IncidentResponse {
+	checkIDs := make([]uint, 0, len(list))
+	externalIDs := []uint{}
+	assigneeIDs := []uint{}
+	for _, incident := range list {
+		if incident.CheckID != nil {
+			checkIDs = append(checkIDs, *incident.CheckID)
+		}
+		if incident.ExternalAlertID != nil {
+			externalIDs = append(externalIDs, *incident.ExternalAlertID)
+		}
+		if incident.AssigneeID != nil {
+			assigneeIDs = append(assigneeIDs, *incident.AssigneeID)
+		}
+	}
+	checkNames := map[uint]string{}
+	if len(checkIDs) > 0 {
+		var checks []models.Check
+		db.Unscoped().Select("id", "name").Where("id IN ?", checkIDs).Find(&checks)
+		for _, check := range checks {
+			checkNames[check.ID] = check.Name
+		}
+	}
+	externalNames := map[uint]string{}
+	if len(externalIDs) > 0 {
+		var externals []models.ExternalAlert
+		db.Select("id", "name").Where("id IN ?", externalIDs).Find(&externals)
+		for _, external := range externals {
+			externalNames[external.ID] = external.Name
+		}
+	}
+	emails := userEmails(db, assigneeIDs)
+
+	responses := make([]IncidentResponse, len(list))
+	for i, incident := range list {
+		responses[i] = IncidentResponse{Incident: incident}
+		if incident.CheckID != nil {
+			responses[i].CheckName = checkNames[*incident.CheckID]
+		} else if incident.ExternalAlertID != nil {
+			responses[i].CheckName = externalNames[*incident.ExternalAlertID]
+		}
+		if incident.AssigneeID != nil {
+			responses[i].AssigneeEmail = emails[*incident.AssigneeID]
+		}
+	}
+	return responses
+}
+
+// findOrgIncident loads an incident by route param and verifies org ownership
+func findOrgIncident(c *fiber.Ctx, db *gorm.DB) (*models.Incident, error) {
+	orgID := c.Locals("orgID").(uint)
+	incidentID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid incident ID")
+	}
+	var incident models.Incident
+	if err := db.Where("id = ? AND org_id = ?", incidentID, orgID).First(&incident).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "incident not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch incident")
+	}
+	return &incident, nil
+}
+
+// ListIncidents returns the org's incidents, newest first.
+// Supports ?status=, ?check_id=, ?limit= and ?window_hours= (like the alerts list).
+func ListIncidents(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		limit, cutoff := parseAlertQueryParams(c)
+
+		query := db.Where("org_id = ? AND started_at >= ?", orgID, cutoff)
+		switch status := models.IncidentStatus(c.Query("status")); status {
+		case "":
+		case "open":
+			query = query.Where("status <> ?", models.IncidentResolved)
+		case models.IncidentTriggered, models.IncidentAcknowledged, models.IncidentResolved:
+			query = query.Where("status = ?", status)
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "status must be open, triggered, acknowledged or resolved",
+			})
+		}
+		if checkParam := c.Query("check_id"); checkParam != "" {
+			checkID, err := strconv.ParseUint(checkParam, 10, 32)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "invalid check ID",
+				})
+			}
+			query = query.Where("check_id = ?", checkID)
+		}
+
+		var list []models.Incident
+		if err := query.Order("started_at DESC").Limit(limit).Find(&list).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch incidents",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"incidents": toIncidentResponses(db, list),
+		})
+	}
+}
+
+// GetIncident returns an incident with its timeline of alerts, notes and status changes
+func GetIncident(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		incident, err := findOrgIncident(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var events []models.IncidentEvent
+		if err := db.Where("incident_id = ?", incident.ID).Order("created_at ASC, id ASC").Find(&events).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch incident timeline",
+			})
+		}
+		var alerts []models.Alert
+		if err := db.Preload("Check").Preload("ExternalAlert").Where("incident_id = ?", incident.ID).Find(&alerts).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch alerts",
+			})
+		}
+		alertsByID := make(map[uint]AlertResponse, len(alerts))
+		for _, alert := range alerts {
+			alertsByID[alert.ID] = toAlertResponse(alert, alertSubjectName(alert))
+		}
+		userIDs := []uint{}
+		for _, event := range events {
+			if event.UserID != nil {
+				userIDs = append(userIDs, *event.UserID)
+			}
+		}
+		emails := userEmails(db, userIDs)
+
+		timeline := make([]IncidentTimelineEntry, len(events))
+		for i, event := range events {
+			timeline[i] = IncidentTimelineEntry{IncidentEvent: event}
+			if event.AlertID != nil {
+				if alert, ok := alertsByID[*event.AlertID]; ok {
+					timeline[i].Alert = &alert
+				}
+			}
+			if event.UserID != nil {
+				timeline[i].UserEmail = emails[*event.UserID]
+			}
+		}
+
+		return c.JSON(fiber.Map{
+			"incident": toIncidentResponses(db, []models.Incident{*incident})[0],
+			"timeline": timeline,
+		})
+	}
+}
+
+// AcknowledgeIncident acknowledges an incident and its alerts, stopping escalation
+func AcknowledgeIncident(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		incident, err := findOrgIncident(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		if incident.Status != models.IncidentTriggered {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "only triggered incidents can be acknowledged",
+			})
+		}
+
+		if err := incidents.Acknowledge(db, incident, userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to acknowledge incident",
+			})
+		}
+
+		logAuditEvent(db, incident.OrgID, &userID, models.AuditActionIncidentAcknowledged, "incident", &incident.ID, models.JSONMap{
+			"check_id": incident.CheckID,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(incident)
+	}
+}
+
+// ResolveIncident closes an incident by hand
+func ResolveIncident(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		incident, err := findOrgIncident(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		if !incident.IsOpen() {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "incident is already resolved",
+			})
+		}
+
+		if err := incidents.Resolve(db, incident, userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to resolve incident",
+			})
+		}
+
+		logAuditEvent(db, incident.OrgID, &userID, models.AuditActionIncidentResolved, "incident", &incident.ID, models.JSONMap{
+			"check_id":         incident.CheckID,
+			"duration_seconds": incident.DurationSeconds,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(incident)
+	}
+}
+
+// AssignIncident sets or clears an incident's assignee
+func AssignIncident(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		incident, err := findOrgIncident(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req AssignIncidentRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		var assigneeID *uint
+		if req.AssigneeID != nil && *req.AssigneeID != 0 {
+			if _, err := validateOrgUserIDs(db, incident.OrgID, []uint{*req.AssigneeID}); err != nil {
+				return respondError(c, err)
+			}
+			assigneeID = req.AssigneeID
+		}
+
+		if err := incidents.Assign(db, incident, assigneeID, userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to assign incident",
+			})
+		}
+
+		logAuditEvent(db, incident.OrgID, &userID, models.AuditActionIncidentAssigned, "incident", &incident.ID, models.JSONMap{
+			"assignee_id": assigneeID,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(toIncidentResponses(db, []models.Incident{*incident})[0])
+	}
+}
+
+// AddIncidentNote appends a note to an incident's timeline
+func AddIncidentNote(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		incident, err := findOrgIncident(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req AddIncidentNoteRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		body := strings.TrimSpace(req.Body)
+		if body == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "body is required",
+			})
+		}
+		if len(body) > 10000 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "note is too long (max 10000 characters)",
+			})
+		}
+
+		event, err := incidents.AddNote(db, incident, userID, body)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to add note",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(event)
+	}
+}
+
+// GetIncidentStats returns incident counts, MTTR and MTTA for the window.
+// Supports ?window_hours= (default 720) and ?check_id=.
+func GetIncidentStats(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		windowHours := 720
+		if windowParam := c.Query("window_hours"); windowParam != "" {
+			if w, err := strconv.Atoi(windowParam); err == nil && w > 0 {
+				windowHours = w
+			}
+		}
+		var checkID *uint
+		if checkParam := c.Query("check_id"); checkParam != "" {
+			id, err := strconv.ParseUint(checkParam, 10, 32)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "invalid check ID",
+				})
+			}
+			uid := uint(id)
+			checkID = &uid
+		}
+
+		stats, err := incidents.ComputeStats(db, orgID, checkID, time.Now().Add(-time.Duration(windowHours)*time.Hour))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to compute incident stats",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"window_hours": windowHours,
+			"stats":        stats,
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"context"
+	"log"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -144,6 +147,7 @@ func CreateInvite(db *gorm.DB) fiber.Handler {
 		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 			"invite":      toInviteResponse(invite),
 			"invite_link": getInviteLink(token),
+			"email_sent":  sendInviteEmail(db, invite),
 		})
 	}
 }
@@ -432,15 +436,42 @@ func ResendInvite(db *gorm.DB) fiber.Handler {
 			})
 		}
 
-		// TODO: Send email notification
-
 		return c.JSON(fiber.Map{
 			"message":     "invite resent successfully",
 			"invite_link": getInviteLink(newToken),
+			"email_sent":  sendInviteEmail(db, invite),
 		})
 	}
 }
 
+// sendInviteEmail emails the invite link. Failures are logged rather than returned
+// since the link is also handed back to the inviter to share directly.
+func sendInviteEmail(db *gorm.DB, invite models.Invite) bool {
+	var org models.Organization
+	if err := db.First(&org, invite.OrgID).Error; err != nil {
+		log.Printf("Failed to load org %d for invite email: %v", invite.OrgID, err)
+		return false
+	}
+	var inviter models.User
+	db.Select("email").First(&inviter, invite.InvitedByID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	err := notifier.SendInviteEmail(ctx, notifier.InviteEmail{
+		To:        invite.Email,
+		OrgName:   org.Name,
+		InvitedBy: inviter.Email,
+		Role:      string(invite.Role),
+		Link:      getInviteLink(invite.Token),
+		ExpiresAt: invite.ExpiresAt,
+	})
+	if err != nil {
+		log.Printf("Failed to send invite email for invite %d: %v", invite.ID, err)
+		return false
+	}
+	return true
+}
+
 // getInviteLink generates the frontend URL for accepting an invite
 func getInviteLink(token string) string {
 	// In production, this should be configurable
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/logalerts"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/search"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LogAlertRuleRequest defines a rule such as "more than 50 logs matching
+// level=error, service_name=api in 5m" (condition above, threshold 50, window 300)
+// or "no logs from a service for 10m" (condition below, threshold 1, window 600)
+type LogAlertRuleRequest struct {
+	Name          *string               `json:"name,omitempty"`
+	Enabled       *bool                 `json:"enabled,omitempty"`
+	Query         *search.SearchRequest `json:"query,omitempty"` // Filters and tags as for log search
+	Condition     *string               `json:"condition,omitempty"`
+	Threshold     *int64                `json:"threshold,omitempty"`
+	WindowSeconds *int                  `json:"window_seconds,omitempty"`
+}
+
+// findLogAlertRule loads a log alert rule by route param and verifies org ownership
+func findLogAlertRule(c *fiber.Ctx, db *gorm.DB) (*models.LogAlertRule, error) {
+	orgID := c.Locals("orgID").(uint)
+	ruleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid log alert rule ID")
+	}
+	var rule models.LogAlertRule
+	if err := db.Where("id = ? AND org_id = ?", ruleID, orgID).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "log alert rule not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch log alert rule")
+	}
+	return &rule, nil
+}
+
+// applyLogAlertRuleRequest validates the request and copies set fields onto the rule
+func applyLogAlertRuleRequest(rule *models.LogAlertRule, req LogAlertRuleRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "name cannot be empty")
+		}
+		rule.Name = name
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Query != nil {
+		query, err := logalerts.Normalize(*req.Query)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid query: "+err.Error())
+		}
+		rule.Query = query
+		rule.ServiceName, rule.Environment = logalerts.SubjectFields(*req.Query)
+	}
+	if req.Condition != nil {
+		condition := models.LogAlertCondition(strings.ToLower(strings.TrimSpace(*req.Condition)))
+		if condition != models.LogAlertAbove && condition != models.LogAlertBelow {
+			return fiber.NewError(fiber.StatusBadRequest, "condition must be above or below")
+		}
+		rule.Condition = condition
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.WindowSeconds != nil {
+		rule.WindowSeconds = *req.WindowSeconds
+	}
+
+	if rule.Threshold < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "threshold cannot be negative")
+	}
+	if rule.Condition == models.LogAlertBelow && rule.Threshold < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "threshold must be at least 1 for the below condition")
+	}
+	if rule.WindowSeconds < models.MinLogAlertWindowSeconds || rule.WindowSeconds > models.MaxLogAlertWindowSeconds {
+		return fiber.NewError(fiber.StatusBadRequest, "window_seconds must be between 60 and 86400")
+	}
+	return nil
+}
+
+// ListLogAlertRules returns the org's log alert rules
+func ListLogAlertRules(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var rules []models.LogAlertRule
+		if err := db.Where("org_id = ?", orgID).Order("name ASC, id ASC").Find(&rules).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch log alert rules",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"rules": rules,
+		})
+	}
+}
+
+// CreateLogAlertRule creates a log alert rule. It is first evaluated within a minute.
+func CreateLogAlertRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req LogAlertRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.Name == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "name is required",
+			})
+		}
+		if req.Query == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "query is required",
+			})
+		}
+		if req.Threshold == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "threshold is required",
+			})
+		}
+
+		rule := models.LogAlertRule{
+			OrgID:            orgID,
+			Enabled:          true,
+			Condition:        models.LogAlertAbove,
+			WindowSeconds:    models.DefaultLogAlertWindowSeconds,
+			CreatedByID:      &userID,
+			NextEvaluationAt: time.Now(),
+		}
+		if err := applyLogAlertRuleRequest(&rule, req); err != nil {
+			return respondError(c, err)
+		}
+
+		if err := db.Create(&rule).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create log alert rule",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionLogAlertRuleCreated, "log_alert_rule", &rule.ID, models.JSONMap{
+			"name":           rule.Name,
+			"query":          rule.Query,
+			"condition":      rule.Condition,
+			"threshold":      rule.Threshold,
+			"window_seconds": rule.WindowSeconds,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(rule)
+	}
+}
+
+// GetLogAlertRule returns a single log alert rule with its evaluation state
+func GetLogAlertRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		rule, err := findLogAlertRule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(rule)
+	}
+}
+
+// lockLogAlertRule reloads a rule with its row locked until the transaction ends, so
+// edits apply to its current evaluation state
+func lockLogAlertRule(tx *gorm.DB, ruleID uint) (*models.LogAlertRule, error) {
+	var rule models.LogAlertRule
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rule, ruleID).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateLogAlertRule updates a log alert rule's definition. An enabled rule is
+// evaluated right away and resolves on that evaluation if it no longer matches;
+// disabling a firing rule resolves it immediately.
+func UpdateLogAlertRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		rule, err := findLogAlertRule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req LogAlertRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			locked, err := lockLogAlertRule(tx, rule.ID)
+			if err != nil {
+				return err
+			}
+			if err := applyLogAlertRuleRequest(locked, req); err != nil {
+				return err
+			}
+			locked.NextEvaluationAt = time.Now()
+			fields := append([]string{"NextEvaluationAt"}, models.LogAlertRuleDefinitionFields...)
+			if err := tx.Model(locked).Select(fields).Updates(locked).Error; err != nil {
+				return err
+			}
+			if !locked.Enabled {
+				if err := logalerts.Resolve(tx, *locked, "Rule disabled"); err != nil {
+					return err
+				}
+				locked.Firing = false
+			}
+			rule = locked
+			return nil
+		})
+		if err != nil {
+			if _, ok := err.(*fiber.Error); ok {
+				return respondError(c, err)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update log alert rule",
+			})
+		}
+
+		logAuditEvent(db, rule.OrgID, &userID, models.AuditActionLogAlertRuleUpdated, "log_alert_rule", &rule.ID, models.JSONMap{
+			"name":           rule.Name,
+			"enabled":        rule.Enabled,
+			"query":          rule.Query,
+			"condition":      rule.Condition,
+			"threshold":      rule.Threshold,
+			"window_seconds": rule.WindowSeconds,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(rule)
+	}
+}
+
+// DeleteLogAlertRule removes a log alert rule, resolving it if it is firing. Its past
+// alerts are kept.
+func DeleteLogAlertRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		rule, err := findLogAlertRule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			locked, err := lockLogAlertRule(tx, rule.ID)
+			if err != nil {
+				return err
+			}
+			if err := logalerts.Resolve(tx, *locked, "Rule deleted"); err != nil {
+				return err
+			}
+			return tx.Delete(locked).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete log alert rule",
+			})
+		}
+
+		logAuditEvent(db, rule.OrgID, &userID, models.AuditActionLogAlertRuleDeleted, "log_alert_rule", &rule.ID, models.JSONMap{
+			"name": rule.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "log alert rule deleted successfully",
+		})
+	}
+}
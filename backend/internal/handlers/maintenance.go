@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+type CreateMaintenanceWindowRequest struct {
+	Name     string    `json:"name"`
+	CheckID  *uint     `json:"check_id,omitempty"`
+	GroupID  *uint     `json:"group_id,omitempty"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// ListMaintenanceWindows returns maintenance windows for the organization.
+// Pass ?active=true to only return windows in effect right now.
+func ListMaintenanceWindows(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		query := db.Where("org_id = ?", orgID)
+		if c.Query("active") == "true" {
+			now := time.Now()
+			query = query.Where("starts_at <= ? AND ends_at > ?", now, now)
+		}
+
+		var windows []models.MaintenanceWindow
+		if err := query.Order("starts_at DESC").Limit(200).Find(&windows).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch maintenance windows",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"maintenance_windows": windows,
+		})
+	}
+}
+
+// CreateMaintenanceWindow schedules a maintenance window for a check or a check group
+func CreateMaintenanceWindow(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req CreateMaintenanceWindowRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "name is required",
+			})
+		}
+		if (req.CheckID == nil) == (req.GroupID == nil) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "exactly one of check_id or group_id is required",
+			})
+		}
+		if req.StartsAt.IsZero() || req.EndsAt.IsZero() || !req.EndsAt.After(req.StartsAt) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "starts_at and ends_at are required and ends_at must be after starts_at",
+			})
+		}
+
+		// Verify the target belongs to this org
+		if req.CheckID != nil {
+			var count int64
+			if err := db.Model(&models.Check{}).Where("id = ? AND org_id = ?", *req.CheckID, orgID).Count(&count).Error; err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "failed to fetch check",
+				})
+			}
+			if count == 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "check not found",
+				})
+			}
+		} else if _, err := validateCheckGroupID(db, orgID, req.GroupID); err != nil {
+			return respondError(c, err)
+		}
+
+		window := models.MaintenanceWindow{
+			OrgID:       orgID,
+			Name:        req.Name,
+			CheckID:     req.CheckID,
+			GroupID:     req.GroupID,
+			StartsAt:    req.StartsAt,
+			EndsAt:      req.EndsAt,
+			CreatedByID: &userID,
+		}
+		if err := db.Create(&window).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create maintenance window",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionMaintenanceCreated, "maintenance_window", &window.ID, models.JSONMap{
+			"name":      window.Name,
+			"check_id":  window.CheckID,
+			"group_id":  window.GroupID,
+			"starts_at": window.StartsAt,
+			"ends_at":   window.EndsAt,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(window)
+	}
+}
+
+// DeleteMaintenanceWindow removes a maintenance window (ending it early if active)
+func DeleteMaintenanceWindow(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		windowID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid maintenance window ID",
+			})
+		}
+
+		var window models.MaintenanceWindow
+		if err := db.Where("id = ? AND org_id = ?", windowID, orgID).First(&window).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "maintenance window not found",
+			})
+		}
+
+		if err := db.Delete(&window).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete maintenance window",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionMaintenanceDeleted, "maintenance_window", &window.ID, models.JSONMap{
+			"name": window.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "maintenance window deleted successfully",
+		})
+	}
+}
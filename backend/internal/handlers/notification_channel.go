@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+	"gorm.io/gorm"
+)
+
+type NotificationChannelRequest struct {
+	Name    *string                         `json:"name,omitempty"`
+	Type    *models.NotificationChannelType `json:"type,omitempty"` // Only settable on create
+	Enabled *bool                           `json:"enabled,omitempty"`
+	Config  *models.JSONMap                 `json:"config,omitempty"`
+}
+
+// NotificationChannelSecretResponse carries a newly generated signing secret.
+// The secret is only ever returned here, right after creation or rotation.
+type NotificationChannelSecretResponse struct {
+	models.NotificationChannel
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+type RotateSigningSecretRequest struct {
+	GracePeriodHours *int `json:"grace_period_hours,omitempty"` // How long the old secret stays valid (default 24, max 168)
+}
+
+// findNotificationChannel loads a channel by route param and verifies org ownership
+func findNotificationChannel(c *fiber.Ctx, db *gorm.DB) (*models.NotificationChannel, error) {
+	orgID := c.Locals("orgID").(uint)
+	channelID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid notification channel ID")
+	}
+	var channel models.NotificationChannel
+	if err := db.Where("id = ? AND org_id = ?", channelID, orgID).First(&channel).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "notification channel not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch notification channel")
+	}
+	return &channel, nil
+}
+
+// validateChannelConfig rejects configs the channel type cannot send with
+func validateChannelConfig(channelType models.NotificationChannelType, config models.JSONMap) error {
+	if _, err := notifier.NewChannel(models.NotificationChannel{Type: channelType, Config: config}); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// ListNotificationChannelTypes returns the channel types that can be created
+func ListNotificationChannelTypes() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		types := notifier.ChannelTypes()
+		sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+		return c.JSON(fiber.Map{
+			"types": types,
+		})
+	}
+}
+
+// ListNotificationChannels returns all notification channels for the organization
+func ListNotificationChannels(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var channels []models.NotificationChannel
+		if err := db.Where("org_id = ?", orgID).Order("id ASC").Find(&channels).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch notification channels",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"channels": channels,
+		})
+	}
+}
+
+// GetNotificationChannel returns a single notification channel
+func GetNotificationChannel(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		channel, err := findNotificationChannel(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(channel)
+	}
+}
+
+// CreateNotificationChannel adds a notification channel to the organization
+func CreateNotificationChannel(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req NotificationChannelRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "name is required",
+			})
+		}
+		if req.Type == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "type is required",
+			})
+		}
+		config := models.JSONMap{}
+		if req.Config != nil {
+			config = *req.Config
+		}
+		if err := validateChannelConfig(*req.Type, config); err != nil {
+			return respondError(c, err)
+		}
+
+		channel := models.NotificationChannel{
+			OrgID:   orgID,
+			Name:    strings.TrimSpace(*req.Name),
+			Type:    *req.Type,
+			Enabled: req.Enabled == nil || *req.Enabled,
+			Config:  config,
+		}
+		if channel.UsesSigningSecret() {
+			secret, err := models.GenerateSigningSecret()
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "failed to generate signing secret",
+				})
+			}
+			channel.SigningSecret = secret
+		}
+		if err := db.Create(&channel).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create notification channel",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionNotificationChannelCreated, "notification_channel", &channel.ID, models.JSONMap{
+			"name":    channel.Name,
+			"type":    channel.Type,
+			"enabled": channel.Enabled,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(NotificationChannelSecretResponse{
+			NotificationChannel: channel,
+			SigningSecret:       channel.SigningSecret,
+		})
+	}
+}
+
+// UpdateNotificationChannel renames, enables/disables or reconfigures a channel.
+// Config changes to channels mirrored from notification settings are overwritten
+// the next time the settings are saved.
+func UpdateNotificationChannel(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		channel, err := findNotificationChannel(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req NotificationChannelRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		if req.Type != nil && *req.Type != channel.Type {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "channel type cannot be changed",
+			})
+		}
+
+		changes := models.JSONMap{}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "name cannot be empty",
+				})
+			}
+			if name != channel.Name {
+				changes["name"] = map[string]interface{}{"from": channel.Name, "to": name}
+				channel.Name = name
+			}
+		}
+		if req.Enabled != nil && *req.Enabled != channel.Enabled {
+			changes["enabled"] = map[string]interface{}{"from": channel.Enabled, "to": *req.Enabled}
+			channel.Enabled = *req.Enabled
+		}
+		if req.Config != nil {
+			if err := validateChannelConfig(channel.Type, *req.Config); err != nil {
+				return respondError(c, err)
+			}
+			channel.Config = *req.Config
+			changes["config"] = true // Configs may hold secrets, so only record that it changed
+		}
+
+		if err := db.Save(channel).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update notification channel",
+			})
+		}
+
+		if len(changes) > 0 {
+			logAuditEvent(db, channel.OrgID, &userID, models.AuditActionNotificationChannelUpdated, "notification_channel", &channel.ID, models.JSONMap{
+				"name":    channel.Name,
+				"changes": changes,
+			}, c.IP(), c.Get("User-Agent"))
+		}
+
+		return c.JSON(channel)
+	}
+}
+
+// DeleteNotificationChannel removes a notification channel
+func DeleteNotificationChannel(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		channel, err := findNotificationChannel(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		if err := db.Delete(channel).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete notification channel",
+			})
+		}
+
+		logAuditEvent(db, channel.OrgID, &userID, models.AuditActionNotificationChannelDeleted, "notification_channel", &channel.ID, models.JSONMap{
+			"name": channel.Name,
+			"type": channel.Type,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "notification channel deleted successfully",
+		})
+	}
+}
+
+// RotateNotificationChannelSecret issues a new signing secret for a webhook channel.
+// Deliveries are signed with both secrets until the grace period ends.
+func RotateNotificationChannelSecret(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		channel, err := findNotificationChannel(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		if !channel.UsesSigningSecret() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "only webhook channels have signing secrets",
+			})
+		}
+
+		var req RotateSigningSecretRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "invalid request body",
+				})
+			}
+		}
+		graceHours := 24
+		if req.GracePeriodHours != nil {
+			graceHours = *req.GracePeriodHours
+		}
+		if graceHours < 0 || graceHours > 168 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "grace_period_hours must be between 0 and 168",
+			})
+		}
+
+		secret, err := channel.RotateSigningSecret(time.Duration(graceHours) * time.Hour)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to generate signing secret",
+			})
+		}
+		if err := db.Save(channel).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to rotate signing secret",
+			})
+		}
+
+		logAuditEvent(db, channel.OrgID, &userID, models.AuditActionNotificationChannelSecretRotated, "notification_channel", &channel.ID, models.JSONMap{
+			"name":               channel.Name,
+			"grace_period_hours": graceHours,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(NotificationChannelSecretResponse{
+			NotificationChannel: *channel,
+			SigningSecret:       secret,
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+	"gorm.io/gorm"
+)
+
+// findOrgAlert loads an alert by route param and verifies org ownership
+func findOrgAlert(c *fiber.Ctx, db *gorm.DB) (*models.Alert, error) {
+	orgID := c.Locals("orgID").(uint)
+	alertID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid alert ID")
+	}
+	var alert models.Alert
+	if err := db.Where("id = ? AND org_id = ?", alertID, orgID).First(&alert).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "alert not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch alert")
+	}
+	return &alert, nil
+}
+
+// GetAlertDeliveries returns every delivery attempt for an alert, newest first,
+// along with the queued jobs so pending retries and dead letters are visible
+func GetAlertDeliveries(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		alert, err := findOrgAlert(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var deliveries []models.NotificationDelivery
+		if err := db.Where("alert_id = ?", alert.ID).Order("created_at DESC").Find(&deliveries).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch deliveries",
+			})
+		}
+
+		var jobs []models.NotificationJob
+		if err := db.Where("alert_id = ?", alert.ID).Order("id ASC").Find(&jobs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch delivery jobs",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"deliveries": deliveries,
+			"jobs":       jobs,
+		})
+	}
+}
+
+// RedeliverAlertNotification resends an alert to the channel or user of a previous delivery
+// and returns the new delivery attempt
+func RedeliverAlertNotification(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		alert, err := findOrgAlert(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		deliveryID, err := strconv.ParseUint(c.Params("deliveryId"), 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid delivery ID",
+			})
+		}
+		var previous models.NotificationDelivery
+		if err := db.Where("id = ? AND alert_id = ?", deliveryID, alert.ID).First(&previous).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "delivery not found",
+			})
+		}
+
+		if previous.ChannelID != nil {
+			var channel models.NotificationChannel
+			if err := db.Where("id = ? AND org_id = ?", *previous.ChannelID, alert.OrgID).First(&channel).Error; err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "notification channel no longer exists",
+				})
+			}
+			if !channel.Enabled {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "notification channel is disabled",
+				})
+			}
+		}
+
+		delivery, sendErr := notifier.Redeliver(db, *alert, previous, userID)
+		if delivery == nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to redeliver notification",
+			})
+		}
+
+		logAuditEvent(db, alert.OrgID, &userID, models.AuditActionAlertRedelivered, "alert", &alert.ID, models.JSONMap{
+			"channel_id":           previous.ChannelID,
+			"user_id":              previous.UserID,
+			"target":               delivery.ChannelName,
+			"previous_delivery_id": previous.ID,
+			"success":              sendErr == nil,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(delivery)
+	}
+}
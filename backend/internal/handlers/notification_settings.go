@@ -1,12 +1,15 @@
 package handlers
 
 import (
+    "context"
     "regexp"
     "strings"
+    "time"
 
     "github.com/gofiber/fiber/v2"
     "github.com/lib/pq"
     "github.com/oFuterman/light-house/internal/models"
+    "github.com/oFuterman/light-house/internal/notifier"
     "gorm.io/gorm"
 )
 
@@ -17,6 +20,13 @@ type NotificationSettingsRequest struct {
     WebhookURL      *string  `json:"webhook_url"`
 }
 
+type TestNotificationRequest struct {
+    ChannelIDs []uint `json:"channel_ids,omitempty"` // Defaults to every enabled channel
+}
+
+// testNotificationTimeout bounds the whole test request; channels are tried in parallel
+const testNotificationTimeout = 45 * time.Second
+
 type NotificationSettingsResponse struct {
     ID              uint     `json:"id"`
     EmailRecipients []string `json:"email_recipients"`
@@ -94,32 +104,26 @@ func UpdateNotificationSettings(db *gorm.DB) fiber.Handler {
                 req.WebhookURL = &url
             }
         }
-        // Upsert settings
+        // Upsert settings and mirror them into the org's settings-sourced notification channels
         var settings models.NotificationSettings
-        err = db.Where("org_id = ?", orgID).First(&settings).Error
-        if err == gorm.ErrRecordNotFound {
-            settings = models.NotificationSettings{
-                OrgID:           orgID,
-                EmailRecipients: pq.StringArray(validatedEmails),
-                WebhookURL:      req.WebhookURL,
-            }
-            if err := db.Create(&settings).Error; err != nil {
-                return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                    "error": "failed to create notification settings",
-                })
+        err = db.Transaction(func(tx *gorm.DB) error {
+            err := tx.Where("org_id = ?", orgID).First(&settings).Error
+            if err == gorm.ErrRecordNotFound {
+                settings = models.NotificationSettings{OrgID: orgID}
+            } else if err != nil {
+                return err
             }
-        } else if err != nil {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                "error": "failed to fetch notification settings",
-            })
-        } else {
             settings.EmailRecipients = pq.StringArray(validatedEmails)
             settings.WebhookURL = req.WebhookURL
-            if err := db.Save(&settings).Error; err != nil {
-                return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-                    "error": "failed to update notification settings",
-                })
+            if err := tx.Save(&settings).Error; err != nil {
+                return err
             }
+            return models.SyncSettingsChannels(tx, settings)
+        })
+        if err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "failed to update notification settings",
+            })
         }
         return c.JSON(NotificationSettingsResponse{
             ID:              settings.ID,
@@ -128,3 +132,77 @@ func UpdateNotificationSettings(db *gorm.DB) fiber.Handler {
         })
     }
 }
+
+// TestNotificationSettings sends a synthetic, clearly labelled TEST alert through each
+// configured channel and reports per-channel results synchronously
+func TestNotificationSettings(db *gorm.DB) fiber.Handler {
+    return func(c *fiber.Ctx) error {
+        orgID := c.Locals("orgID").(uint)
+        userID := c.Locals("userID").(uint)
+        var req TestNotificationRequest
+        if len(c.Body()) > 0 {
+            if err := c.BodyParser(&req); err != nil {
+                return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                    "error": "invalid request body",
+                })
+            }
+        }
+        requested := map[uint]bool{}
+        for _, id := range req.ChannelIDs {
+            requested[id] = true
+        }
+        query := db.Where("org_id = ?", orgID)
+        if len(req.ChannelIDs) > 0 {
+            query = query.Where("id IN ?", req.ChannelIDs)
+        } else {
+            query = query.Where("enabled = ?", true)
+        }
+        var channels []models.NotificationChannel
+        if err := query.Order("id ASC").Find(&channels).Error; err != nil {
+            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+                "error": "failed to fetch notification channels",
+            })
+        }
+        if len(requested) > 0 && len(channels) != len(requested) {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "one or more notification channels not found",
+            })
+        }
+        if len(channels) == 0 {
+            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+                "error": "no notification channels configured",
+            })
+        }
+        org, err := loadOrg(db, orgID)
+        if err != nil {
+            return respondError(c, err)
+        }
+        ctx, cancel := context.WithTimeout(context.Background(), testNotificationTimeout)
+        defer cancel()
+        results := notifier.SendTestNotifications(ctx, notifier.NewTestNotification(db, *org), channels)
+        succeeded := 0
+        auditResults := make([]map[string]interface{}, len(results))
+        for i, result := range results {
+            if result.Success {
+                succeeded++
+            }
+            auditResults[i] = map[string]interface{}{
+                "channel_id":  result.ChannelID,
+                "type":        result.ChannelType,
+                "success":     result.Success,
+                "status_code": result.StatusCode,
+                "error":       result.Error,
+            }
+        }
+        logAuditEvent(db, orgID, &userID, models.AuditActionNotificationTestSent, "notification_settings", nil, models.JSONMap{
+            "results":   auditResults,
+            "succeeded": succeeded,
+            "failed":    len(results) - succeeded,
+        }, c.IP(), c.Get("User-Agent"))
+        return c.JSON(fiber.Map{
+            "results":   results,
+            "succeeded": succeeded,
+            "failed":    len(results) - succeeded,
+        })
+    }
+}
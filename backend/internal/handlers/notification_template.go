@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+	"gorm.io/gorm"
+)
+
+type NotificationTemplateRequest struct {
+	EmailSubject string `json:"email_subject"`
+	EmailText    string `json:"email_text"`
+	EmailHTML    string `json:"email_html"`
+	WebhookBody  string `json:"webhook_body"`
+}
+
+// NotificationTemplateDefaults are the built-in templates used for empty fields
+type NotificationTemplateDefaults struct {
+	EmailSubject string `json:"email_subject"`
+	EmailText    string `json:"email_text"`
+	EmailHTML    string `json:"email_html"`
+}
+
+func (r NotificationTemplateRequest) toModel(orgID uint) models.NotificationTemplate {
+	return models.NotificationTemplate{
+		OrgID:        orgID,
+		EmailSubject: r.EmailSubject,
+		EmailText:    r.EmailText,
+		EmailHTML:    r.EmailHTML,
+		WebhookBody:  r.WebhookBody,
+	}
+}
+
+// loadOrg fetches the current org for template rendering
+func loadOrg(db *gorm.DB, orgID uint) (*models.Organization, error) {
+	var org models.Organization
+	if err := db.First(&org, orgID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to load organization")
+	}
+	return &org, nil
+}
+
+// GetNotificationTemplates returns the org's custom templates and the defaults they override
+func GetNotificationTemplates(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		templates := models.NotificationTemplate{OrgID: orgID}
+		if err := db.Where("org_id = ?", orgID).First(&templates).Error; err != nil && err != gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch notification templates",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"templates": templates,
+			"defaults": NotificationTemplateDefaults{
+				EmailSubject: notifier.DefaultEmailSubjectTemplate,
+				EmailText:    notifier.DefaultEmailTextTemplate,
+				EmailHTML:    notifier.DefaultEmailHTMLTemplate,
+			},
+		})
+	}
+}
+
+// UpdateNotificationTemplates validates and saves the org's templates.
+// Empty fields restore the default for that part.
+func UpdateNotificationTemplates(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req NotificationTemplateRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		org, err := loadOrg(db, orgID)
+		if err != nil {
+			return respondError(c, err)
+		}
+		updated := req.toModel(orgID)
+		if err := notifier.ValidateTemplates(updated, *org); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		var templates models.NotificationTemplate
+		err = db.Where("org_id = ?", orgID).First(&templates).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch notification templates",
+			})
+		}
+		templates.OrgID = orgID
+		templates.EmailSubject = updated.EmailSubject
+		templates.EmailText = updated.EmailText
+		templates.EmailHTML = updated.EmailHTML
+		templates.WebhookBody = updated.WebhookBody
+		if err := db.Save(&templates).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to save notification templates",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionNotificationTemplatesUpdated, "notification_template", &templates.ID, models.JSONMap{
+			"custom_email_subject": templates.EmailSubject != "",
+			"custom_email_text":    templates.EmailText != "",
+			"custom_email_html":    templates.EmailHTML != "",
+			"custom_webhook_body":  templates.WebhookBody != "",
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(templates)
+	}
+}
+
+// PreviewNotificationTemplates renders the submitted templates (without saving them)
+// against a sample DOWN alert
+func PreviewNotificationTemplates(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var req NotificationTemplateRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		org, err := loadOrg(db, orgID)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		preview, err := notifier.PreviewTemplates(req.toModel(orgID), notifier.SampleNotification(*org))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(preview)
+	}
+}
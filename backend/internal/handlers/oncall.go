@@ -0,0 +1,458 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/oncall"
+	"gorm.io/gorm"
+)
+
+type OnCallScheduleRequest struct {
+	Name             *string    `json:"name,omitempty"`
+	Timezone         *string    `json:"timezone,omitempty"`
+	RotationStart    *time.Time `json:"rotation_start,omitempty"`
+	ShiftLengthHours *int       `json:"shift_length_hours,omitempty"`
+	ParticipantIDs   *[]uint    `json:"participant_ids,omitempty"`
+}
+
+type CreateOnCallOverrideRequest struct {
+	UserID   uint      `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// OnCallShiftResponse is a shift with the on-call user's email resolved
+type OnCallShiftResponse struct {
+	oncall.Shift
+	ScheduleName string `json:"schedule_name"`
+	UserEmail    string `json:"user_email,omitempty"`
+}
+
+// validateOrgUserIDs checks every user ID belongs to the organization
+func validateOrgUserIDs(db *gorm.DB, orgID uint, ids []uint) (pq.Int64Array, error) {
+	result := pq.Int64Array{}
+	unique := map[uint]bool{}
+	for _, id := range ids {
+		if !unique[id] {
+			unique[id] = true
+			result = append(result, int64(id))
+		}
+	}
+	if len(result) == 0 {
+		return result, nil
+	}
+	var count int64
+	if err := db.Model(&models.User{}).Where("org_id = ? AND id IN ?", orgID, ids).Count(&count).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch users")
+	}
+	if int(count) != len(result) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "one or more users not found")
+	}
+	return result, nil
+}
+
+// parseAtParam reads an optional ?at=<RFC3339> query parameter, defaulting to now
+func parseAtParam(c *fiber.Ctx) (time.Time, error) {
+	atParam := c.Query("at")
+	if atParam == "" {
+		return time.Now(), nil
+	}
+	at, err := time.Parse(time.RFC3339, atParam)
+	if err != nil {
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, "at must be an RFC3339 timestamp")
+	}
+	return at, nil
+}
+
+// findOnCallSchedule loads a schedule by route param and verifies org ownership
+func findOnCallSchedule(c *fiber.Ctx, db *gorm.DB) (*models.OnCallSchedule, error) {
+	orgID := c.Locals("orgID").(uint)
+	scheduleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid schedule ID")
+	}
+	var schedule models.OnCallSchedule
+	if err := db.Where("id = ? AND org_id = ?", scheduleID, orgID).First(&schedule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "schedule not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch schedule")
+	}
+	return &schedule, nil
+}
+
+// resolveShift computes who is on call and attaches their email
+func resolveShift(db *gorm.DB, schedule models.OnCallSchedule, at time.Time) (OnCallShiftResponse, error) {
+	shift, err := oncall.WhoIsOnCall(db, schedule, at)
+	if err != nil {
+		return OnCallShiftResponse{}, fiber.NewError(fiber.StatusInternalServerError, "failed to resolve on-call user")
+	}
+	response := OnCallShiftResponse{Shift: shift, ScheduleName: schedule.Name}
+	if shift.UserID != nil {
+		var user models.User
+		if err := db.Select("email").First(&user, *shift.UserID).Error; err == nil {
+			response.UserEmail = user.Email
+		}
+	}
+	return response, nil
+}
+
+// applyOnCallScheduleRequest validates the request and copies set fields onto the schedule
+func applyOnCallScheduleRequest(db *gorm.DB, orgID uint, schedule *models.OnCallSchedule, req OnCallScheduleRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "name cannot be empty")
+		}
+		schedule.Name = name
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid timezone")
+		}
+		schedule.Timezone = *req.Timezone
+	}
+	if req.RotationStart != nil {
+		schedule.RotationStart = *req.RotationStart
+	}
+	if req.ShiftLengthHours != nil {
+		if *req.ShiftLengthHours < 1 || *req.ShiftLengthHours > 24*28 {
+			return fiber.NewError(fiber.StatusBadRequest, "shift_length_hours must be between 1 and 672")
+		}
+		schedule.ShiftLengthHours = *req.ShiftLengthHours
+	}
+	if req.ParticipantIDs != nil {
+		ids, err := validateOrgUserIDs(db, orgID, *req.ParticipantIDs)
+		if err != nil {
+			return err
+		}
+		schedule.ParticipantIDs = ids
+	}
+	return nil
+}
+
+// ListOnCallSchedules returns the org's schedules
+func ListOnCallSchedules(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var schedules []models.OnCallSchedule
+		if err := db.Where("org_id = ?", orgID).Order("name ASC").Find(&schedules).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch schedules",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"schedules": schedules,
+		})
+	}
+}
+
+// CreateOnCallSchedule creates a rotation
+func CreateOnCallSchedule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req OnCallScheduleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.Name == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "name is required",
+			})
+		}
+		if req.RotationStart == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "rotation_start is required",
+			})
+		}
+
+		schedule := models.OnCallSchedule{OrgID: orgID, Timezone: "UTC", ShiftLengthHours: 168}
+		if err := applyOnCallScheduleRequest(db, orgID, &schedule, req); err != nil {
+			return respondError(c, err)
+		}
+		if err := db.Create(&schedule).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create schedule",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionOnCallScheduleCreated, "oncall_schedule", &schedule.ID, models.JSONMap{
+			"name": schedule.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(schedule)
+	}
+}
+
+// GetOnCallSchedule returns a schedule with its current shift and upcoming overrides
+func GetOnCallSchedule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		schedule, err := findOnCallSchedule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		current, err := resolveShift(db, *schedule, time.Now())
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var overrides []models.OnCallOverride
+		if err := db.Where("schedule_id = ? AND ends_at > ?", schedule.ID, time.Now()).
+			Order("starts_at ASC").
+			Find(&overrides).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch overrides",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"schedule":  schedule,
+			"on_call":   current,
+			"overrides": overrides,
+		})
+	}
+}
+
+// UpdateOnCallSchedule updates a schedule's rotation settings
+func UpdateOnCallSchedule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		schedule, err := findOnCallSchedule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req OnCallScheduleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if err := applyOnCallScheduleRequest(db, schedule.OrgID, schedule, req); err != nil {
+			return respondError(c, err)
+		}
+		if err := db.Save(schedule).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update schedule",
+			})
+		}
+
+		logAuditEvent(db, schedule.OrgID, &userID, models.AuditActionOnCallScheduleUpdated, "oncall_schedule", &schedule.ID, models.JSONMap{
+			"name": schedule.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(schedule)
+	}
+}
+
+// DeleteOnCallSchedule deletes a schedule and its overrides
+func DeleteOnCallSchedule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		schedule, err := findOnCallSchedule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("schedule_id = ?", schedule.ID).Delete(&models.OnCallOverride{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(schedule).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete schedule",
+			})
+		}
+
+		logAuditEvent(db, schedule.OrgID, &userID, models.AuditActionOnCallScheduleDeleted, "oncall_schedule", &schedule.ID, models.JSONMap{
+			"name": schedule.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "schedule deleted successfully",
+		})
+	}
+}
+
+// GetScheduleOnCall returns who is on call for one schedule. Pass ?at=<RFC3339> to look ahead.
+func GetScheduleOnCall(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		schedule, err := findOnCallSchedule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		at, err := parseAtParam(c)
+		if err != nil {
+			return respondError(c, err)
+		}
+		shift, err := resolveShift(db, *schedule, at)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(shift)
+	}
+}
+
+// WhoIsOnCall returns the on-call user of every schedule in the org.
+// Pass ?at=<RFC3339> to look ahead.
+func WhoIsOnCall(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		at, err := parseAtParam(c)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var schedules []models.OnCallSchedule
+		if err := db.Where("org_id = ?", orgID).Order("name ASC").Find(&schedules).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch schedules",
+			})
+		}
+
+		shifts := make([]OnCallShiftResponse, 0, len(schedules))
+		for _, schedule := range schedules {
+			shift, err := resolveShift(db, schedule, at)
+			if err != nil {
+				return respondError(c, err)
+			}
+			shifts = append(shifts, shift)
+		}
+
+		return c.JSON(fiber.Map{
+			"at":      at,
+			"on_call": shifts,
+		})
+	}
+}
+
+// ListOnCallOverrides returns a schedule's overrides. Pass ?include_past=true for ended ones.
+func ListOnCallOverrides(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		schedule, err := findOnCallSchedule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		query := db.Where("schedule_id = ?", schedule.ID)
+		if c.Query("include_past") != "true" {
+			query = query.Where("ends_at > ?", time.Now())
+		}
+		var overrides []models.OnCallOverride
+		if err := query.Order("starts_at ASC").Limit(200).Find(&overrides).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch overrides",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"overrides": overrides,
+		})
+	}
+}
+
+// CreateOnCallOverride puts a user on call for a time range, replacing the rotation
+func CreateOnCallOverride(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		schedule, err := findOnCallSchedule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req CreateOnCallOverrideRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.StartsAt.IsZero() || req.EndsAt.IsZero() || !req.EndsAt.After(req.StartsAt) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "starts_at and ends_at are required and ends_at must be after starts_at",
+			})
+		}
+		if _, err := validateOrgUserIDs(db, schedule.OrgID, []uint{req.UserID}); err != nil {
+			return respondError(c, err)
+		}
+
+		override := models.OnCallOverride{
+			OrgID:      schedule.OrgID,
+			ScheduleID: schedule.ID,
+			UserID:     req.UserID,
+			StartsAt:   req.StartsAt,
+			EndsAt:     req.EndsAt,
+		}
+		if err := db.Create(&override).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create override",
+			})
+		}
+
+		logAuditEvent(db, schedule.OrgID, &userID, models.AuditActionOnCallScheduleUpdated, "oncall_schedule", &schedule.ID, models.JSONMap{
+			"name":               schedule.Name,
+			"override_id":        override.ID,
+			"override_user_id":   override.UserID,
+			"override_starts_at": override.StartsAt,
+			"override_ends_at":   override.EndsAt,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(override)
+	}
+}
+
+// DeleteOnCallOverride removes an override
+func DeleteOnCallOverride(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		schedule, err := findOnCallSchedule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		overrideID, err := strconv.ParseUint(c.Params("overrideId"), 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid override ID",
+			})
+		}
+
+		result := db.Where("id = ? AND schedule_id = ?", overrideID, schedule.ID).Delete(&models.OnCallOverride{})
+		if result.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete override",
+			})
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "override not found",
+			})
+		}
+
+		logAuditEvent(db, schedule.OrgID, &userID, models.AuditActionOnCallScheduleUpdated, "oncall_schedule", &schedule.ID, models.JSONMap{
+			"name":                schedule.Name,
+			"deleted_override_id": overrideID,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "override deleted successfully",
+		})
+	}
+}
@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/digest"
+	"github.com/oFuterman/light-house/internal/models"
 	"gorm.io/gorm"
 )
 
@@ -32,18 +38,107 @@ func GetOrganization(db *gorm.DB) fiber.Handler {
 	}
 }
 
-// UpdateOrganization updates an organization
+type UpdateOrganizationRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Timezone *string `json:"timezone,omitempty"` // IANA name, e.g. "Europe/Berlin"
+}
+
+// UpdateOrganization updates an organization's name or timezone.
+// Changing the timezone reschedules the org's digests.
 func UpdateOrganization(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// TODO: Implement update organization
-		// 1. Parse organization ID from params
-		// 2. Parse request body
-		// 3. Verify user has access
-		// 4. Update organization
-		// 5. Return updated organization
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
 
-		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
-			"error": "not implemented",
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid organization ID",
+			})
+		}
+		if uint(id) != orgID {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "organization not found",
+			})
+		}
+
+		var req UpdateOrganizationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		org, err := loadOrg(db, orgID)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		updates := map[string]interface{}{}
+		changes := models.JSONMap{}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "name cannot be empty",
+				})
+			}
+			if name != org.Name {
+				updates["name"] = name
+				changes["name"] = map[string]interface{}{"from": org.Name, "to": name}
+			}
+		}
+		if req.Timezone != nil {
+			timezone := strings.TrimSpace(*req.Timezone)
+			if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || strings.EqualFold(timezone, "local") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "timezone must be a valid IANA timezone name",
+				})
+			}
+			if timezone != org.Timezone {
+				updates["timezone"] = timezone
+				changes["timezone"] = map[string]interface{}{"from": org.Timezone, "to": timezone}
+			}
+		}
+		if len(updates) == 0 {
+			return c.JSON(org)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(org).Updates(updates).Error; err != nil {
+				return err
+			}
+			if name, ok := updates["name"].(string); ok {
+				org.Name = name
+			}
+			timezone, ok := updates["timezone"].(string)
+			if !ok {
+				return nil
+			}
+			org.Timezone = timezone
+			var subs []models.DigestSubscription
+			if err := tx.Where("org_id = ?", org.ID).Find(&subs).Error; err != nil {
+				return err
+			}
+			now := time.Now()
+			for i := range subs {
+				digest.ScheduleNext(&subs[i], *org, now)
+				if err := tx.Model(&subs[i]).Update("next_run_at", subs[i].NextRunAt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
 		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update organization",
+			})
+		}
+
+		logAuditEvent(db, org.ID, &userID, models.AuditActionOrgUpdated, "organization", &org.ID, models.JSONMap{
+			"changes": changes,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(org)
 	}
 }
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+type RoutingRuleRequest struct {
+	Name           *string         `json:"name,omitempty"`
+	Enabled        *bool           `json:"enabled,omitempty"`
+	Environments   *[]string       `json:"environments,omitempty"`
+	ServiceNames   *[]string       `json:"service_names,omitempty"`
+	AlertTypes     *[]string       `json:"alert_types,omitempty"`
+	Tags           *models.JSONMap `json:"tags,omitempty"`
+	GroupIDs       *[]uint         `json:"group_ids,omitempty"`
+	ChannelIDs     *[]uint         `json:"channel_ids,omitempty"`
+	StopProcessing *bool           `json:"stop_processing,omitempty"`
+}
+
+type ReorderRoutingRulesRequest struct {
+	RuleIDs []uint `json:"rule_ids"` // Every rule of the org, in the new evaluation order
+}
+
+// findRoutingRule loads a routing rule by route param and verifies org ownership
+func findRoutingRule(c *fiber.Ctx, db *gorm.DB) (*models.RoutingRule, error) {
+	orgID := c.Locals("orgID").(uint)
+	ruleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid routing rule ID")
+	}
+	var rule models.RoutingRule
+	if err := db.Where("id = ? AND org_id = ?", ruleID, orgID).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "routing rule not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch routing rule")
+	}
+	return &rule, nil
+}
+
+// cleanStrings trims values and drops empties
+func cleanStrings(values []string, upper bool) pq.StringArray {
+	cleaned := pq.StringArray{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if upper {
+			v = strings.ToUpper(v)
+		}
+		if v != "" {
+			cleaned = append(cleaned, v)
+		}
+	}
+	return cleaned
+}
+
+// applyRoutingRuleRequest validates the request and copies set fields onto the rule
+func applyRoutingRuleRequest(db *gorm.DB, orgID uint, rule *models.RoutingRule, req RoutingRuleRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "name cannot be empty")
+		}
+		rule.Name = name
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Environments != nil {
+		rule.Environments = cleanStrings(*req.Environments, false)
+	}
+	if req.ServiceNames != nil {
+		rule.ServiceNames = cleanStrings(*req.ServiceNames, false)
+	}
+	if req.AlertTypes != nil {
+		rule.AlertTypes = cleanStrings(*req.AlertTypes, true)
+	}
+	if req.Tags != nil {
+		rule.Tags = *req.Tags
+	}
+	if req.GroupIDs != nil {
+		ids, err := validateOrgResourceIDs(db, &models.CheckGroup{}, orgID, *req.GroupIDs, "check group")
+		if err != nil {
+			return err
+		}
+		rule.GroupIDs = ids
+	}
+	if req.StopProcessing != nil {
+		rule.StopProcessing = *req.StopProcessing
+	}
+	if req.ChannelIDs != nil {
+		unique := map[uint]bool{}
+		ids := pq.Int64Array{}
+		for _, id := range *req.ChannelIDs {
+			if !unique[id] {
+				unique[id] = true
+				ids = append(ids, int64(id))
+			}
+		}
+		if len(ids) > 0 {
+			var count int64
+			if err := db.Model(&models.NotificationChannel{}).
+				Where("org_id = ? AND id IN ?", orgID, *req.ChannelIDs).
+				Count(&count).Error; err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch notification channels")
+			}
+			if int(count) != len(ids) {
+				return fiber.NewError(fiber.StatusBadRequest, "one or more notification channels not found")
+			}
+		}
+		rule.ChannelIDs = ids
+	}
+	return nil
+}
+
+// ListRoutingRules returns the org's routing rules in evaluation order
+func ListRoutingRules(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var rules []models.RoutingRule
+		if err := db.Where("org_id = ?", orgID).Order("position ASC, id ASC").Find(&rules).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch routing rules",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"rules": rules,
+		})
+	}
+}
+
+// CreateRoutingRule appends a routing rule to the end of the org's rule list
+func CreateRoutingRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req RoutingRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.Name == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "name is required",
+			})
+		}
+		if req.ChannelIDs == nil || len(*req.ChannelIDs) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "channel_ids is required",
+			})
+		}
+
+		rule := models.RoutingRule{OrgID: orgID, Enabled: true}
+		if err := applyRoutingRuleRequest(db, orgID, &rule, req); err != nil {
+			return respondError(c, err)
+		}
+
+		var maxPosition int
+		db.Model(&models.RoutingRule{}).Where("org_id = ?", orgID).Select("COALESCE(MAX(position), -1)").Scan(&maxPosition)
+		rule.Position = maxPosition + 1
+
+		if err := db.Create(&rule).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create routing rule",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionRoutingRuleCreated, "routing_rule", &rule.ID, models.JSONMap{
+			"name": rule.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(rule)
+	}
+}
+
+// UpdateRoutingRule updates a routing rule's matchers, destinations or flags
+func UpdateRoutingRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		rule, err := findRoutingRule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req RoutingRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.ChannelIDs != nil && len(*req.ChannelIDs) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "channel_ids cannot be empty",
+			})
+		}
+
+		if err := applyRoutingRuleRequest(db, rule.OrgID, rule, req); err != nil {
+			return respondError(c, err)
+		}
+		if err := db.Save(rule).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update routing rule",
+			})
+		}
+
+		logAuditEvent(db, rule.OrgID, &userID, models.AuditActionRoutingRuleUpdated, "routing_rule", &rule.ID, models.JSONMap{
+			"name": rule.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(rule)
+	}
+}
+
+// DeleteRoutingRule removes a routing rule
+func DeleteRoutingRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		rule, err := findRoutingRule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		if err := db.Delete(rule).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete routing rule",
+			})
+		}
+
+		logAuditEvent(db, rule.OrgID, &userID, models.AuditActionRoutingRuleDeleted, "routing_rule", &rule.ID, models.JSONMap{
+			"name": rule.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "routing rule deleted successfully",
+		})
+	}
+}
+
+// ReorderRoutingRules sets the evaluation order of all of the org's rules
+func ReorderRoutingRules(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req ReorderRoutingRulesRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		var existing []uint
+		if err := db.Model(&models.RoutingRule{}).Where("org_id = ?", orgID).Pluck("id", &existing).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch routing rules",
+			})
+		}
+		known := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+		seen := map[uint]bool{}
+		for _, id := range req.RuleIDs {
+			if !known[id] || seen[id] {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "rule_ids must list each of the organization's rules exactly once",
+				})
+			}
+			seen[id] = true
+		}
+		if len(seen) != len(existing) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "rule_ids must list each of the organization's rules exactly once",
+			})
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for position, id := range req.RuleIDs {
+				if err := tx.Model(&models.RoutingRule{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to reorder routing rules",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionRoutingRuleUpdated, "routing_rule", nil, models.JSONMap{
+			"order": req.RuleIDs,
+		}, c.IP(), c.Get("User-Agent"))
+
+		var rules []models.RoutingRule
+		db.Where("org_id = ?", orgID).Order("position ASC, id ASC").Find(&rules)
+		return c.JSON(fiber.Map{
+			"rules": rules,
+		})
+	}
+}
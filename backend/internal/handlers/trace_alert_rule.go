@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/tracealerts"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TraceAlertRuleRequest defines a rule such as "p95 duration of api GET /orders
+// above 800ms over 5m" (metric p95_duration, threshold 800) or "error rate of
+// checkout above 5% over 10m" (metric error_rate, threshold 5, window 600)
+type TraceAlertRuleRequest struct {
+	Name          *string  `json:"name,omitempty"`
+	Enabled       *bool    `json:"enabled,omitempty"`
+	ServiceName   *string  `json:"service_name,omitempty"`
+	Operation     *string  `json:"operation,omitempty"`
+	Environment   *string  `json:"environment,omitempty"`
+	Metric        *string  `json:"metric,omitempty"`
+	Threshold     *float64 `json:"threshold,omitempty"`
+	WindowSeconds *int     `json:"window_seconds,omitempty"`
+	MinSpans      *int     `json:"min_spans,omitempty"`
+}
+
+// findTraceAlertRule loads a trace alert rule by route param and verifies org ownership
+func findTraceAlertRule(c *fiber.Ctx, db *gorm.DB) (*models.TraceAlertRule, error) {
+	orgID := c.Locals("orgID").(uint)
+	ruleID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid trace alert rule ID")
+	}
+	var rule models.TraceAlertRule
+	if err := db.Where("id = ? AND org_id = ?", ruleID, orgID).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, "trace alert rule not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch trace alert rule")
+	}
+	return &rule, nil
+}
+
+// applyTraceAlertRuleRequest validates the request and copies set fields onto the rule
+func applyTraceAlertRuleRequest(rule *models.TraceAlertRule, req TraceAlertRuleRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "name cannot be empty")
+		}
+		rule.Name = name
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.ServiceName != nil {
+		serviceName := strings.TrimSpace(*req.ServiceName)
+		if serviceName == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "service_name cannot be empty")
+		}
+		rule.ServiceName = serviceName
+	}
+	if req.Operation != nil {
+		rule.Operation = strings.TrimSpace(*req.Operation)
+	}
+	if req.Environment != nil {
+		rule.Environment = strings.TrimSpace(*req.Environment)
+	}
+	if req.Metric != nil {
+		metric := models.TraceAlertMetric(strings.ToLower(strings.TrimSpace(*req.Metric)))
+		if !metric.IsValid() {
+			return fiber.NewError(fiber.StatusBadRequest, "metric must be p50_duration, p95_duration, p99_duration or error_rate")
+		}
+		rule.Metric = metric
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.WindowSeconds != nil {
+		rule.WindowSeconds = *req.WindowSeconds
+	}
+	if req.MinSpans != nil {
+		rule.MinSpans = *req.MinSpans
+	}
+
+	if rule.Threshold < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "threshold cannot be negative")
+	}
+	if rule.Metric == models.TraceMetricErrorRate && rule.Threshold >= 100 {
+		return fiber.NewError(fiber.StatusBadRequest, "threshold must be below 100 for error_rate")
+	}
+	if rule.WindowSeconds < models.MinTraceAlertWindowSeconds || rule.WindowSeconds > models.MaxTraceAlertWindowSeconds {
+		return fiber.NewError(fiber.StatusBadRequest, "window_seconds must be between 60 and 86400")
+	}
+	if rule.MinSpans < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "min_spans must be at least 1")
+	}
+	return nil
+}
+
+// traceAlertRuleAuditDetails is the definition recorded in the audit log
+func traceAlertRuleAuditDetails(rule *models.TraceAlertRule) models.JSONMap {
+	return models.JSONMap{
+		"name":           rule.Name,
+		"enabled":        rule.Enabled,
+		"service_name":   rule.ServiceName,
+		"operation":      rule.Operation,
+		"environment":    rule.Environment,
+		"metric":         rule.Metric,
+		"threshold":      rule.Threshold,
+		"window_seconds": rule.WindowSeconds,
+		"min_spans":      rule.MinSpans,
+	}
+}
+
+// ListTraceAlertRules returns the org's trace alert rules
+func ListTraceAlertRules(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+
+		var rules []models.TraceAlertRule
+		if err := db.Where("org_id = ?", orgID).Order("name ASC, id ASC").Find(&rules).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch trace alert rules",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"rules": rules,
+		})
+	}
+}
+
+// CreateTraceAlertRule creates a trace alert rule. It is first evaluated within a minute.
+func CreateTraceAlertRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req TraceAlertRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+		if req.Name == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "name is required",
+			})
+		}
+		if req.ServiceName == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "service_name is required",
+			})
+		}
+		if req.Metric == nil || req.Threshold == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "metric and threshold are required",
+			})
+		}
+
+		rule := models.TraceAlertRule{
+			OrgID:            orgID,
+			Enabled:          true,
+			WindowSeconds:    models.DefaultTraceAlertWindowSeconds,
+			MinSpans:         models.DefaultTraceAlertMinSpans,
+			CreatedByID:      &userID,
+			NextEvaluationAt: time.Now(),
+		}
+		if err := applyTraceAlertRuleRequest(&rule, req); err != nil {
+			return respondError(c, err)
+		}
+
+		if err := db.Create(&rule).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to create trace alert rule",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionTraceAlertRuleCreated, "trace_alert_rule", &rule.ID,
+			traceAlertRuleAuditDetails(&rule), c.IP(), c.Get("User-Agent"))
+
+		return c.Status(fiber.StatusCreated).JSON(rule)
+	}
+}
+
+// GetTraceAlertRule returns a single trace alert rule with its evaluation state
+func GetTraceAlertRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		rule, err := findTraceAlertRule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(rule)
+	}
+}
+
+// lockTraceAlertRule reloads a rule with its row locked until the transaction ends, so
+// edits apply to its current evaluation state
+func lockTraceAlertRule(tx *gorm.DB, ruleID uint) (*models.TraceAlertRule, error) {
+	var rule models.TraceAlertRule
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rule, ruleID).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateTraceAlertRule updates a trace alert rule's definition. An enabled rule is
+// evaluated right away and resolves on that evaluation if it is no longer breached;
+// disabling a firing rule resolves it immediately.
+func UpdateTraceAlertRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		rule, err := findTraceAlertRule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		var req TraceAlertRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			locked, err := lockTraceAlertRule(tx, rule.ID)
+			if err != nil {
+				return err
+			}
+			if err := applyTraceAlertRuleRequest(locked, req); err != nil {
+				return err
+			}
+			locked.NextEvaluationAt = time.Now()
+			fields := append([]string{"NextEvaluationAt"}, models.TraceAlertRuleDefinitionFields...)
+			if err := tx.Model(locked).Select(fields).Updates(locked).Error; err != nil {
+				return err
+			}
+			if !locked.Enabled {
+				if err := tracealerts.Resolve(tx, *locked, "Rule disabled"); err != nil {
+					return err
+				}
+				locked.Firing = false
+			}
+			rule = locked
+			return nil
+		})
+		if err != nil {
+			if _, ok := err.(*fiber.Error); ok {
+				return respondError(c, err)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update trace alert rule",
+			})
+		}
+
+		logAuditEvent(db, rule.OrgID, &userID, models.AuditActionTraceAlertRuleUpdated, "trace_alert_rule", &rule.ID,
+			traceAlertRuleAuditDetails(rule), c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(rule)
+	}
+}
+
+// DeleteTraceAlertRule removes a trace alert rule, resolving it if it is firing. Its
+// past alerts are kept.
+func DeleteTraceAlertRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("userID").(uint)
+
+		rule, err := findTraceAlertRule(c, db)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			locked, err := lockTraceAlertRule(tx, rule.ID)
+			if err != nil {
+				return err
+			}
+			if err := tracealerts.Resolve(tx, *locked, "Rule deleted"); err != nil {
+				return err
+			}
+			return tx.Delete(locked).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to delete trace alert rule",
+			})
+		}
+
+		logAuditEvent(db, rule.OrgID, &userID, models.AuditActionTraceAlertRuleDeleted, "trace_alert_rule", &rule.ID, models.JSONMap{
+			"name": rule.Name,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(fiber.Map{
+			"message": "trace alert rule deleted successfully",
+		})
+	}
+}
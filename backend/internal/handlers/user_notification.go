@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+type NotificationPreferencesRequest struct {
+	CheckIDs          *[]uint         `json:"check_ids,omitempty"`
+	GroupIDs          *[]uint         `json:"group_ids,omitempty"`
+	Tags              *models.JSONMap `json:"tags,omitempty"`
+	AlertTypes        *[]string       `json:"alert_types,omitempty"`
+	EmailEnabled      *bool           `json:"email_enabled,omitempty"`
+	WebhookURL        *string         `json:"webhook_url,omitempty"` // Empty clears it
+	QuietHoursEnabled *bool           `json:"quiet_hours_enabled,omitempty"`
+	QuietHoursStart   *string         `json:"quiet_hours_start,omitempty"` // HH:MM
+	QuietHoursEnd     *string         `json:"quiet_hours_end,omitempty"`   // HH:MM
+	Timezone          *string         `json:"timezone,omitempty"`          // Empty uses the org's timezone
+}
+
+// validateOrgResourceIDs dedupes ids and verifies every one belongs to the org
+func validateOrgResourceIDs(db *gorm.DB, model interface{}, orgID uint, ids []uint, name string) (pq.Int64Array, error) {
+	unique := map[uint]bool{}
+	result := pq.Int64Array{}
+	for _, id := range ids {
+		if !unique[id] {
+			unique[id] = true
+			result = append(result, int64(id))
+		}
+	}
+	if len(result) == 0 {
+		return result, nil
+	}
+	var count int64
+	if err := db.Model(model).Where("org_id = ? AND id IN ?", orgID, ids).Count(&count).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch "+name+"s")
+	}
+	if int(count) != len(result) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "one or more "+name+"s not found")
+	}
+	return result, nil
+}
+
+// loadNotificationPreferences returns the user's preferences, or the defaults if none are saved
+func loadNotificationPreferences(db *gorm.DB, orgID, userID uint) (*models.UserNotificationPreference, error) {
+	pref := models.UserNotificationPreference{
+		OrgID:        orgID,
+		UserID:       userID,
+		CheckIDs:     pq.Int64Array{},
+		GroupIDs:     pq.Int64Array{},
+		Tags:         models.JSONMap{},
+		AlertTypes:   pq.StringArray{},
+		EmailEnabled: true,
+	}
+	if err := db.Where("user_id = ?", userID).First(&pref).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch notification preferences")
+	}
+	return &pref, nil
+}
+
+// applyNotificationPreferencesRequest validates the request and copies set fields onto the preferences
+func applyNotificationPreferencesRequest(db *gorm.DB, pref *models.UserNotificationPreference, req NotificationPreferencesRequest) error {
+	if req.CheckIDs != nil {
+		ids, err := validateOrgResourceIDs(db, &models.Check{}, pref.OrgID, *req.CheckIDs, "check")
+		if err != nil {
+			return err
+		}
+		pref.CheckIDs = ids
+	}
+	if req.GroupIDs != nil {
+		ids, err := validateOrgResourceIDs(db, &models.CheckGroup{}, pref.OrgID, *req.GroupIDs, "check group")
+		if err != nil {
+			return err
+		}
+		pref.GroupIDs = ids
+	}
+	if req.Tags != nil {
+		pref.Tags = *req.Tags
+	}
+	if req.AlertTypes != nil {
+		pref.AlertTypes = cleanStrings(*req.AlertTypes, true)
+	}
+	if req.EmailEnabled != nil {
+		pref.EmailEnabled = *req.EmailEnabled
+	}
+	if req.WebhookURL != nil {
+		webhookURL := strings.TrimSpace(*req.WebhookURL)
+		if webhookURL != "" && !strings.HasPrefix(webhookURL, "http://") && !strings.HasPrefix(webhookURL, "https://") {
+			return fiber.NewError(fiber.StatusBadRequest, "webhook URL must start with http:// or https://")
+		}
+		pref.WebhookURL = webhookURL
+		if webhookURL == "" {
+			pref.WebhookSigningSecret = ""
+		} else if pref.WebhookSigningSecret == "" {
+			secret, err := models.GenerateSigningSecret()
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "failed to generate signing secret")
+			}
+			pref.WebhookSigningSecret = secret
+		}
+	}
+	if req.QuietHoursEnabled != nil {
+		pref.QuietHoursEnabled = *req.QuietHoursEnabled
+	}
+	if req.QuietHoursStart != nil {
+		pref.QuietHoursStart = strings.TrimSpace(*req.QuietHoursStart)
+	}
+	if req.QuietHoursEnd != nil {
+		pref.QuietHoursEnd = strings.TrimSpace(*req.QuietHoursEnd)
+	}
+	if pref.QuietHoursEnabled {
+		start, err := models.ParseClock(pref.QuietHoursStart)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "quiet_hours_start: "+err.Error())
+		}
+		end, err := models.ParseClock(pref.QuietHoursEnd)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "quiet_hours_end: "+err.Error())
+		}
+		if start == end {
+			return fiber.NewError(fiber.StatusBadRequest, "quiet hours must not start and end at the same time")
+		}
+	}
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if timezone != "" {
+			if _, err := time.LoadLocation(timezone); err != nil || strings.EqualFold(timezone, "local") {
+				return fiber.NewError(fiber.StatusBadRequest, "timezone must be a valid IANA timezone name")
+			}
+		}
+		pref.Timezone = timezone
+	}
+	return nil
+}
+
+// GetNotificationPreferences returns the current user's personal notification preferences
+func GetNotificationPreferences(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		pref, err := loadNotificationPreferences(db, orgID, userID)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(pref)
+	}
+}
+
+// UpdateNotificationPreferences saves the current user's subscriptions, channels and quiet hours
+func UpdateNotificationPreferences(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := c.Locals("orgID").(uint)
+		userID := c.Locals("userID").(uint)
+
+		var req NotificationPreferencesRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+
+		pref, err := loadNotificationPreferences(db, orgID, userID)
+		if err != nil {
+			return respondError(c, err)
+		}
+		if err := applyNotificationPreferencesRequest(db, pref, req); err != nil {
+			return respondError(c, err)
+		}
+		if err := db.Save(pref).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to update notification preferences",
+			})
+		}
+
+		logAuditEvent(db, orgID, &userID, models.AuditActionNotificationPreferencesUpdated, "notification_preferences", &pref.ID, models.JSONMap{
+			"check_ids":     pref.CheckIDs,
+			"group_ids":     pref.GroupIDs,
+			"tags":          pref.Tags,
+			"email_enabled": pref.EmailEnabled,
+			"webhook":       pref.WebhookURL != "",
+			"quiet_hours":   pref.QuietHoursEnabled,
+		}, c.IP(), c.Get("User-Agent"))
+
+		return c.JSON(pref)
+	}
+}
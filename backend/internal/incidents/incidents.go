@@ -0,0 +1,278 @@
+// Package incidents groups DOWN and RECOVERY alerts (and firing and resolved
+// external alerts) into incidents and tracks their lifecycle (triggered,
+// acknowledged, resolved).
+package incidents
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/oncall"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// findOpen locks and returns the check's open incident, or nil if there is none
+func findOpen(tx *gorm.DB, checkID uint) (*models.Incident, error) {
+	return findOpenWhere(tx, "check_id = ?", checkID)
+}
+
+// findOpenExternal locks and returns the external alert's open incident, or nil if there is none
+func findOpenExternal(tx *gorm.DB, externalAlertID uint) (*models.Incident, error) {
+	return findOpenWhere(tx, "external_alert_id = ?", externalAlertID)
+}
+
+// findOpenWhere locks and returns the latest open incident matching the condition
+func findOpenWhere(tx *gorm.DB, condition string, id uint) (*models.Incident, error) {
+	var incident models.Incident
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(condition, id).
+		Where("status <> ?", models.IncidentResolved).
+		Order("started_at DESC").
+		First(&incident).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// attachAlert links an alert to the incident and adds it to the timeline
+func attachAlert(tx *gorm.DB, incident *models.Incident, alert *models.Alert) error {
+	if err := tx.Model(alert).Update("incident_id", incident.ID).Error; err != nil {
+		return err
+	}
+	alert.IncidentID = &incident.ID
+	return tx.Create(&models.IncidentEvent{
+		IncidentID: incident.ID,
+		Kind:       models.IncidentEventAlert,
+		AlertID:    &alert.ID,
+		Body:       alert.ErrorMessage,
+	}).Error
+}
+
+// OpenForAlert opens an incident for a DOWN alert. If the check already has an
+// open incident (e.g. its RECOVERY was never seen) the alert joins that one instead.
+func OpenForAlert(db *gorm.DB, alert *models.Alert, check models.Check) (*models.Incident, error) {
+	if alert.AlertType != models.AlertTypeDown {
+		return nil, nil
+	}
+	var incident *models.Incident
+	err := db.Transaction(func(tx *gorm.DB) error {
+		open, err := findOpen(tx, check.ID)
+		if err != nil {
+			return err
+		}
+		if open == nil {
+			created := models.NewIncident(*alert, check)
+			if err := tx.Create(&created).Error; err != nil {
+				return err
+			}
+			open = &created
+		}
+		incident = open
+		return attachAlert(tx, incident, alert)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open incident: %w", err)
+	}
+	return incident, nil
+}
+
+// ResolveForCheck closes the check's open incident after it recovers.
+// recovery is the RECOVERY alert, or nil when that alert was suppressed.
+func ResolveForCheck(db *gorm.DB, checkID uint, recovery *models.Alert, at time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		incident, err := findOpen(tx, checkID)
+		if err != nil || incident == nil {
+			return err
+		}
+		if recovery != nil {
+			if err := attachAlert(tx, incident, recovery); err != nil {
+				return err
+			}
+		}
+		return resolve(tx, incident, at, nil)
+	})
+}
+
+// OpenForExternalAlert opens an incident for a firing external alert, or adds the
+// alert to the incident it already has open
+func OpenForExternalAlert(db *gorm.DB, alert *models.Alert, external models.ExternalAlert) (*models.Incident, error) {
+	var incident *models.Incident
+	err := db.Transaction(func(tx *gorm.DB) error {
+		open, err := findOpenExternal(tx, external.ID)
+		if err != nil {
+			return err
+		}
+		if open == nil {
+			created := models.NewExternalIncident(*alert, external)
+			if err := tx.Create(&created).Error; err != nil {
+				return err
+			}
+			open = &created
+		}
+		incident = open
+		return attachAlert(tx, incident, alert)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open incident: %w", err)
+	}
+	return incident, nil
+}
+
+// ResolveForExternalAlert closes the external alert's open incident once the
+// source reports it resolved, attaching the resolution alert
+func ResolveForExternalAlert(db *gorm.DB, externalAlertID uint, resolution *models.Alert, at time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		incident, err := findOpenExternal(tx, externalAlertID)
+		if err != nil || incident == nil {
+			return err
+		}
+		if resolution != nil {
+			if err := attachAlert(tx, incident, resolution); err != nil {
+				return err
+			}
+		}
+		return resolve(tx, incident, at, nil)
+	})
+}
+
+// resolve marks the incident resolved and records it on the timeline. The update only
+// applies while the incident is open, so a concurrent resolution is recorded once.
+func resolve(tx *gorm.DB, incident *models.Incident, at time.Time, userID *uint) error {
+	resolved := *incident
+	resolved.Resolve(at, userID)
+	result := tx.Model(&models.Incident{}).Where("id = ? AND status <> ?", incident.ID, models.IncidentResolved).Updates(map[string]interface{}{
+		"status":           resolved.Status,
+		"resolved_at":      resolved.ResolvedAt,
+		"resolved_by_id":   resolved.ResolvedByID,
+		"duration_seconds": resolved.DurationSeconds,
+	})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	*incident = resolved
+	return tx.Create(&models.IncidentEvent{
+		IncidentID: incident.ID,
+		Kind:       models.IncidentEventResolved,
+		UserID:     userID,
+	}).Error
+}
+
+// Acknowledge marks a triggered incident acknowledged and acknowledges its alerts,
+// which stops their escalation. Acknowledging twice, or concurrently, is a no-op.
+func Acknowledge(db *gorm.DB, incident *models.Incident, userID uint) error {
+	if incident.Status != models.IncidentTriggered {
+		return nil
+	}
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Incident{}).Where("id = ? AND status = ?", incident.ID, models.IncidentTriggered).Updates(map[string]interface{}{
+			"status":             models.IncidentAcknowledged,
+			"acknowledged_at":    now,
+			"acknowledged_by_id": userID,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		incident.Status = models.IncidentAcknowledged
+		incident.AcknowledgedAt = &now
+		incident.AcknowledgedByID = &userID
+
+		var alerts []models.Alert
+		if err := tx.Where("incident_id = ? AND alert_type = ? AND acknowledged_at IS NULL", incident.ID, models.AlertTypeDown).
+			Find(&alerts).Error; err != nil {
+			return err
+		}
+		for i := range alerts {
+			if err := oncall.Acknowledge(tx, &alerts[i], userID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&models.IncidentEvent{
+			IncidentID: incident.ID,
+			Kind:       models.IncidentEventAcknowledged,
+			UserID:     &userID,
+		}).Error
+	})
+}
+
+// Resolve closes an incident by hand and stops any escalation for its check
+func Resolve(db *gorm.DB, incident *models.Incident, userID uint) error {
+	if !incident.IsOpen() {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if incident.CheckID != nil {
+			if err := oncall.ResolveEscalations(tx, *incident.CheckID); err != nil {
+				return err
+			}
+		}
+		return resolve(tx, incident, time.Now(), &userID)
+	})
+}
+
+// Assign sets (or clears, with nil) the incident's assignee
+func Assign(db *gorm.DB, incident *models.Incident, assigneeID *uint, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(incident).Update("assignee_id", assigneeID).Error; err != nil {
+			return err
+		}
+		incident.AssigneeID = assigneeID
+		body := "unassigned"
+		if assigneeID != nil {
+			body = fmt.Sprintf("assigned to user %d", *assigneeID)
+		}
+		return tx.Create(&models.IncidentEvent{
+			IncidentID: incident.ID,
+			Kind:       models.IncidentEventAssigned,
+			UserID:     &userID,
+			Body:       body,
+		}).Error
+	})
+}
+
+// AddNote appends a note to the incident's timeline
+func AddNote(db *gorm.DB, incident *models.Incident, userID uint, body string) (*models.IncidentEvent, error) {
+	event := models.IncidentEvent{
+		IncidentID: incident.ID,
+		Kind:       models.IncidentEventNote,
+		UserID:     &userID,
+		Body:       body,
+	}
+	if err := db.Create(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Stats summarises incidents started within a window
+type Stats struct {
+	Total       int64    `json:"total"`
+	Open        int64    `json:"open"`
+	Resolved    int64    `json:"resolved"`
+	MTTRSeconds *float64 `json:"mttr_seconds"` // Mean time to resolve; nil without resolved incidents
+	MTTASeconds *float64 `json:"mtta_seconds"` // Mean time to acknowledge; nil without acknowledged incidents
+}
+
+// ComputeStats returns incident counts, MTTR and MTTA for incidents started since the cutoff.
+// Pass a check ID to limit it to one check.
+func ComputeStats(db *gorm.DB, orgID uint, checkID *uint, since time.Time) (Stats, error) {
+	var stats Stats
+	query := db.Model(&models.Incident{}).Where("org_id = ? AND started_at >= ?", orgID, since)
+	if checkID != nil {
+		query = query.Where("check_id = ?", *checkID)
+	}
+	err := query.Select(`COUNT(*) AS total,
+		COUNT(*) FILTER (WHERE status <> ?) AS open,
+		COUNT(*) FILTER (WHERE status = ?) AS resolved,
+		AVG(duration_seconds) AS mttr_seconds,
+		AVG(EXTRACT(EPOCH FROM acknowledged_at - started_at)) AS mtta_seconds`,
+		models.IncidentResolved, models.IncidentResolved).
+		Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,139 @@
+package logalerts
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+	"github.com/oFuterman/light-house/internal/schedule"
+	"github.com/oFuterman/light-house/internal/search"
+	"github.com/oFuterman/light-house/internal/utils"
+	"gorm.io/gorm"
+)
+
+// EvaluationInterval is how often each enabled rule is evaluated
+const EvaluationInterval = time.Minute
+
+// Count returns the number of an org's logs matching req between from and to
+func Count(db *gorm.DB, orgID uint, req search.SearchRequest, from, to time.Time) (int64, error) {
+	req.TimeRange = &search.TimeRange{From: &from, To: &to}
+	builder := search.NewQueryBuilder(db.Model(&models.LogEntry{}), "timestamp")
+	_, countQuery := builder.BuildWithCount(&req, orgID)
+	var count int64
+	err := countQuery.Count(&count).Error
+	return count, err
+}
+
+// Evaluate counts the logs matching a rule over its window ending at now. When the
+// outcome differs from the rule's firing state, it raises a LOG_ALERT or LOG_RESOLVED
+// alert and queues its notifications. rule.Organization must be loaded for the search link.
+func Evaluate(db *gorm.DB, rule models.LogAlertRule, now time.Time) error {
+	updates := map[string]interface{}{"last_evaluated_at": now}
+	fail := func(err error) error {
+		updates["last_error"] = utils.Truncate(err.Error(), 1024)
+		if updateErr := db.Model(&rule).Updates(updates).Error; updateErr != nil {
+			log.Printf("Error updating log alert rule %d: %v", rule.ID, updateErr)
+		}
+		return err
+	}
+
+	req, err := Query(rule)
+	if err != nil {
+		return fail(err)
+	}
+	from := now.Add(-rule.Window())
+	count, err := Count(db, rule.OrgID, req, from, now)
+	if err != nil {
+		return fail(err)
+	}
+	updates["last_count"] = count
+	updates["last_error"] = ""
+
+	breached := rule.Breached(count)
+	if breached == rule.Firing {
+		return db.Model(&rule).Updates(updates).Error
+	}
+
+	alertType := models.AlertTypeLogResolved
+	if breached {
+		alertType = models.AlertTypeLogAlert
+		updates["last_triggered_at"] = now
+	}
+	updates["firing"] = breached
+	alert := models.Alert{
+		OrgID:          rule.OrgID,
+		LogAlertRuleID: &rule.ID,
+		AlertType:      alertType,
+		ErrorMessage:   Describe(rule, count),
+		Link:           SearchPath(rule.Organization, req, from, now),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// The rule may have been resolved by disabling it since it was claimed
+		result := tx.Model(&rule).Where("firing = ?", rule.Firing).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errFiringChanged
+		}
+		if err := tx.Create(&alert).Error; err != nil {
+			return err
+		}
+		// Delivery happens in the notification dispatcher so failed sends are retried
+		_, err := notifier.EnqueueAlert(tx, alert, rule.Subject())
+		return err
+	})
+	if err == errFiringChanged {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	log.Printf("Alert created: log_rule=%d type=%s count=%d", rule.ID, alertType, count)
+	return nil
+}
+
+// errFiringChanged rolls back an evaluation whose rule changed firing state concurrently
+var errFiringChanged = errors.New("rule firing state changed during evaluation")
+
+// Resolve raises a LOG_RESOLVED alert for a firing rule that is being disabled or
+// deleted, since it won't be evaluated again, and clears its firing state. Call it in
+// the transaction that changes the rule, with the rule's row locked.
+func Resolve(tx *gorm.DB, rule models.LogAlertRule, reason string) error {
+	if !rule.Firing {
+		return nil
+	}
+	alert := models.Alert{
+		OrgID:          rule.OrgID,
+		LogAlertRuleID: &rule.ID,
+		AlertType:      models.AlertTypeLogResolved,
+		ErrorMessage:   reason,
+	}
+	if err := tx.Create(&alert).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&rule).Update("firing", false).Error; err != nil {
+		return err
+	}
+	_, err := notifier.EnqueueAlert(tx, alert, rule.Subject())
+	return err
+}
+
+// Rules are claimed and rescheduled by schedule.Due so replicas never evaluate one twice
+var due = schedule.Due[models.LogAlertRule]{
+	Column:   "next_evaluation_at",
+	Preload:  []string{"Organization"},
+	Interval: EvaluationInterval,
+	ID:       func(rule models.LogAlertRule) uint { return rule.ID },
+	Run: func(db *gorm.DB, rule models.LogAlertRule, now time.Time) {
+		if err := Evaluate(db, rule, now); err != nil {
+			log.Printf("Log alert rule %d for org %d failed: %v", rule.ID, rule.OrgID, err)
+		}
+	},
+}
+
+// ProcessDue evaluates up to limit enabled rules whose next evaluation has come
+func ProcessDue(db *gorm.DB, limit int) (int, error) {
+	return due.ProcessDue(db, limit)
+}
@@ -0,0 +1,115 @@
+package logalerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/search"
+	"github.com/oFuterman/light-house/internal/utils"
+)
+
+const (
+	// maxLinkLength is the size of the alert link column the search path is stored in
+	maxLinkLength = 2048
+	// maxSlugLength is the size of the organization slug column
+	maxSlugLength = 100
+)
+
+// Query decodes a rule's stored filter. Only filters and tags are kept; the
+// window is applied at evaluation time and sort and paging don't affect a count.
+func Query(rule models.LogAlertRule) (search.SearchRequest, error) {
+	var req search.SearchRequest
+	data, err := json.Marshal(rule.Query)
+	if err != nil {
+		return req, err
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return req, err
+	}
+	return search.SearchRequest{Filters: req.Filters, Tags: req.Tags}, nil
+}
+
+// Normalize validates a filter against the log search fields and returns it in
+// the form it is stored in
+func Normalize(req search.SearchRequest) (models.JSONMap, error) {
+	req = search.SearchRequest{Filters: req.Filters, Tags: req.Tags}
+	check := req
+	if err := search.ValidateLogsSearch(&check); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	// Alerts link to the filter's log search, which must fit the alert's link column
+	// for any org and window
+	longestOrg := models.Organization{Slug: strings.Repeat("x", maxSlugLength)}
+	longestTime := time.Date(2000, 1, 1, 0, 0, 0, 999999999, time.UTC)
+	if len(searchPath(longestOrg, req, longestTime, longestTime)) > maxLinkLength {
+		return nil, errors.New("filter is too long")
+	}
+	m := models.JSONMap{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SubjectFields returns the service and environment a filter pins with an
+// equality condition, so the rule's alerts can be routed like a check's
+func SubjectFields(req search.SearchRequest) (serviceName, environment string) {
+	for _, f := range req.Filters {
+		if f.Op != "=" && f.Op != "eq" {
+			continue
+		}
+		value, ok := f.Value.(string)
+		if !ok {
+			continue
+		}
+		switch f.Field {
+		case "service_name":
+			serviceName = value
+		case "environment":
+			environment = value
+		}
+	}
+	return serviceName, environment
+}
+
+// SearchPath is the dashboard path of the log search matching a rule over [from, to].
+// Filters too long to link to (only rules saved before Normalize checked the length)
+// link to the unfiltered logs instead.
+func SearchPath(org models.Organization, req search.SearchRequest, from, to time.Time) string {
+	path := searchPath(org, req, from, to)
+	if len(path) > maxLinkLength {
+		return fmt.Sprintf("/org/%s/logs", org.Slug)
+	}
+	return path
+}
+
+// searchPath builds the log search path without regard to its length
+func searchPath(org models.Organization, req search.SearchRequest, from, to time.Time) string {
+	from, to = from.UTC(), to.UTC()
+	req.TimeRange = &search.TimeRange{From: &from, To: &to}
+	data, _ := json.Marshal(req)
+	return fmt.Sprintf("/org/%s/logs?search=%s", org.Slug, url.QueryEscape(string(data)))
+}
+
+// Describe explains an evaluation, e.g. "73 matching logs in the last 5m (threshold: more than 50)"
+func Describe(rule models.LogAlertRule, count int64) string {
+	comparison := "more than"
+	if rule.Condition == models.LogAlertBelow {
+		comparison = "fewer than"
+	}
+	noun := "logs"
+	if count == 1 {
+		noun = "log"
+	}
+	return fmt.Sprintf("%d matching %s in the last %s (threshold: %s %d)",
+		count, noun, utils.FormatWindow(rule.WindowSeconds), comparison, rule.Threshold)
+}
@@ -245,10 +245,24 @@ func APIKeyAuth(db *gorm.DB) fiber.Handler {
 	}
 }
 
+// requestAPIKey returns the key from the X-API-Key header, falling back to
+// "Authorization: Bearer <key>" for senders like Alertmanager and Grafana that
+// can only set an authorization header
+func requestAPIKey(c *fiber.Ctx) string {
+	if apiKey := c.Get("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	parts := strings.Fields(c.Get("Authorization"))
+	if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+		return parts[1]
+	}
+	return ""
+}
+
 // APIKeyAuthWithScope validates API keys and checks for required scopes
 func APIKeyAuthWithScope(db *gorm.DB, requiredScopes ...models.APIKeyScope) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		apiKey := c.Get("X-API-Key")
+		apiKey := requestAPIKey(c)
 		if apiKey == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "missing X-API-Key header",
@@ -2,6 +2,8 @@ package models
 
 import (
     "time"
+
+    "github.com/lib/pq"
 )
 
 type AlertType string
@@ -9,17 +11,67 @@ type AlertType string
 const (
     AlertTypeDown     AlertType = "DOWN"
     AlertTypeRecovery AlertType = "RECOVERY"
+    // AlertTypeSecurityRegression fires when a check's security grade drops between runs
+    AlertTypeSecurityRegression AlertType = "SECURITY_REGRESSION"
+    // AlertTypeFlapping fires once when a check starts changing state too often;
+    // AlertTypeStabilized fires once when it settles again
+    AlertTypeFlapping   AlertType = "FLAPPING"
+    AlertTypeStabilized AlertType = "STABILIZED"
+    // AlertTypeTest labels synthetic alerts sent to verify channel configuration
+    AlertTypeTest AlertType = "TEST"
+    // AlertTypeLogAlert fires when a log alert rule's condition starts holding;
+    // AlertTypeLogResolved fires when it stops
+    AlertTypeLogAlert    AlertType = "LOG_ALERT"
+    AlertTypeLogResolved AlertType = "LOG_RESOLVED"
+    // AlertTypeTraceAlert and AlertTypeTraceResolved are the same for trace alert rules
+    AlertTypeTraceAlert    AlertType = "TRACE_ALERT"
+    AlertTypeTraceResolved AlertType = "TRACE_RESOLVED"
+    // AlertTypeLatencyAnomaly fires when a check's response time stays above its
+    // seasonal baseline; AlertTypeLatencyNormal fires when it returns to it
+    AlertTypeLatencyAnomaly AlertType = "LATENCY_ANOMALY"
+    AlertTypeLatencyNormal  AlertType = "LATENCY_NORMAL"
+    // AlertTypeExternalFiring and AlertTypeExternalResolved mirror alerts received
+    // from Prometheus Alertmanager or Grafana
+    AlertTypeExternalFiring   AlertType = "EXTERNAL_FIRING"
+    AlertTypeExternalResolved AlertType = "EXTERNAL_RESOLVED"
 )
 
+// IsCritical reports whether alerts of this type bypass users' quiet hours. Every
+// type that starts a problem does; resolutions and tests wait until the quiet hours end.
+func (t AlertType) IsCritical() bool {
+    switch t {
+    case AlertTypeDown, AlertTypeSecurityRegression, AlertTypeFlapping, AlertTypeLogAlert,
+        AlertTypeTraceAlert, AlertTypeLatencyAnomaly, AlertTypeExternalFiring:
+        return true
+    }
+    return false
+}
+
 type Alert struct {
     ID           uint      `gorm:"primarykey" json:"id"`
     CreatedAt    time.Time `json:"created_at" gorm:"index"`
     OrgID        uint      `gorm:"not null;index" json:"org_id"`
-    CheckID      uint      `gorm:"not null;index:idx_alerts_check_created" json:"check_id"`
+    CheckID      *uint     `gorm:"index:idx_alerts_check_created" json:"check_id"` // nil for log, trace and external alerts
     AlertType    AlertType `gorm:"not null;size:20;index" json:"alert_type"`
     StatusCode   int       `json:"status_code"`
     ErrorMessage string    `gorm:"size:1024" json:"error_message,omitempty"`
+    // Set for DOWN/RECOVERY and external alerts belonging to an incident
+    IncidentID *uint `gorm:"index" json:"incident_id,omitempty"`
+    // Acknowledgement stops escalation
+    AcknowledgedAt   *time.Time `json:"acknowledged_at,omitempty"`
+    AcknowledgedByID *uint      `json:"acknowledged_by_id,omitempty"`
+    // Set for alerts raised by a log alert rule, with the dashboard path of the matching search
+    LogAlertRuleID *uint  `gorm:"index" json:"log_alert_rule_id,omitempty"`
+    Link           string `gorm:"size:2048" json:"link,omitempty"`
+    // Set for alerts raised by a trace alert rule, with example slow or failing traces
+    TraceAlertRuleID *uint          `gorm:"index" json:"trace_alert_rule_id,omitempty"`
+    TraceIDs         pq.StringArray `gorm:"type:text[]" json:"trace_ids,omitempty"`
+    // Set for alerts received from Alertmanager or Grafana; Link is then the source's generator URL
+    ExternalAlertID *uint `gorm:"index" json:"external_alert_id,omitempty"`
     // Relations
-    Organization Organization `gorm:"foreignKey:OrgID" json:"organization,omitempty"`
-    Check        Check        `gorm:"foreignKey:CheckID" json:"check,omitempty"`
+    Organization   Organization    `gorm:"foreignKey:OrgID" json:"organization,omitempty"`
+    Check          Check           `gorm:"foreignKey:CheckID" json:"check,omitempty"`
+    LogAlertRule   *LogAlertRule   `gorm:"foreignKey:LogAlertRuleID" json:"-"`
+    TraceAlertRule *TraceAlertRule `gorm:"foreignKey:TraceAlertRuleID" json:"-"`
+    ExternalAlert  *ExternalAlert  `gorm:"foreignKey:ExternalAlertID" json:"-"`
 }
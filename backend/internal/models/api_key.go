@@ -23,6 +23,7 @@ const (
 
 	// Write scopes
 	ScopeChecksWrite APIKeyScope = "checks:write"
+	ScopeAlertsWrite APIKeyScope = "alerts:write" // Inbound Alertmanager and Grafana webhooks
 
 	// Full access
 	ScopeAll APIKeyScope = "*"
@@ -38,6 +39,7 @@ func AllScopes() []APIKeyScope {
 		ScopeChecksRead,
 		ScopeAlertsRead,
 		ScopeChecksWrite,
+		ScopeAlertsWrite,
 		ScopeAll,
 	}
 }
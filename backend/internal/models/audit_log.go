@@ -34,9 +34,74 @@ const (
 	AuditActionCheckCreated AuditAction = "check.created"
 	AuditActionCheckUpdated AuditAction = "check.updated"
 	AuditActionCheckDeleted AuditAction = "check.deleted"
+	AuditActionCheckBulk    AuditAction = "check.bulk_action"
+	AuditActionCheckRestored AuditAction = "check.restored"
+
+	// Check group actions
+	AuditActionCheckGroupCreated AuditAction = "check_group.created"
+	AuditActionCheckGroupUpdated AuditAction = "check_group.updated"
+	AuditActionCheckGroupDeleted AuditAction = "check_group.deleted"
+
+	// Maintenance window actions
+	AuditActionMaintenanceCreated AuditAction = "maintenance.created"
+	AuditActionMaintenanceDeleted AuditAction = "maintenance.deleted"
 
 	// Settings actions
 	AuditActionSettingsUpdated AuditAction = "settings.updated"
+
+	// Routing rule actions
+	AuditActionRoutingRuleCreated AuditAction = "routing_rule.created"
+	AuditActionRoutingRuleUpdated AuditAction = "routing_rule.updated"
+	AuditActionRoutingRuleDeleted AuditAction = "routing_rule.deleted"
+
+	// Log alert rule actions
+	AuditActionLogAlertRuleCreated AuditAction = "log_alert_rule.created"
+	AuditActionLogAlertRuleUpdated AuditAction = "log_alert_rule.updated"
+	AuditActionLogAlertRuleDeleted AuditAction = "log_alert_rule.deleted"
+
+	// Trace alert rule actions
+	AuditActionTraceAlertRuleCreated AuditAction = "trace_alert_rule.created"
+	AuditActionTraceAlertRuleUpdated AuditAction = "trace_alert_rule.updated"
+	AuditActionTraceAlertRuleDeleted AuditAction = "trace_alert_rule.deleted"
+
+	// Alert actions
+	AuditActionAlertRedelivered  AuditAction = "alert.redelivered"
+	AuditActionAlertAcknowledged AuditAction = "alert.acknowledged"
+
+	// Incident actions
+	AuditActionIncidentAcknowledged AuditAction = "incident.acknowledged"
+	AuditActionIncidentResolved     AuditAction = "incident.resolved"
+	AuditActionIncidentAssigned     AuditAction = "incident.assigned"
+
+	// On-call schedule actions
+	AuditActionOnCallScheduleCreated AuditAction = "oncall_schedule.created"
+	AuditActionOnCallScheduleUpdated AuditAction = "oncall_schedule.updated"
+	AuditActionOnCallScheduleDeleted AuditAction = "oncall_schedule.deleted"
+
+	// Escalation policy actions
+	AuditActionEscalationPolicyCreated AuditAction = "escalation_policy.created"
+	AuditActionEscalationPolicyUpdated AuditAction = "escalation_policy.updated"
+	AuditActionEscalationPolicyDeleted AuditAction = "escalation_policy.deleted"
+
+	// Notification channel actions
+	AuditActionNotificationChannelCreated       AuditAction = "notification_channel.created"
+	AuditActionNotificationChannelUpdated       AuditAction = "notification_channel.updated"
+	AuditActionNotificationChannelDeleted       AuditAction = "notification_channel.deleted"
+	AuditActionNotificationChannelSecretRotated AuditAction = "notification_channel.secret_rotated"
+
+	// Notification template actions
+	AuditActionNotificationTemplatesUpdated AuditAction = "notification_template.updated"
+
+	// Notification test actions
+	AuditActionNotificationTestSent AuditAction = "notification.test_sent"
+
+	// Personal notification preference actions
+	AuditActionNotificationPreferencesUpdated AuditAction = "notification_preferences.updated"
+
+	// Digest subscription actions
+	AuditActionDigestSubscriptionCreated AuditAction = "digest_subscription.created"
+	AuditActionDigestSubscriptionUpdated AuditAction = "digest_subscription.updated"
+	AuditActionDigestSubscriptionDeleted AuditAction = "digest_subscription.deleted"
 )
 
 // AuditLog records security-relevant events for compliance and debugging
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// BrokenLink is a page found by a crawl check that returned 4xx/5xx or failed to load
+type BrokenLink struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"` // 0 when the request failed (timeout, DNS, ...)
+	Error      string `json:"error,omitempty"`
+	FoundOn    string `json:"found_on,omitempty"` // Page that linked to the broken URL
+}
+
+// BrokenLinks is stored as a JSONB array on check results
+type BrokenLinks []BrokenLink
+
+func (b BrokenLinks) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return json.Marshal(b)
+}
+
+func (b *BrokenLinks) Scan(value interface{}) error {
+	if value == nil {
+		*b = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, b)
+}
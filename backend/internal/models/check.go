@@ -5,6 +5,41 @@ import (
     "gorm.io/gorm"
 )
 
+// Check types
+const (
+    CheckTypeHTTP  = "http"  // Single request to the check URL
+    CheckTypeCrawl = "crawl" // Follows same-origin links from the check URL looking for broken pages
+)
+
+// Crawl limits
+const (
+    DefaultCrawlMaxDepth = 2
+    MaxCrawlDepth        = 5
+    DefaultCrawlMaxPages = 50
+    MaxCrawlPages        = 200
+    // MinCrawlIntervalSeconds keeps a crawl check from coming due again while its
+    // previous run (page fetch plus the worker's two-minute crawl budget) is still going
+    MinCrawlIntervalSeconds = 300
+)
+
+// Alert noise control
+const (
+    DefaultAlertSuppressionSeconds = 15 * 60
+    MaxAlertSuppressionSeconds     = 24 * 60 * 60
+    DefaultFlapThresholdPercent    = 50
+    // FlapWindowResults is how many recent results the state-change rate is measured over
+    FlapWindowResults = 11
+)
+
+// Response time anomaly detection
+const (
+    DefaultAnomalySensitivity     = 3.0 // Standard deviations above the baseline
+    MinAnomalySensitivity         = 1.0
+    MaxAnomalySensitivity         = 10.0
+    DefaultAnomalyConsecutiveRuns = 3
+    MaxAnomalyConsecutiveRuns     = 20
+)
+
 type Check struct {
     ID        uint           `gorm:"primarykey" json:"id"`
     CreatedAt time.Time      `json:"created_at"`
@@ -17,7 +52,31 @@ type Check struct {
     LastStatus      *int       `json:"last_status"`
     LastCheckedAt   *time.Time `json:"last_checked_at"`
     LastAlertAt     *time.Time `json:"last_alert_at"`
+    LastSuccess     *bool      `json:"last_success"`
     IsActive        bool       `gorm:"default:true" json:"is_active"`
+    GroupID         *uint      `gorm:"index" json:"group_id"`
+    // Check type and crawl settings (crawl settings only apply to crawl checks)
+    CheckType     string `gorm:"size:20;not null;default:'http'" json:"check_type"`
+    CrawlMaxDepth int    `gorm:"not null;default:2" json:"crawl_max_depth"`
+    CrawlMaxPages int    `gorm:"not null;default:50" json:"crawl_max_pages"`
+    // Escalation policy applied to DOWN alerts (optional)
+    EscalationPolicyID *uint `gorm:"index" json:"escalation_policy_id"`
+    // Alert noise control (nil uses the defaults). Transitions inside the suppression
+    // window are deferred rather than dropped; a flap threshold of 0 disables flap detection.
+    AlertSuppressionSeconds *int       `json:"alert_suppression_seconds"`
+    FlapThresholdPercent    *int       `json:"flap_threshold_percent"`
+    IsFlapping              bool       `gorm:"not null;default:false" json:"is_flapping"`
+    FlappingSince           *time.Time `json:"flapping_since,omitempty"`
+    LastNotifiedUp          *bool      `json:"-"` // State announced by the last DOWN/RECOVERY alert
+    // Response time anomaly detection against the check's hour-of-week baseline (nil uses the defaults)
+    AnomalyDetection       bool     `gorm:"not null;default:false" json:"anomaly_detection"`
+    AnomalySensitivity     *float64 `json:"anomaly_sensitivity"`
+    AnomalyConsecutiveRuns *int     `json:"anomaly_consecutive_runs"`
+    IsAnomalous            bool     `gorm:"not null;default:false" json:"is_anomalous"`
+    AnomalyStreak          int      `gorm:"not null;default:0" json:"-"` // Consecutive runs disagreeing with IsAnomalous
+    // Security header audit (HTTP checks only)
+    SecurityAudit     bool   `gorm:"default:false" json:"security_audit"`
+    LastSecurityGrade string `gorm:"size:2" json:"last_security_grade,omitempty"`
     // Observability fields
     ServiceName string  `gorm:"size:255;index" json:"service_name,omitempty"`
     Environment string  `gorm:"size:50;index" json:"environment,omitempty"`
@@ -25,5 +84,38 @@ type Check struct {
     Tags        JSONMap `gorm:"type:jsonb" json:"tags,omitempty"`
     // Relations
     Organization Organization  `gorm:"foreignKey:OrgID" json:"organization,omitempty"`
+    Group        *CheckGroup   `gorm:"foreignKey:GroupID" json:"-"`
     Results      []CheckResult `gorm:"foreignKey:CheckID" json:"results,omitempty"`
 }
+
+// SuppressionWindow is the minimum time between DOWN/RECOVERY alerts for the check
+func (c *Check) SuppressionWindow() time.Duration {
+    if c.AlertSuppressionSeconds == nil {
+        return DefaultAlertSuppressionSeconds * time.Second
+    }
+    return time.Duration(*c.AlertSuppressionSeconds) * time.Second
+}
+
+// AnomalyThreshold is how many standard deviations above the baseline counts as anomalous
+func (c *Check) AnomalyThreshold() float64 {
+    if c.AnomalySensitivity == nil {
+        return DefaultAnomalySensitivity
+    }
+    return *c.AnomalySensitivity
+}
+
+// AnomalyRuns is how many consecutive runs must agree before the anomaly state changes
+func (c *Check) AnomalyRuns() int {
+    if c.AnomalyConsecutiveRuns == nil {
+        return DefaultAnomalyConsecutiveRuns
+    }
+    return *c.AnomalyConsecutiveRuns
+}
+
+// FlapThreshold is the state-change percentage at which the check counts as flapping (0 = disabled)
+func (c *Check) FlapThreshold() int {
+    if c.FlapThresholdPercent == nil {
+        return DefaultFlapThresholdPercent
+    }
+    return *c.FlapThresholdPercent
+}
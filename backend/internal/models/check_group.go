@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GroupAggregation controls how a group's status is derived from its members
+type GroupAggregation string
+
+const (
+	// GroupAggregationWorstOf reports the worst member status
+	GroupAggregationWorstOf GroupAggregation = "worst_of"
+	// GroupAggregationThreshold reports DOWN once ThresholdPercent of members are down
+	GroupAggregationThreshold GroupAggregation = "threshold"
+)
+
+// IsValid checks if the aggregation mode is a valid value
+func (a GroupAggregation) IsValid() bool {
+	switch a {
+	case GroupAggregationWorstOf, GroupAggregationThreshold:
+		return true
+	}
+	return false
+}
+
+// GroupStatus is the aggregate status of a check group
+type GroupStatus string
+
+const (
+	GroupStatusUp       GroupStatus = "up"
+	GroupStatusDegraded GroupStatus = "degraded"
+	GroupStatusDown     GroupStatus = "down"
+	GroupStatusUnknown  GroupStatus = "unknown"
+)
+
+// CheckGroup is a folder of checks. Groups nest through ParentID.
+type CheckGroup struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	OrgID       uint   `gorm:"not null;index" json:"org_id"`
+	ParentID    *uint  `gorm:"index" json:"parent_id"`
+	Name        string `gorm:"not null;size:255" json:"name"`
+	Description string `gorm:"size:1024" json:"description,omitempty"`
+
+	// Aggregation settings
+	Aggregation      GroupAggregation `gorm:"not null;size:20;default:'worst_of'" json:"aggregation"`
+	ThresholdPercent int              `gorm:"not null;default:50" json:"threshold_percent"` // Only used by threshold aggregation
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+	Parent       *CheckGroup  `gorm:"foreignKey:ParentID" json:"-"`
+}
@@ -12,6 +12,12 @@ type CheckResult struct {
     ResponseTimeMs int64  `json:"response_time_ms"`
     Success        bool   `json:"success"`
     ErrorMessage   string `gorm:"size:1024" json:"error_message,omitempty"`
+    // Crawl checks only: pages visited and the ones that failed
+    PagesCrawled int         `json:"pages_crawled,omitempty"`
+    BrokenLinks  BrokenLinks `gorm:"type:jsonb" json:"broken_links,omitempty"`
+    // Security header audit (only set when the check has SecurityAudit enabled)
+    SecurityGrade    string           `gorm:"size:2" json:"security_grade,omitempty"`
+    SecurityFindings SecurityFindings `gorm:"type:jsonb" json:"security_findings,omitempty"`
     // Observability fields (denormalized for efficient querying)
     OrgID       uint    `gorm:"index" json:"org_id"`
     ServiceName string  `gorm:"size:255;index" json:"service_name,omitempty"`
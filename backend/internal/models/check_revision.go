@@ -0,0 +1,152 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// CheckRevisionAction describes what produced a revision
+type CheckRevisionAction string
+
+const (
+	CheckRevisionCreated  CheckRevisionAction = "created"
+	CheckRevisionUpdated  CheckRevisionAction = "updated"
+	CheckRevisionRestored CheckRevisionAction = "restored"
+	CheckRevisionDeleted  CheckRevisionAction = "deleted"
+)
+
+// CheckConfig is the user-editable configuration of a check.
+// Revisions snapshot exactly these fields; runtime state like LastStatus is excluded.
+type CheckConfig struct {
+	Name                    string   `json:"name"`
+	URL                     string   `json:"url"`
+	IntervalSeconds         int      `json:"interval_seconds"`
+	IsActive                bool     `json:"is_active"`
+	GroupID                 *uint    `json:"group_id"`
+	CheckType               string   `json:"check_type"`
+	CrawlMaxDepth           int      `json:"crawl_max_depth"`
+	CrawlMaxPages           int      `json:"crawl_max_pages"`
+	EscalationPolicyID      *uint    `json:"escalation_policy_id"`
+	AlertSuppressionSeconds *int     `json:"alert_suppression_seconds"`
+	FlapThresholdPercent    *int     `json:"flap_threshold_percent"`
+	AnomalyDetection        bool     `json:"anomaly_detection"`
+	AnomalySensitivity      *float64 `json:"anomaly_sensitivity"`
+	AnomalyConsecutiveRuns  *int     `json:"anomaly_consecutive_runs"`
+	SecurityAudit           bool     `json:"security_audit"`
+	ServiceName             string   `json:"service_name"`
+	Environment             string   `json:"environment"`
+	Region                  string   `json:"region"`
+	Tags                    JSONMap  `json:"tags"`
+}
+
+// CheckConfigFields are the Check fields holding its configuration. Edits save only
+// these so runtime state owned by the check runner is never written back stale.
+var CheckConfigFields = []string{
+	"Name", "URL", "IntervalSeconds", "IsActive", "GroupID", "CheckType", "CrawlMaxDepth",
+	"CrawlMaxPages", "EscalationPolicyID", "AlertSuppressionSeconds", "FlapThresholdPercent",
+	"AnomalyDetection", "AnomalySensitivity", "AnomalyConsecutiveRuns", "SecurityAudit",
+	"ServiceName", "Environment", "Region", "Tags",
+}
+
+// Config extracts the editable configuration from a check
+func (c *Check) Config() CheckConfig {
+	return CheckConfig{
+		Name:                    c.Name,
+		URL:                     c.URL,
+		IntervalSeconds:         c.IntervalSeconds,
+		IsActive:                c.IsActive,
+		GroupID:                 c.GroupID,
+		CheckType:               c.CheckType,
+		CrawlMaxDepth:           c.CrawlMaxDepth,
+		CrawlMaxPages:           c.CrawlMaxPages,
+		EscalationPolicyID:      c.EscalationPolicyID,
+		AlertSuppressionSeconds: c.AlertSuppressionSeconds,
+		FlapThresholdPercent:    c.FlapThresholdPercent,
+		AnomalyDetection:        c.AnomalyDetection,
+		AnomalySensitivity:      c.AnomalySensitivity,
+		AnomalyConsecutiveRuns:  c.AnomalyConsecutiveRuns,
+		SecurityAudit:           c.SecurityAudit,
+		ServiceName:             c.ServiceName,
+		Environment:             c.Environment,
+		Region:                  c.Region,
+		Tags:                    c.Tags,
+	}
+}
+
+// ApplyConfig overwrites the check's editable configuration
+func (c *Check) ApplyConfig(cfg CheckConfig) {
+	c.Name = cfg.Name
+	c.URL = cfg.URL
+	c.IntervalSeconds = cfg.IntervalSeconds
+	c.IsActive = cfg.IsActive
+	c.GroupID = cfg.GroupID
+	c.CheckType = cfg.CheckType
+	c.CrawlMaxDepth = cfg.CrawlMaxDepth
+	c.CrawlMaxPages = cfg.CrawlMaxPages
+	c.EscalationPolicyID = cfg.EscalationPolicyID
+	c.AlertSuppressionSeconds = cfg.AlertSuppressionSeconds
+	c.FlapThresholdPercent = cfg.FlapThresholdPercent
+	c.AnomalyDetection = cfg.AnomalyDetection
+	c.AnomalySensitivity = cfg.AnomalySensitivity
+	c.AnomalyConsecutiveRuns = cfg.AnomalyConsecutiveRuns
+	c.SecurityAudit = cfg.SecurityAudit
+	c.ServiceName = cfg.ServiceName
+	c.Environment = cfg.Environment
+	c.Region = cfg.Region
+	c.Tags = cfg.Tags
+}
+
+// ToMap converts the config into a generic map for JSONB storage
+func (cfg CheckConfig) ToMap() JSONMap {
+	data, _ := json.Marshal(cfg)
+	m := JSONMap{}
+	_ = json.Unmarshal(data, &m)
+	return m
+}
+
+// CheckConfigFromMap decodes a stored snapshot back into a CheckConfig
+func CheckConfigFromMap(m JSONMap) (CheckConfig, error) {
+	var cfg CheckConfig
+	data, err := json.Marshal(m)
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+// DiffCheckConfig returns field -> {"from": old, "to": new} for every field that changed
+func DiffCheckConfig(before, after CheckConfig) JSONMap {
+	beforeMap := before.ToMap()
+	afterMap := after.ToMap()
+	diff := JSONMap{}
+	for key, newValue := range afterMap {
+		oldValue := beforeMap[key]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			diff[key] = map[string]interface{}{
+				"from": oldValue,
+				"to":   newValue,
+			}
+		}
+	}
+	return diff
+}
+
+// CheckRevision is an immutable snapshot of a check's configuration after a change
+type CheckRevision struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OrgID        uint                `gorm:"not null;index" json:"org_id"`
+	CheckID      uint                `gorm:"not null;uniqueIndex:idx_check_revisions_check_version,priority:1" json:"check_id"`
+	Version      int                 `gorm:"not null;uniqueIndex:idx_check_revisions_check_version,priority:2" json:"version"`
+	Action       CheckRevisionAction `gorm:"not null;size:20" json:"action"`
+	AuthorID     *uint               `json:"author_id,omitempty"` // Nullable for system changes
+	Config       JSONMap             `gorm:"type:jsonb;not null" json:"config"`
+	Changes      JSONMap             `gorm:"type:jsonb" json:"changes,omitempty"`
+	RestoredFrom *int                `json:"restored_from,omitempty"` // Version that was restored
+
+	// Relations
+	Author *User `gorm:"foreignKey:AuthorID" json:"-"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// DigestFrequency is how often a digest is sent
+type DigestFrequency string
+
+const (
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// Period is the reporting window a digest covers
+func (f DigestFrequency) Period() time.Duration {
+	if f == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// DigestSubscription sends an uptime summary to an email channel on a schedule
+// evaluated in the org's timezone
+type DigestSubscription struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrgID      uint            `gorm:"not null;index" json:"org_id"`
+	ChannelID  uint            `gorm:"not null;index" json:"channel_id"` // Email notification channel
+	Frequency  DigestFrequency `gorm:"not null;size:10" json:"frequency"`
+	Weekday    int             `gorm:"not null;default:1" json:"weekday"` // 0 = Sunday; weekly digests only
+	Hour       int             `gorm:"not null;default:9" json:"hour"`    // Local hour of day, 0-23
+	Enabled    bool            `gorm:"not null" json:"enabled"`
+	NextRunAt  time.Time       `gorm:"not null;index" json:"next_run_at"`
+	LastSentAt *time.Time      `json:"last_sent_at,omitempty"`
+	LastError  string          `gorm:"size:1024" json:"last_error,omitempty"`
+
+	// Relations
+	Organization Organization        `gorm:"foreignKey:OrgID" json:"-"`
+	Channel      NotificationChannel `gorm:"foreignKey:ChannelID" json:"-"`
+}
+
+// NextRun returns the first scheduled time strictly after `after`, in loc.
+// time.Date normalises wall-clock times across DST changes.
+func (s *DigestSubscription) NextRun(loc *time.Location, after time.Time) time.Time {
+	local := after.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, loc)
+	if s.Frequency == DigestWeekly {
+		offset := (s.Weekday - int(next.Weekday()) + 7) % 7
+		next = time.Date(next.Year(), next.Month(), next.Day()+offset, s.Hour, 0, 0, 0, loc)
+	}
+	step := 1
+	if s.Frequency == DigestWeekly {
+		step = 7
+	}
+	for !next.After(after) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+step, s.Hour, 0, 0, 0, loc)
+	}
+	return next
+}
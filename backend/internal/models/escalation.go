@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// EscalationPolicy notifies its levels in order until the alert is acknowledged
+type EscalationPolicy struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	OrgID       uint   `gorm:"not null;index" json:"org_id"`
+	Name        string `gorm:"not null;size:255" json:"name"`
+	Description string `gorm:"size:1024" json:"description,omitempty"`
+
+	// Relations
+	Organization Organization      `gorm:"foreignKey:OrgID" json:"-"`
+	Levels       []EscalationLevel `gorm:"foreignKey:PolicyID" json:"levels"`
+}
+
+// EscalationLevel is one step of a policy. Its targets are notified when the level
+// is reached; if nobody acknowledges within EscalateAfterMinutes the next level is notified.
+type EscalationLevel struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	PolicyID             uint          `gorm:"not null;uniqueIndex:idx_escalation_levels_policy_level,priority:1" json:"policy_id"`
+	Level                int           `gorm:"not null;uniqueIndex:idx_escalation_levels_policy_level,priority:2" json:"level"` // 1-based
+	EscalateAfterMinutes int           `gorm:"not null;default:15" json:"escalate_after_minutes"`
+	UserIDs              pq.Int64Array `gorm:"type:bigint[]" json:"user_ids"`
+	ScheduleIDs          pq.Int64Array `gorm:"type:bigint[]" json:"schedule_ids"` // Whoever is on call when the level is reached
+}
+
+// AlertEscalationStatus tracks where an alert is in its escalation policy
+type AlertEscalationStatus string
+
+const (
+	EscalationActive       AlertEscalationStatus = "active"
+	EscalationAcknowledged AlertEscalationStatus = "acknowledged"
+	EscalationResolved     AlertEscalationStatus = "resolved"  // The check recovered
+	EscalationExhausted    AlertEscalationStatus = "exhausted" // Every level was notified without an acknowledgement
+)
+
+// AlertEscalation is the escalation state of one DOWN alert. The escalation worker
+// advances CurrentLevel whenever NextEscalationAt passes while the status is active.
+type AlertEscalation struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrgID            uint                  `gorm:"not null;index" json:"org_id"`
+	AlertID          uint                  `gorm:"not null;uniqueIndex" json:"alert_id"`
+	CheckID          uint                  `gorm:"not null;index" json:"check_id"`
+	PolicyID         uint                  `gorm:"not null;index" json:"policy_id"`
+	Status           AlertEscalationStatus `gorm:"not null;size:20;index" json:"status"`
+	CurrentLevel     int                   `gorm:"not null;default:0" json:"current_level"` // 0 until level 1 is notified
+	NextEscalationAt *time.Time            `gorm:"index" json:"next_escalation_at,omitempty"`
+
+	// Relations
+	Alert  Alert            `gorm:"foreignKey:AlertID" json:"-"`
+	Policy EscalationPolicy `gorm:"foreignKey:PolicyID" json:"-"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// ExternalAlertSource is the system that sent an external alert
+type ExternalAlertSource string
+
+const (
+	ExternalSourceAlertmanager ExternalAlertSource = "alertmanager"
+	ExternalSourceGrafana      ExternalAlertSource = "grafana"
+)
+
+// ExternalAlert is the latest known state of an alert received from Prometheus
+// Alertmanager or Grafana. One row is kept per alert fingerprint, so repeated
+// notifications for an alert that is still firing don't alert again.
+type ExternalAlert struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrgID        uint                `gorm:"not null;uniqueIndex:idx_external_alerts_fingerprint,priority:1" json:"org_id"`
+	Source       ExternalAlertSource `gorm:"not null;size:20;uniqueIndex:idx_external_alerts_fingerprint,priority:2" json:"source"`
+	Fingerprint  string              `gorm:"not null;size:128;uniqueIndex:idx_external_alerts_fingerprint,priority:3" json:"fingerprint"`
+	Name         string              `gorm:"not null;size:255" json:"name"` // The alertname label
+	ServiceName  string              `gorm:"size:255;index" json:"service_name,omitempty"`
+	Environment  string              `gorm:"size:50;index" json:"environment,omitempty"`
+	Labels       JSONMap             `gorm:"type:jsonb" json:"labels,omitempty"`
+	Annotations  JSONMap             `gorm:"type:jsonb" json:"annotations,omitempty"`
+	GeneratorURL string              `gorm:"size:2048" json:"generator_url,omitempty"`
+	Firing       bool                `gorm:"not null;default:false" json:"firing"`
+	StartsAt     time.Time           `json:"starts_at"`
+	EndsAt       *time.Time          `json:"ends_at,omitempty"`           // Set once resolved
+	ReceivedAt   time.Time           `gorm:"not null" json:"received_at"` // Last notification for this alert
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+}
+
+// Subject stands in for a check when routing and rendering the alert's notifications;
+// labels become tags so routing rules can match on them
+func (e *ExternalAlert) Subject() Check {
+	return Check{
+		OrgID:       e.OrgID,
+		Name:        e.Name,
+		ServiceName: e.ServiceName,
+		Environment: e.Environment,
+		Tags:        e.Labels,
+	}
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+)
+
+// IncidentStatus is where an incident is in its lifecycle
+type IncidentStatus string
+
+const (
+	IncidentTriggered    IncidentStatus = "triggered"
+	IncidentAcknowledged IncidentStatus = "acknowledged"
+	IncidentResolved     IncidentStatus = "resolved"
+)
+
+// Incident groups a check's outage: it opens with a DOWN alert and closes with
+// the following RECOVERY (or a manual resolve). A check has at most one open incident.
+// Incidents for alerts from Alertmanager or Grafana belong to the external alert
+// instead, opening when it fires and closing when it resolves.
+type Incident struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrgID            uint           `gorm:"not null;index:idx_incidents_org_started,priority:1" json:"org_id"`
+	CheckID          *uint          `gorm:"index" json:"check_id"`
+	ExternalAlertID  *uint          `gorm:"index" json:"external_alert_id,omitempty"`
+	Title            string         `gorm:"not null;size:512" json:"title"`
+	Status           IncidentStatus `gorm:"not null;size:20;index" json:"status"`
+	StartedAt        time.Time      `gorm:"not null;index:idx_incidents_org_started,priority:2" json:"started_at"`
+	AcknowledgedAt   *time.Time     `json:"acknowledged_at,omitempty"`
+	AcknowledgedByID *uint          `json:"acknowledged_by_id,omitempty"`
+	ResolvedAt       *time.Time     `json:"resolved_at,omitempty"`
+	ResolvedByID     *uint          `json:"resolved_by_id,omitempty"` // nil when resolved by the check recovering or the external alert resolving
+	AssigneeID       *uint          `json:"assignee_id,omitempty"`
+	DurationSeconds  *int64         `json:"duration_seconds,omitempty"` // Set when resolved
+
+	// Relations
+	Organization  Organization   `gorm:"foreignKey:OrgID" json:"-"`
+	Check         Check          `gorm:"foreignKey:CheckID" json:"-"`
+	ExternalAlert *ExternalAlert `gorm:"foreignKey:ExternalAlertID" json:"-"`
+}
+
+// IsOpen reports whether the incident has not been resolved yet
+func (i *Incident) IsOpen() bool {
+	return i.Status != IncidentResolved
+}
+
+// IncidentEventKind identifies a timeline entry
+type IncidentEventKind string
+
+const (
+	IncidentEventAlert        IncidentEventKind = "alert"
+	IncidentEventNote         IncidentEventKind = "note"
+	IncidentEventAcknowledged IncidentEventKind = "acknowledged"
+	IncidentEventAssigned     IncidentEventKind = "assigned"
+	IncidentEventResolved     IncidentEventKind = "resolved"
+)
+
+// IncidentEvent is one entry of an incident's timeline
+type IncidentEvent struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	IncidentID uint              `gorm:"not null;index" json:"incident_id"`
+	Kind       IncidentEventKind `gorm:"not null;size:20" json:"kind"`
+	AlertID    *uint             `json:"alert_id,omitempty"`
+	UserID     *uint             `json:"user_id,omitempty"` // nil for system events
+	Body       string            `gorm:"type:text" json:"body,omitempty"`
+
+	// Relations
+	Incident Incident `gorm:"foreignKey:IncidentID" json:"-"`
+}
+
+// NewIncident builds the incident a DOWN alert opens
+func NewIncident(alert Alert, check Check) Incident {
+	return Incident{
+		OrgID:     alert.OrgID,
+		CheckID:   &check.ID,
+		Title:     check.Name + " is down",
+		Status:    IncidentTriggered,
+		StartedAt: alert.CreatedAt,
+	}
+}
+
+// Resolve closes the incident at the given time. resolvedBy is nil for automatic resolution.
+func (i *Incident) Resolve(at time.Time, resolvedBy *uint) {
+	duration := int64(at.Sub(i.StartedAt).Seconds())
+	if duration < 0 {
+		duration = 0
+	}
+	i.Status = IncidentResolved
+	i.ResolvedAt = &at
+	i.ResolvedByID = resolvedBy
+	i.DurationSeconds = &duration
+}
+
+// NewExternalIncident builds the incident a firing external alert opens
+func NewExternalIncident(alert Alert, external ExternalAlert) Incident {
+	return Incident{
+		OrgID:           alert.OrgID,
+		ExternalAlertID: &external.ID,
+		Title:           external.Name + " is firing",
+		Status:          IncidentTriggered,
+		StartedAt:       alert.CreatedAt,
+	}
+}
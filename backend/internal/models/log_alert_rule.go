@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LogAlertCondition is how a log alert rule compares the matching log count to its threshold
+type LogAlertCondition string
+
+const (
+	// LogAlertAbove fires when more than Threshold logs match within the window
+	LogAlertAbove LogAlertCondition = "above"
+	// LogAlertBelow fires when fewer than Threshold logs match; a threshold of 1 alerts on silence
+	LogAlertBelow LogAlertCondition = "below"
+)
+
+// Log alert rule limits
+const (
+	DefaultLogAlertWindowSeconds = 5 * 60
+	MinLogAlertWindowSeconds     = 60
+	MaxLogAlertWindowSeconds     = 24 * 60 * 60
+)
+
+// LogAlertRule raises an alert when the number of log entries matching a search
+// filter crosses a threshold over a trailing window, and a resolution once it no longer does
+type LogAlertRule struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Kept so queued notifications still render
+
+	OrgID         uint              `gorm:"not null;index" json:"org_id"`
+	Name          string            `gorm:"not null;size:255" json:"name"`
+	Enabled       bool              `gorm:"not null" json:"enabled"`
+	Query         JSONMap           `gorm:"type:jsonb;not null" json:"query"` // search.SearchRequest filters and tags; time range, sort and paging are ignored
+	Condition     LogAlertCondition `gorm:"not null;size:10" json:"condition"`
+	Threshold     int64             `gorm:"not null" json:"threshold"`
+	WindowSeconds int               `gorm:"not null" json:"window_seconds"`
+	CreatedByID   *uint             `json:"created_by_id,omitempty"`
+
+	// Used to route alerts like a check's; taken from equality filters in the query
+	ServiceName string `gorm:"size:255" json:"service_name,omitempty"`
+	Environment string `gorm:"size:50" json:"environment,omitempty"`
+
+	// Evaluation state
+	Firing           bool       `gorm:"not null;default:false" json:"firing"`
+	NextEvaluationAt time.Time  `gorm:"not null;index" json:"next_evaluation_at"`
+	LastEvaluatedAt  *time.Time `json:"last_evaluated_at,omitempty"`
+	LastCount        *int64     `json:"last_count,omitempty"`
+	LastTriggeredAt  *time.Time `json:"last_triggered_at,omitempty"`
+	LastError        string     `gorm:"size:1024" json:"last_error,omitempty"`
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+}
+
+// LogAlertRuleDefinitionFields are the fields set through the API. Edits save only
+// these so the evaluator's state (Firing, LastCount, ...) is never written back stale.
+var LogAlertRuleDefinitionFields = []string{
+	"Name", "Enabled", "Query", "Condition", "Threshold", "WindowSeconds", "ServiceName", "Environment",
+}
+
+// Window is the trailing period logs are counted over
+func (r *LogAlertRule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+// Breached reports whether a matching log count satisfies the rule's condition
+func (r *LogAlertRule) Breached(count int64) bool {
+	if r.Condition == LogAlertBelow {
+		return count < r.Threshold
+	}
+	return count > r.Threshold
+}
+
+// Subject stands in for a check when routing and rendering the rule's alerts
+func (r *LogAlertRule) Subject() Check {
+	return Check{
+		OrgID:       r.OrgID,
+		Name:        r.Name,
+		ServiceName: r.ServiceName,
+		Environment: r.Environment,
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaintenanceWindow suppresses alerts for a check or a whole check group
+// (including nested groups) between StartsAt and EndsAt
+type MaintenanceWindow struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	OrgID       uint      `gorm:"not null;index" json:"org_id"`
+	Name        string    `gorm:"not null;size:255" json:"name"`
+	CheckID     *uint     `gorm:"index" json:"check_id,omitempty"` // Exactly one of CheckID or GroupID is set
+	GroupID     *uint     `gorm:"index" json:"group_id,omitempty"`
+	StartsAt    time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt      time.Time `gorm:"not null;index" json:"ends_at"`
+	CreatedByID *uint     `json:"created_by_id,omitempty"`
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+}
+
+// IsActiveAt returns true if the window covers the given time
+func (m *MaintenanceWindow) IsActiveAt(t time.Time) bool {
+	return !t.Before(m.StartsAt) && t.Before(m.EndsAt)
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationChannelType identifies how a channel delivers notifications
+type NotificationChannelType string
+
+const (
+	ChannelTypeEmail     NotificationChannelType = "email"
+	ChannelTypeWebhook   NotificationChannelType = "webhook"
+	ChannelTypeSlack     NotificationChannelType = "slack"
+	ChannelTypeDiscord   NotificationChannelType = "discord"
+	ChannelTypeTeams     NotificationChannelType = "teams"
+	ChannelTypePagerDuty NotificationChannelType = "pagerduty"
+)
+
+// ChannelSourceSettings marks channels mirrored from the legacy NotificationSettings.
+// They are kept in sync whenever the settings are saved.
+const ChannelSourceSettings = "settings"
+
+// NotificationChannel is one destination for alert notifications. Orgs can have many.
+// Config holds the type-specific settings, e.g. {"recipients": [...]} for email
+// or {"url": "..."} for webhooks.
+type NotificationChannel struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	OrgID   uint                    `gorm:"not null;index" json:"org_id"`
+	Name    string                  `gorm:"not null;size:255" json:"name"`
+	Type    NotificationChannelType `gorm:"not null;size:50" json:"type"`
+	Enabled bool                    `gorm:"not null" json:"enabled"`
+	Config  JSONMap                 `gorm:"type:jsonb" json:"config"`
+	Source  string                  `gorm:"size:20;index" json:"source,omitempty"` // "settings" for legacy-mirrored channels
+
+	// Webhook signing. The previous secret stays valid until PreviousSecretExpiresAt
+	// so receivers can roll over without dropping deliveries.
+	SigningSecret           string     `gorm:"size:100" json:"-"`
+	PreviousSigningSecret   string     `gorm:"size:100" json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+}
+
+// GenerateSigningSecret creates a random webhook signing secret
+func GenerateSigningSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(bytes), nil
+}
+
+// UsesSigningSecret reports whether deliveries on this channel type are signed
+func (c *NotificationChannel) UsesSigningSecret() bool {
+	return c.Type == ChannelTypeWebhook
+}
+
+// ActiveSigningSecrets returns the secrets to sign with at time `at`, newest first
+func (c *NotificationChannel) ActiveSigningSecrets(at time.Time) []string {
+	var secrets []string
+	if c.SigningSecret != "" {
+		secrets = append(secrets, c.SigningSecret)
+	}
+	if c.PreviousSigningSecret != "" && c.PreviousSecretExpiresAt != nil && at.Before(*c.PreviousSecretExpiresAt) {
+		secrets = append(secrets, c.PreviousSigningSecret)
+	}
+	return secrets
+}
+
+// RotateSigningSecret replaces the signing secret, keeping the old one valid for grace
+func (c *NotificationChannel) RotateSigningSecret(grace time.Duration) (string, error) {
+	secret, err := GenerateSigningSecret()
+	if err != nil {
+		return "", err
+	}
+	if c.SigningSecret != "" && grace > 0 {
+		expires := time.Now().Add(grace)
+		c.PreviousSigningSecret = c.SigningSecret
+		c.PreviousSecretExpiresAt = &expires
+	} else {
+		c.PreviousSigningSecret = ""
+		c.PreviousSecretExpiresAt = nil
+	}
+	c.SigningSecret = secret
+	return secret, nil
+}
+
+// SyncSettingsChannels mirrors an org's legacy NotificationSettings into channels:
+// one email channel for the recipient list and one webhook channel for the URL.
+// Channels are created, updated or removed so they match the settings; name and
+// enabled flag changes made through the channels API are preserved.
+func SyncSettingsChannels(tx *gorm.DB, settings NotificationSettings) error {
+	recipients := make([]interface{}, len(settings.EmailRecipients))
+	for i, r := range settings.EmailRecipients {
+		recipients[i] = r
+	}
+	var emailConfig, webhookConfig JSONMap
+	if len(recipients) > 0 {
+		emailConfig = JSONMap{"recipients": recipients}
+	}
+	if settings.WebhookURL != nil && *settings.WebhookURL != "" {
+		webhookConfig = JSONMap{"url": *settings.WebhookURL}
+	}
+
+	if err := syncSettingsChannel(tx, settings.OrgID, ChannelTypeEmail, "Email", emailConfig); err != nil {
+		return err
+	}
+	return syncSettingsChannel(tx, settings.OrgID, ChannelTypeWebhook, "Webhook", webhookConfig)
+}
+
+// syncSettingsChannel upserts (or deletes, when config is nil) one legacy-mirrored channel
+func syncSettingsChannel(tx *gorm.DB, orgID uint, channelType NotificationChannelType, name string, config JSONMap) error {
+	var channel NotificationChannel
+	err := tx.Where("org_id = ? AND type = ? AND source = ?", orgID, channelType, ChannelSourceSettings).First(&channel).Error
+	if err == gorm.ErrRecordNotFound {
+		if config == nil {
+			return nil
+		}
+		channel = NotificationChannel{
+			OrgID:   orgID,
+			Name:    name,
+			Type:    channelType,
+			Enabled: true,
+			Config:  config,
+			Source:  ChannelSourceSettings,
+		}
+		if channel.UsesSigningSecret() {
+			if channel.SigningSecret, err = GenerateSigningSecret(); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&channel).Error
+	}
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return tx.Delete(&channel).Error
+	}
+	return tx.Model(&channel).Update("config", config).Error
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// NotificationDelivery records a single attempt to deliver an alert to a channel or user
+type NotificationDelivery struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OrgID       uint                    `gorm:"not null;index" json:"org_id"`
+	AlertID     uint                    `gorm:"not null;index" json:"alert_id"`
+	ChannelID   *uint                   `gorm:"index" json:"channel_id,omitempty"`
+	UserID      *uint                   `gorm:"index" json:"user_id,omitempty"`
+	JobID       *uint                   `gorm:"index" json:"job_id,omitempty"`
+	Attempt     int                     `gorm:"not null" json:"attempt"`
+	ChannelType NotificationChannelType `gorm:"size:50" json:"channel_type"` // Snapshot, channels can change later
+	ChannelName string                  `gorm:"size:255" json:"channel_name"`
+	Request     string                  `gorm:"size:1024" json:"request"` // Summary, e.g. "POST https://hooks.slack.com (812 bytes)"
+	StatusCode  int                     `json:"status_code,omitempty"`
+	LatencyMs   int64                   `json:"latency_ms"`
+	Success     bool                    `gorm:"not null" json:"success"`
+	Error       string                  `gorm:"size:1024" json:"error,omitempty"`
+	RequestedBy *uint                   `json:"requested_by,omitempty"` // Set for manual redeliveries
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NotificationJobStatus tracks a delivery job through the outbox
+type NotificationJobStatus string
+
+const (
+	NotificationJobPending    NotificationJobStatus = "pending"    // Waiting for its next attempt
+	NotificationJobProcessing NotificationJobStatus = "processing" // Claimed by a dispatcher
+	NotificationJobSent       NotificationJobStatus = "sent"
+	NotificationJobDead       NotificationJobStatus = "dead"    // Gave up after MaxAttempts
+	NotificationJobSkipped    NotificationJobStatus = "skipped" // Channel or user was removed (or channel disabled) before delivery
+)
+
+// NotificationJob is one alert to be delivered to one target: a notification channel,
+// or a single user when the alert is escalated to them or matches their subscriptions.
+// Jobs are written when the alert is raised and drained by the notification dispatcher,
+// so deliveries survive restarts and are retried with exponential backoff.
+type NotificationJob struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrgID         uint                    `gorm:"not null;index" json:"org_id"`
+	AlertID       uint                    `gorm:"not null;index" json:"alert_id"`
+	ChannelID     *uint                   `gorm:"index" json:"channel_id,omitempty"`
+	UserID        *uint                   `gorm:"index" json:"user_id,omitempty"`        // Escalation target or subscriber
+	UserChannel   NotificationChannelType `gorm:"size:50" json:"user_channel,omitempty"` // How a user job is delivered: email (default) or webhook
+	Status        NotificationJobStatus   `gorm:"not null;size:20;index:idx_notification_jobs_due,priority:1" json:"status"`
+	Attempts      int                     `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts   int                     `gorm:"not null" json:"max_attempts"`
+	NextAttemptAt time.Time               `gorm:"not null;index:idx_notification_jobs_due,priority:2" json:"next_attempt_at"`
+	LockedUntil   *time.Time              `json:"locked_until,omitempty"` // Lease held by the dispatcher processing the job
+	LastError     string                  `gorm:"size:1024" json:"last_error,omitempty"`
+	SentAt        *time.Time              `json:"sent_at,omitempty"`
+	RequestedBy   *uint                   `json:"requested_by,omitempty"` // User who asked for a manual redelivery
+
+	// DeliveredRecipients are email recipients whose batch already went out; retries skip them
+	DeliveredRecipients pq.StringArray `gorm:"type:text[]" json:"delivered_recipients,omitempty"`
+
+	// Relations
+	Alert   Alert                `gorm:"foreignKey:AlertID" json:"-"`
+	Channel *NotificationChannel `gorm:"foreignKey:ChannelID" json:"-"`
+	User    *User                `gorm:"foreignKey:UserID" json:"-"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationTemplate holds an org's custom notification templates.
+// Empty fields fall back to the built-in defaults.
+type NotificationTemplate struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrgID        uint   `gorm:"uniqueIndex;not null" json:"org_id"`
+	EmailSubject string `gorm:"type:text" json:"email_subject"` // text/template, single line
+	EmailText    string `gorm:"type:text" json:"email_text"`    // text/template
+	EmailHTML    string `gorm:"type:text" json:"email_html"`    // html/template
+	WebhookBody  string `gorm:"type:text" json:"webhook_body"`  // text/template that must render valid JSON
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// OnCallSchedule is a rotation of users who take turns being on call.
+// Shifts start at RotationStart and hand off every ShiftLengthHours, walking
+// through ParticipantIDs in order. Shifts that are whole days keep their
+// wall-clock handoff time in Timezone across DST changes.
+type OnCallSchedule struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	OrgID            uint          `gorm:"not null;index" json:"org_id"`
+	Name             string        `gorm:"not null;size:255" json:"name"`
+	Timezone         string        `gorm:"not null;size:64;default:'UTC'" json:"timezone"`
+	RotationStart    time.Time     `gorm:"not null" json:"rotation_start"` // First handoff
+	ShiftLengthHours int           `gorm:"not null;default:168" json:"shift_length_hours"`
+	ParticipantIDs   pq.Int64Array `gorm:"type:bigint[]" json:"participant_ids"` // User IDs in rotation order
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+}
+
+// OnCallOverride replaces whoever the rotation says is on call for a time range
+type OnCallOverride struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OrgID      uint      `gorm:"not null;index" json:"org_id"`
+	ScheduleID uint      `gorm:"not null;index" json:"schedule_id"`
+	UserID     uint      `gorm:"not null" json:"user_id"`
+	StartsAt   time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt     time.Time `gorm:"not null;index" json:"ends_at"`
+
+	// Relations
+	Schedule OnCallSchedule `gorm:"foreignKey:ScheduleID" json:"-"`
+	User     User           `gorm:"foreignKey:UserID" json:"-"`
+}
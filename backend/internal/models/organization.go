@@ -15,6 +15,9 @@ type Organization struct {
 	Name string `gorm:"not null;size:255" json:"name"`
 	Slug string `gorm:"uniqueIndex;size:100" json:"slug"` // URL-friendly identifier
 
+	// Timezone (IANA name) used for scheduled work such as digests
+	Timezone string `gorm:"not null;size:64;default:'UTC'" json:"timezone"`
+
 	// Billing & Subscription
 	Plan                     Plan    `gorm:"size:20;default:'free'" json:"plan"`
 	StripeCustomerID         *string `gorm:"size:255;index" json:"-"`
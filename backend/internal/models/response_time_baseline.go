@@ -0,0 +1,73 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Baseline parameters
+const (
+	// BaselineBuckets is one bucket per hour of the week
+	BaselineBuckets = 7 * 24
+	// BaselineAlpha is the EWMA weight of a new sample once a bucket is warmed up
+	BaselineAlpha = 0.05
+	// BaselineMinSamples is how many samples a bucket needs before it is used for detection
+	BaselineMinSamples = 10
+)
+
+// ResponseTimeBaseline is the expected response time of a check for one hour of the
+// week, kept as an exponentially weighted mean and variance of successful runs.
+// Buckets are in UTC so they don't depend on the org's timezone setting.
+type ResponseTimeBaseline struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	CheckID  uint    `gorm:"not null;uniqueIndex:idx_baselines_check_bucket,priority:1" json:"-"`
+	Bucket   int     `gorm:"not null;uniqueIndex:idx_baselines_check_bucket,priority:2" json:"bucket"` // 0 = Sunday 00:00 UTC
+	Mean     float64 `gorm:"not null" json:"mean_ms"`
+	Variance float64 `gorm:"not null" json:"-"`
+	Samples  int     `gorm:"not null" json:"samples"`
+}
+
+// BaselineBucket returns the hour-of-week bucket t falls in
+func BaselineBucket(t time.Time) int {
+	t = t.UTC()
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// Ready reports whether the bucket has seen enough samples to judge a new one
+func (b *ResponseTimeBaseline) Ready() bool {
+	return b.Samples >= BaselineMinSamples
+}
+
+// StdDev is the bucket's standard deviation in ms. It is floored at 5% of the mean
+// (and 1ms) so very steady checks don't flag every millisecond of jitter.
+func (b *ResponseTimeBaseline) StdDev() float64 {
+	return math.Max(math.Sqrt(b.Variance), math.Max(b.Mean*0.05, 1))
+}
+
+// Score is how many standard deviations ms is above the bucket's mean
+func (b *ResponseTimeBaseline) Score(ms float64) float64 {
+	return (ms - b.Mean) / b.StdDev()
+}
+
+// Observe folds a sample into the bucket. Early samples are averaged evenly; after
+// that the weight settles at BaselineAlpha. Once the bucket is ready, samples are
+// clamped to maxScore standard deviations so an ongoing slowdown is learned slowly
+// instead of immediately becoming the new normal.
+func (b *ResponseTimeBaseline) Observe(ms, maxScore float64) {
+	if b.Samples == 0 {
+		b.Mean, b.Variance, b.Samples = ms, 0, 1
+		return
+	}
+	if b.Ready() {
+		limit := maxScore * b.StdDev()
+		ms = math.Max(b.Mean-limit, math.Min(ms, b.Mean+limit))
+	}
+	alpha := math.Max(1/float64(b.Samples+1), BaselineAlpha)
+	diff := ms - b.Mean
+	increment := alpha * diff
+	b.Mean += increment
+	b.Variance = (1 - alpha) * (b.Variance + diff*increment)
+	b.Samples++
+}
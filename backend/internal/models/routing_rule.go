@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RoutingRule sends matching alerts to a set of notification channels.
+// Rules are evaluated in Position order; every matcher that is set must match,
+// and empty matchers match anything. When a matching rule has StopProcessing set,
+// later rules are not evaluated.
+type RoutingRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrgID    uint   `gorm:"not null;index" json:"org_id"`
+	Name     string `gorm:"not null;size:255" json:"name"`
+	Position int    `gorm:"not null;default:0" json:"position"`
+	Enabled  bool   `gorm:"not null" json:"enabled"`
+
+	// Matchers
+	Environments pq.StringArray `gorm:"type:text[]" json:"environments"`
+	ServiceNames pq.StringArray `gorm:"type:text[]" json:"service_names"`
+	AlertTypes   pq.StringArray `gorm:"type:text[]" json:"alert_types"`
+	Tags         JSONMap        `gorm:"type:jsonb" json:"tags"`         // Every key/value must be present on the check
+	GroupIDs     pq.Int64Array  `gorm:"type:bigint[]" json:"group_ids"` // The check's group or any of its ancestors
+
+	// Destinations
+	ChannelIDs     pq.Int64Array `gorm:"type:bigint[]" json:"channel_ids"`
+	StopProcessing bool          `gorm:"not null;default:false" json:"stop_processing"`
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+}
+
+// Matches reports whether an alert on a check satisfies every matcher of the rule.
+// checkGroupIDs are the check's group and all of its ancestors.
+func (r *RoutingRule) Matches(alert Alert, check Check, checkGroupIDs []uint) bool {
+	if !matchesAny(r.Environments, check.Environment) {
+		return false
+	}
+	if !matchesAny(r.ServiceNames, check.ServiceName) {
+		return false
+	}
+	if !matchesAny(r.AlertTypes, string(alert.AlertType)) {
+		return false
+	}
+	for key, want := range r.Tags {
+		got, ok := check.Tags[key]
+		if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	if len(r.GroupIDs) > 0 && !containsGroup(r.GroupIDs, checkGroupIDs) {
+		return false
+	}
+	return true
+}
+
+// containsGroup is true when any of checkGroupIDs is in ids
+func containsGroup(ids pq.Int64Array, checkGroupIDs []uint) bool {
+	for _, id := range ids {
+		for _, groupID := range checkGroupIDs {
+			if uint(id) == groupID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesAny is true when allowed is empty or contains value
+func matchesAny(allowed pq.StringArray, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Security audit finding identifiers
+const (
+	SecurityCheckHSTS               = "hsts"
+	SecurityCheckCSP                = "csp"
+	SecurityCheckContentTypeOptions = "x_content_type_options"
+	SecurityCheckCookieSecure       = "cookie_secure"
+	SecurityCheckCookieHTTPOnly     = "cookie_httponly"
+	SecurityCheckHTTPSRedirect      = "https_redirect"
+)
+
+// Finding severities, which determine how much a failure costs the grade
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// SecurityFinding is the outcome of one header/HTTPS hygiene rule
+type SecurityFinding struct {
+	Check    string `json:"check"`
+	Passed   bool   `json:"passed"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// SecurityFindings is stored as a JSONB array on check results
+type SecurityFindings []SecurityFinding
+
+func (f SecurityFindings) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+func (f *SecurityFindings) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, f)
+}
+
+// securityGradeRank orders grades from best to worst
+var securityGradeRank = map[string]int{"A": 5, "B": 4, "C": 3, "D": 2, "F": 1}
+
+// SecurityGradeDropped returns true if `current` is a worse grade than `previous`.
+// An empty previous grade (first audit) never counts as a drop.
+func SecurityGradeDropped(previous, current string) bool {
+	prevRank, ok := securityGradeRank[previous]
+	if !ok {
+		return false
+	}
+	currRank, ok := securityGradeRank[current]
+	if !ok {
+		return false
+	}
+	return currRank < prevRank
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TraceAlertMetric is the span aggregate a trace alert rule watches
+type TraceAlertMetric string
+
+const (
+	TraceMetricP50Duration TraceAlertMetric = "p50_duration" // Milliseconds
+	TraceMetricP95Duration TraceAlertMetric = "p95_duration"
+	TraceMetricP99Duration TraceAlertMetric = "p99_duration"
+	TraceMetricErrorRate   TraceAlertMetric = "error_rate" // Percent of spans with status ERROR
+)
+
+// Percentile returns the duration percentile the metric measures, or 0 for the error rate
+func (m TraceAlertMetric) Percentile() float64 {
+	switch m {
+	case TraceMetricP50Duration:
+		return 0.50
+	case TraceMetricP95Duration:
+		return 0.95
+	case TraceMetricP99Duration:
+		return 0.99
+	}
+	return 0
+}
+
+// IsValid reports whether m is a known metric
+func (m TraceAlertMetric) IsValid() bool {
+	return m == TraceMetricErrorRate || m.Percentile() > 0
+}
+
+// Trace alert rule limits
+const (
+	DefaultTraceAlertWindowSeconds = 5 * 60
+	MinTraceAlertWindowSeconds     = 60
+	MaxTraceAlertWindowSeconds     = 24 * 60 * 60
+	DefaultTraceAlertMinSpans      = 10
+	// TraceAlertExampleTraces is how many example trace IDs an alert carries
+	TraceAlertExampleTraces = 3
+)
+
+// TraceAlertRule raises an alert when a span aggregate for a service (optionally one
+// operation) exceeds a threshold over a trailing window, and a resolution once it no longer does
+type TraceAlertRule struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Kept so queued notifications still render
+
+	OrgID         uint             `gorm:"not null;index" json:"org_id"`
+	Name          string           `gorm:"not null;size:255" json:"name"`
+	Enabled       bool             `gorm:"not null" json:"enabled"`
+	ServiceName   string           `gorm:"not null;size:255" json:"service_name"`
+	Operation     string           `gorm:"size:512" json:"operation,omitempty"`  // Empty matches every operation
+	Environment   string           `gorm:"size:50" json:"environment,omitempty"` // Empty matches every environment
+	Metric        TraceAlertMetric `gorm:"not null;size:20" json:"metric"`
+	Threshold     float64          `gorm:"not null" json:"threshold"` // Milliseconds, or percent for error_rate
+	WindowSeconds int              `gorm:"not null" json:"window_seconds"`
+	MinSpans      int              `gorm:"not null" json:"min_spans"` // Fewer spans in the window leave the state unchanged
+	CreatedByID   *uint            `json:"created_by_id,omitempty"`
+
+	// Evaluation state
+	Firing           bool       `gorm:"not null;default:false" json:"firing"`
+	NextEvaluationAt time.Time  `gorm:"not null;index" json:"next_evaluation_at"`
+	LastEvaluatedAt  *time.Time `json:"last_evaluated_at,omitempty"`
+	LastValue        *float64   `json:"last_value,omitempty"`
+	LastSpanCount    *int64     `json:"last_span_count,omitempty"`
+	LastTriggeredAt  *time.Time `json:"last_triggered_at,omitempty"`
+	LastError        string     `gorm:"size:1024" json:"last_error,omitempty"`
+
+	// Relations
+	Organization Organization `gorm:"foreignKey:OrgID" json:"-"`
+}
+
+// TraceAlertRuleDefinitionFields are the fields set through the API. Edits save only
+// these so the evaluator's state (Firing, LastValue, ...) is never written back stale.
+var TraceAlertRuleDefinitionFields = []string{
+	"Name", "Enabled", "ServiceName", "Operation", "Environment", "Metric", "Threshold", "WindowSeconds", "MinSpans",
+}
+
+// Window is the trailing period spans are aggregated over
+func (r *TraceAlertRule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+// Subject stands in for a check when routing and rendering the rule's alerts
+func (r *TraceAlertRule) Subject() Check {
+	return Check{
+		OrgID:       r.OrgID,
+		Name:        r.Name,
+		ServiceName: r.ServiceName,
+		Environment: r.Environment,
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// UsageLevel is a plan usage threshold owners are told about
+type UsageLevel string
+
+const (
+	UsageLevelWarning UsageLevel = "warning" // 80% of the limit
+	UsageLevelLimit   UsageLevel = "limit"   // 100% of the limit
+	UsageLevelOverage UsageLevel = "overage" // Above the limit
+)
+
+// UsageWarningRatio is the share of a limit at which owners are first warned
+const UsageWarningRatio = 0.8
+
+// UsageNotification records that an org's owners were told a resource crossed a
+// usage level in a calendar month (UTC), so each level is announced once per month
+type UsageNotification struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OrgID    uint       `gorm:"not null;uniqueIndex:idx_usage_notifications_once,priority:1" json:"org_id"`
+	Year     int        `gorm:"not null;uniqueIndex:idx_usage_notifications_once,priority:2" json:"year"`
+	Month    int        `gorm:"not null;uniqueIndex:idx_usage_notifications_once,priority:3" json:"month"` // 1-12
+	Resource string     `gorm:"not null;size:50;uniqueIndex:idx_usage_notifications_once,priority:4" json:"resource"`
+	Level    UsageLevel `gorm:"not null;size:20;uniqueIndex:idx_usage_notifications_once,priority:5" json:"level"`
+	Ratio    float64    `gorm:"not null" json:"ratio"` // Usage / limit when the level was crossed
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// UserNotificationPreference is one user's personal alert subscriptions, delivery
+// channels and quiet hours. Alerts matching a subscription are delivered to the user
+// on top of the org's routed channels.
+type UserNotificationPreference struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrgID  uint `gorm:"not null;index" json:"org_id"`
+	UserID uint `gorm:"not null;uniqueIndex" json:"user_id"`
+
+	// Subscriptions. An alert matches when its check is listed, sits in a listed group
+	// (or one nested below it), or carries any of the listed tag key/values.
+	CheckIDs   pq.Int64Array  `gorm:"type:bigint[]" json:"check_ids"`
+	GroupIDs   pq.Int64Array  `gorm:"type:bigint[]" json:"group_ids"`
+	Tags       JSONMap        `gorm:"type:jsonb" json:"tags"`
+	AlertTypes pq.StringArray `gorm:"type:text[]" json:"alert_types"` // Empty matches every alert type
+
+	// Channels
+	EmailEnabled bool   `gorm:"not null" json:"email_enabled"`
+	WebhookURL   string `gorm:"size:2048" json:"webhook_url,omitempty"` // Personal webhook
+	// Signs personal webhook deliveries like a webhook channel's secret. Preferences are
+	// only ever returned to their own user, so the secret is shown with them.
+	WebhookSigningSecret string `gorm:"size:100" json:"webhook_signing_secret,omitempty"`
+
+	// Quiet hours ("HH:MM", local to Timezone or else the org's timezone). Non-critical
+	// alerts raised during quiet hours are held until they end; critical ones go out at once.
+	QuietHoursEnabled bool   `gorm:"not null;default:false" json:"quiet_hours_enabled"`
+	QuietHoursStart   string `gorm:"size:5" json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd     string `gorm:"size:5" json:"quiet_hours_end,omitempty"`
+	Timezone          string `gorm:"size:64" json:"timezone,omitempty"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// HasSubscriptions reports whether any check, group or tag is subscribed to
+func (p *UserNotificationPreference) HasSubscriptions() bool {
+	return len(p.CheckIDs) > 0 || len(p.GroupIDs) > 0 || len(p.Tags) > 0
+}
+
+// Matches reports whether an alert on a check is covered by the subscriptions.
+// checkGroupIDs are the check's group and all of its ancestors.
+func (p *UserNotificationPreference) Matches(alert Alert, check Check, checkGroupIDs []uint) bool {
+	if !matchesAny(p.AlertTypes, string(alert.AlertType)) {
+		return false
+	}
+	for _, id := range p.CheckIDs {
+		if uint(id) == check.ID {
+			return true
+		}
+	}
+	for _, id := range p.GroupIDs {
+		for _, groupID := range checkGroupIDs {
+			if uint(id) == groupID {
+				return true
+			}
+		}
+	}
+	for key, want := range p.Tags {
+		if got, ok := check.Tags[key]; ok && fmt.Sprint(got) == fmt.Sprint(want) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseClock parses an "HH:MM" time of day into minutes after midnight
+func ParseClock(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// QuietUntil returns when the quiet hours covering `at` end, or the zero time if `at`
+// is outside them. Windows may wrap midnight, e.g. 22:00-07:00.
+func (p *UserNotificationPreference) QuietUntil(loc *time.Location, at time.Time) time.Time {
+	if !p.QuietHoursEnabled {
+		return time.Time{}
+	}
+	start, err := ParseClock(p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}
+	}
+	end, err := ParseClock(p.QuietHoursEnd)
+	if err != nil || start == end {
+		return time.Time{}
+	}
+
+	local := at.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	endOn := func(dayOffset int) time.Time {
+		return time.Date(midnight.Year(), midnight.Month(), midnight.Day()+dayOffset, end/60, end%60, 0, 0, loc)
+	}
+	switch {
+	case start < end && now >= start && now < end:
+		return endOn(0)
+	case start > end && now >= start:
+		return endOn(1)
+	case start > end && now < end:
+		return endOn(0)
+	}
+	return time.Time{}
+}
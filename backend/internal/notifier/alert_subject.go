@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"fmt"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// alertSubject loads the check an alert is about, or stands in for one when the
+// alert was raised by a log or trace alert rule or received from Alertmanager or
+// Grafana. Deleted checks and rules still get their final notifications.
+func alertSubject(db *gorm.DB, alert models.Alert) (models.Check, error) {
+	var check models.Check
+	if alert.CheckID != nil {
+		if err := db.Unscoped().First(&check, *alert.CheckID).Error; err != nil {
+			return check, fmt.Errorf("failed to load check: %w", err)
+		}
+		return check, nil
+	}
+	if alert.LogAlertRuleID != nil {
+		var rule models.LogAlertRule
+		if err := db.Unscoped().First(&rule, *alert.LogAlertRuleID).Error; err != nil {
+			return check, fmt.Errorf("failed to load log alert rule: %w", err)
+		}
+		return rule.Subject(), nil
+	}
+	if alert.TraceAlertRuleID != nil {
+		var rule models.TraceAlertRule
+		if err := db.Unscoped().First(&rule, *alert.TraceAlertRuleID).Error; err != nil {
+			return check, fmt.Errorf("failed to load trace alert rule: %w", err)
+		}
+		return rule.Subject(), nil
+	}
+	if alert.ExternalAlertID != nil {
+		var external models.ExternalAlert
+		if err := db.First(&external, *alert.ExternalAlertID).Error; err != nil {
+			return check, fmt.Errorf("failed to load external alert: %w", err)
+		}
+		return external.Subject(), nil
+	}
+	return check, fmt.Errorf("alert %d has no check, alert rule or external alert", alert.ID)
+}
+
+// alertLink is the dashboard deep link for a notification and its button label,
+// or "" if the frontend URL is unknown
+func alertLink(n Notification) (string, string) {
+	if n.Alert.Link == "" {
+		// Rule and test alerts have no check to link to
+		if n.Check.ID == 0 {
+			return "", ""
+		}
+		return checkLink(n.Check), "View check"
+	}
+	// External alerts link back to the rule in Alertmanager or Grafana
+	if n.Alert.ExternalAlertID != nil {
+		return n.Alert.Link, "View source"
+	}
+	if cfg == nil || cfg.FrontendURL == "" {
+		return "", ""
+	}
+	return frontendLink(n.Alert.Link), "View logs"
+}
@@ -0,0 +1,226 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/pkg/webhook"
+	"gorm.io/gorm"
+)
+
+// Notification is everything a channel needs to render and deliver one alert
+type Notification struct {
+	Alert    models.Alert
+	Check    models.Check
+	Org      models.Organization
+	Incident *models.Incident // nil when the alert is not part of an incident
+	// Templates are the org's custom templates, nil to use the defaults
+	Templates *models.NotificationTemplate
+	// EventID identifies this alert for this channel. Retries reuse it so receivers can dedupe.
+	EventID string
+}
+
+// NewNotification loads the org, incident and templates that go with an alert.
+// Missing context degrades the rendering rather than failing the delivery.
+func NewNotification(db *gorm.DB, alert models.Alert, check models.Check, eventID string) Notification {
+	n := Notification{Alert: alert, Check: check, EventID: eventID}
+	if err := db.First(&n.Org, alert.OrgID).Error; err != nil {
+		log.Printf("Failed to load org %d for notification: %v", alert.OrgID, err)
+	}
+	if alert.IncidentID != nil {
+		var incident models.Incident
+		if err := db.First(&incident, *alert.IncidentID).Error; err == nil {
+			n.Incident = &incident
+		}
+	}
+	var templates models.NotificationTemplate
+	if err := db.Where("org_id = ?", alert.OrgID).First(&templates).Error; err == nil {
+		n.Templates = &templates
+	}
+	return n
+}
+
+// NotificationEventID is the stable event ID of an alert delivered to a channel
+func NotificationEventID(alertID, channelID uint) string {
+	return fmt.Sprintf("evt_%d_%d", alertID, channelID)
+}
+
+// DeliveryReceipt describes what a channel sent and what came back, for the delivery log.
+// Request is a summary safe to show users; it never contains secrets embedded in URLs.
+type DeliveryReceipt struct {
+	Request    string
+	StatusCode int // Response status for HTTP-based channels, 0 otherwise
+}
+
+// Channel delivers notifications to a single configured destination
+type Channel interface {
+	Send(ctx context.Context, n Notification) (DeliveryReceipt, error)
+}
+
+// ChannelFactory validates a channel's config and builds the Channel for it.
+// Returning an error rejects the config, so factories double as validators.
+type ChannelFactory func(ch models.NotificationChannel) (Channel, error)
+
+var (
+	channelTypesMu sync.RWMutex
+	channelTypes   = map[models.NotificationChannelType]ChannelFactory{}
+)
+
+// RegisterChannelType makes a channel type available. Types register themselves in init.
+func RegisterChannelType(channelType models.NotificationChannelType, factory ChannelFactory) {
+	channelTypesMu.Lock()
+	defer channelTypesMu.Unlock()
+	channelTypes[channelType] = factory
+}
+
+// ChannelTypes lists the registered channel types
+func ChannelTypes() []models.NotificationChannelType {
+	channelTypesMu.RLock()
+	defer channelTypesMu.RUnlock()
+	types := make([]models.NotificationChannelType, 0, len(channelTypes))
+	for t := range channelTypes {
+		types = append(types, t)
+	}
+	return types
+}
+
+// NewChannel builds the sender for a configured channel
+func NewChannel(ch models.NotificationChannel) (Channel, error) {
+	channelTypesMu.RLock()
+	factory, ok := channelTypes[ch.Type]
+	channelTypesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown channel type %q", ch.Type)
+	}
+	return factory(ch)
+}
+
+func init() {
+	RegisterChannelType(models.ChannelTypeEmail, newEmailChannel)
+	RegisterChannelType(models.ChannelTypeWebhook, newWebhookChannel)
+}
+
+var channelEmailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// emailChannel sends alerts to a list of recipients
+type emailChannel struct {
+	recipients []string
+}
+
+func newEmailChannel(ch models.NotificationChannel) (Channel, error) {
+	raw, _ := ch.Config["recipients"].([]interface{})
+	recipients := make([]string, 0, len(raw))
+	for _, r := range raw {
+		email, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("recipients must be a list of email addresses")
+		}
+		email = strings.TrimSpace(strings.ToLower(email))
+		if !channelEmailRegex.MatchString(email) {
+			return nil, fmt.Errorf("invalid email: %s", email)
+		}
+		recipients = append(recipients, email)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("recipients is required")
+	}
+	return &emailChannel{recipients: recipients}, nil
+}
+
+// without returns a copy of the channel that skips recipients already delivered to
+func (e *emailChannel) without(delivered []string) *emailChannel {
+	skip := make(map[string]bool, len(delivered))
+	for _, r := range delivered {
+		skip[r] = true
+	}
+	remaining := make([]string, 0, len(e.recipients))
+	for _, r := range e.recipients {
+		if !skip[r] {
+			remaining = append(remaining, r)
+		}
+	}
+	return &emailChannel{recipients: remaining}
+}
+
+func (e *emailChannel) Send(ctx context.Context, n Notification) (DeliveryReceipt, error) {
+	if len(e.recipients) == 0 {
+		return DeliveryReceipt{Request: "email: every recipient was already delivered to"}, nil
+	}
+	receipt := DeliveryReceipt{Request: "email to " + strings.Join(e.recipients, ", ")}
+	email := renderEmail(n)
+	email.To = e.recipients
+	return receipt, SendEmail(ctx, email)
+}
+
+// SendToEmailChannel sends a pre-rendered email, such as a digest, to an email channel's recipients
+func SendToEmailChannel(ctx context.Context, ch models.NotificationChannel, email Email) error {
+	if ch.Type != models.ChannelTypeEmail {
+		return fmt.Errorf("channel %d is not an email channel", ch.ID)
+	}
+	sender, err := newEmailChannel(ch)
+	if err != nil {
+		return err
+	}
+	email.To = sender.(*emailChannel).recipients
+	return SendEmail(ctx, email)
+}
+
+// webhookChannel POSTs the generic JSON payload to a URL, signed with the channel's
+// secrets (see pkg/webhook for the scheme and the receiver-side verification helper)
+type webhookChannel struct {
+	url     string
+	secrets []string
+}
+
+func newWebhookChannel(ch models.NotificationChannel) (Channel, error) {
+	target, err := channelURL(ch.Config, "url")
+	if err != nil {
+		return nil, err
+	}
+	return &webhookChannel{url: target, secrets: ch.ActiveSigningSecrets(time.Now())}, nil
+}
+
+func (w *webhookChannel) Send(ctx context.Context, n Notification) (DeliveryReceipt, error) {
+	body, err := renderWebhookBody(n)
+	if err != nil {
+		return DeliveryReceipt{Request: "POST " + redactURL(w.url)}, fmt.Errorf("failed to render payload: %w", err)
+	}
+	headers := map[string]string{
+		webhook.EventIDHeader: n.EventID,
+		webhook.SchemaHeader:  webhook.SchemaVersion,
+	}
+	if len(w.secrets) > 0 {
+		headers[webhook.SignatureHeader] = webhook.SignatureHeaderValue(w.secrets, time.Now(), body)
+	}
+	return post(ctx, w.url, body, headers)
+}
+
+// redactURL keeps only the scheme and host of a URL; webhook paths often embed tokens
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "(invalid URL)"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// channelURL reads and validates an http(s) URL from a channel config
+func channelURL(config models.JSONMap, key string) (string, error) {
+	raw, _ := config[key].(string)
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%s is required", key)
+	}
+	parsed, err := url.ParseRequestURI(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("%s must be an http:// or https:// URL", key)
+	}
+	return raw, nil
+}
@@ -0,0 +1,250 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+)
+
+// Alert colors shared by all chat formats
+const (
+	colorDown     = "#E01E5A"
+	colorRecovery = "#2EB67D"
+	colorWarning  = "#ECB22E"
+)
+
+// Title length limits; longer titles make Slack and Discord reject the whole message
+const (
+	slackHeaderMaxRunes  = 150
+	discordTitleMaxRunes = 256
+)
+
+// Chat channel configs are {"url": "<incoming webhook URL>"}
+func init() {
+	RegisterChannelType(models.ChannelTypeSlack, newChatChannel(formatSlack))
+	RegisterChannelType(models.ChannelTypeDiscord, newChatChannel(formatDiscord))
+	RegisterChannelType(models.ChannelTypeTeams, newChatChannel(formatTeams))
+}
+
+// chatFormatter builds the JSON body for one chat service
+type chatFormatter func(n Notification) interface{}
+
+// chatChannel posts a formatted message to an incoming webhook URL
+type chatChannel struct {
+	url    string
+	format chatFormatter
+}
+
+func newChatChannel(format chatFormatter) ChannelFactory {
+	return func(ch models.NotificationChannel) (Channel, error) {
+		target, err := channelURL(ch.Config, "url")
+		if err != nil {
+			return nil, err
+		}
+		return &chatChannel{url: target, format: format}, nil
+	}
+}
+
+func (ch *chatChannel) Send(ctx context.Context, n Notification) (DeliveryReceipt, error) {
+	return postJSON(ctx, ch.url, ch.format(n))
+}
+
+// postJSON POSTs a JSON body and treats any 4xx/5xx as a failure
+func postJSON(ctx context.Context, target string, body interface{}) (DeliveryReceipt, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return DeliveryReceipt{Request: "POST " + redactURL(target)}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return post(ctx, target, data, nil)
+}
+
+// post sends a JSON request body with optional extra headers
+func post(ctx context.Context, target string, data []byte, headers map[string]string) (DeliveryReceipt, error) {
+	receipt := DeliveryReceipt{Request: fmt.Sprintf("POST %s (%d bytes)", redactURL(target), len(data))}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return receipt, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return receipt, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	receipt.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 400 {
+		return receipt, fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return receipt, nil
+}
+
+// alertColor maps an alert type to its display color
+func alertColor(alertType models.AlertType) string {
+	switch alertType {
+	case models.AlertTypeDown:
+		return colorDown
+	case models.AlertTypeRecovery, models.AlertTypeLogResolved, models.AlertTypeTraceResolved, models.AlertTypeLatencyNormal,
+		models.AlertTypeExternalResolved:
+		return colorRecovery
+	default:
+		return colorWarning
+	}
+}
+
+// checkLink is the dashboard deep link for a check, or "" if the frontend URL is unknown
+func checkLink(check models.Check) string {
+	if cfg == nil || cfg.FrontendURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/checks/%d", strings.TrimRight(cfg.FrontendURL, "/"), check.ID)
+}
+
+// alertFacts are the label/value pairs shown by every chat format
+func alertFacts(n Notification) [][2]string {
+	facts := [][2]string{}
+	if n.Check.URL != "" {
+		facts = append(facts, [2]string{"URL", n.Check.URL})
+	}
+	if n.Alert.StatusCode > 0 {
+		facts = append(facts, [2]string{"Status code", strconv.Itoa(n.Alert.StatusCode)})
+	}
+	if n.Alert.ErrorMessage != "" {
+		facts = append(facts, [2]string{"Error", n.Alert.ErrorMessage})
+	}
+	return facts
+}
+
+// shorten cuts s to at most max runes, ending in an ellipsis when cut
+func shorten(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// formatSlack renders a Block Kit message. The blocks sit inside a colored attachment
+// since Block Kit has no color of its own.
+func formatSlack(n Notification) interface{} {
+	headline := alertHeadline(n.Alert, n.Check)
+	fields := []map[string]interface{}{}
+	for _, fact := range alertFacts(n) {
+		fields = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*%s*\n%s", fact[0], fact[1]),
+		})
+	}
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": shorten(fmt.Sprintf("[%s] %s", n.Alert.AlertType, headline), slackHeaderMaxRunes)},
+		},
+		{
+			"type":   "section",
+			"fields": fields,
+		},
+	}
+	if link, label := alertLink(n); link != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "actions",
+			"elements": []map[string]interface{}{{
+				"type": "button",
+				"text": map[string]interface{}{"type": "plain_text", "text": label},
+				"url":  link,
+			}},
+		})
+	}
+	return map[string]interface{}{
+		"text": headline, // Notification fallback
+		"attachments": []map[string]interface{}{{
+			"color":  alertColor(n.Alert.AlertType),
+			"blocks": blocks,
+		}},
+	}
+}
+
+// formatDiscord renders a single embed
+func formatDiscord(n Notification) interface{} {
+	color, _ := strconv.ParseInt(strings.TrimPrefix(alertColor(n.Alert.AlertType), "#"), 16, 64)
+	fields := []map[string]interface{}{}
+	for _, fact := range alertFacts(n) {
+		fields = append(fields, map[string]interface{}{
+			"name":   fact[0],
+			"value":  fact[1],
+			"inline": fact[0] == "Status code",
+		})
+	}
+	embed := map[string]interface{}{
+		"title":     shorten(fmt.Sprintf("[%s] %s", n.Alert.AlertType, alertHeadline(n.Alert, n.Check)), discordTitleMaxRunes),
+		"color":     color,
+		"fields":    fields,
+		"timestamp": n.Alert.CreatedAt.Format(time.RFC3339),
+	}
+	if link, _ := alertLink(n); link != "" {
+		embed["url"] = link
+	}
+	return map[string]interface{}{
+		"embeds": []map[string]interface{}{embed},
+	}
+}
+
+// formatTeams renders an Adaptive Card wrapped in a message for Teams incoming webhooks
+func formatTeams(n Notification) interface{} {
+	// Adaptive Cards only support named colors
+	titleColor := "Warning"
+	switch n.Alert.AlertType {
+	case models.AlertTypeDown:
+		titleColor = "Attention"
+	case models.AlertTypeRecovery, models.AlertTypeLogResolved, models.AlertTypeTraceResolved, models.AlertTypeLatencyNormal,
+		models.AlertTypeExternalResolved:
+		titleColor = "Good"
+	}
+	facts := []map[string]interface{}{}
+	for _, fact := range alertFacts(n) {
+		facts = append(facts, map[string]interface{}{"title": fact[0], "value": fact[1]})
+	}
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []map[string]interface{}{
+			{
+				"type":   "TextBlock",
+				"text":   fmt.Sprintf("[%s] %s", n.Alert.AlertType, alertHeadline(n.Alert, n.Check)),
+				"weight": "Bolder",
+				"size":   "Medium",
+				"color":  titleColor,
+				"wrap":   true,
+			},
+			{
+				"type":  "FactSet",
+				"facts": facts,
+			},
+		},
+	}
+	if link, label := alertLink(n); link != "" {
+		card["actions"] = []map[string]interface{}{{
+			"type":  "Action.OpenUrl",
+			"title": label,
+			"url":   link,
+		}}
+	}
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+}
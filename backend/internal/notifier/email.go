@@ -0,0 +1,394 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/config"
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// Email provider names, selected with EMAIL_PROVIDER
+const (
+	EmailProviderSMTP     = "smtp"
+	EmailProviderSendGrid = "sendgrid"
+)
+
+// Batch sizes. Recipients of one batch never see each other.
+const (
+	smtpBatchSize     = 50
+	sendGridBatchSize = 1000 // SendGrid's personalizations limit
+)
+
+// emailFromName is the display name on every outgoing email
+const emailFromName = "Light House"
+
+// Email is a message to one or more recipients. Text is required; when HTML is
+// set the message is sent as multipart/alternative.
+type Email struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailProvider delivers emails. Implementations batch recipients themselves and
+// return a *PartialSendError when only some batches were delivered.
+type EmailProvider interface {
+	Name() string
+	Send(ctx context.Context, email Email) error
+}
+
+// EmailProviderFactory builds a provider from config, or errors if it is not configured
+type EmailProviderFactory func(c *config.Config) (EmailProvider, error)
+
+var (
+	emailProvidersMu sync.RWMutex
+	emailProviders   = map[string]EmailProviderFactory{}
+	emailProvider    EmailProvider
+	emailProviderErr error
+)
+
+// RegisterEmailProvider makes an email provider available. Providers register themselves in init.
+func RegisterEmailProvider(name string, factory EmailProviderFactory) {
+	emailProvidersMu.Lock()
+	defer emailProvidersMu.Unlock()
+	emailProviders[name] = factory
+}
+
+func init() {
+	RegisterEmailProvider(EmailProviderSMTP, newSMTPProvider)
+	RegisterEmailProvider(EmailProviderSendGrid, newSendGridProvider)
+}
+
+// selectEmailProvider picks the configured provider. Without EMAIL_PROVIDER, production
+// uses SendGrid, and development uses SMTP (Mailpit) falling back to SendGrid.
+func selectEmailProvider(c *config.Config) (EmailProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(c.EmailProvider))
+	if name == "" {
+		switch {
+		case c.Environment == "production":
+			name = EmailProviderSendGrid
+		case c.SMTPHost != "":
+			name = EmailProviderSMTP
+		case c.SendGridKey != "":
+			name = EmailProviderSendGrid
+		default:
+			return nil, fmt.Errorf("no email provider configured (set SMTP_HOST for dev or SENDGRID_API_KEY)")
+		}
+	}
+	emailProvidersMu.RLock()
+	factory, ok := emailProviders[name]
+	emailProvidersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown email provider %q", name)
+	}
+	return factory(c)
+}
+
+// initEmailProvider sets up the provider used by SendEmail
+func initEmailProvider(c *config.Config) {
+	emailProvider, emailProviderErr = selectEmailProvider(c)
+	if emailProviderErr != nil {
+		log.Printf("Email disabled: %v", emailProviderErr)
+		return
+	}
+	log.Printf("Sending email via %s", emailProvider.Name())
+}
+
+// SendEmail delivers an email through the configured provider
+func SendEmail(ctx context.Context, email Email) error {
+	if emailProviderErr != nil {
+		return emailProviderErr
+	}
+	if emailProvider == nil {
+		return fmt.Errorf("email provider not initialized")
+	}
+	if len(email.To) == 0 {
+		return fmt.Errorf("email has no recipients")
+	}
+	return emailProvider.Send(ctx, email)
+}
+
+// PartialSendError means some batches of an email were delivered and others failed.
+// Retries should go only to the recipients not in Delivered.
+type PartialSendError struct {
+	Delivered []string
+	Err       error
+}
+
+func (e *PartialSendError) Error() string {
+	return fmt.Sprintf("delivered to %d recipients, the rest failed: %v", len(e.Delivered), e.Err)
+}
+
+func (e *PartialSendError) Unwrap() error { return e.Err }
+
+// sendBatches sends every batch and fails with the first error. Once a batch was
+// delivered the error is a *PartialSendError listing the delivered recipients.
+func sendBatches(recipients []string, size int, send func(batch []string) error) error {
+	var delivered []string
+	var firstErr error
+	for _, batch := range batches(recipients, size) {
+		if err := send(batch); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		delivered = append(delivered, batch...)
+	}
+	if firstErr == nil {
+		return nil
+	}
+	if len(delivered) > 0 {
+		return &PartialSendError{Delivered: delivered, Err: firstErr}
+	}
+	return firstErr
+}
+
+// batches splits recipients into chunks of at most size
+func batches(recipients []string, size int) [][]string {
+	var chunks [][]string
+	for len(recipients) > size {
+		chunks = append(chunks, recipients[:size])
+		recipients = recipients[size:]
+	}
+	if len(recipients) > 0 {
+		chunks = append(chunks, recipients)
+	}
+	return chunks
+}
+
+// smtpProvider sends one SMTP transaction per batch (supports Mailpit with no auth)
+type smtpProvider struct {
+	host     string
+	port     string
+	user     string
+	password string
+	from     string
+}
+
+func newSMTPProvider(c *config.Config) (EmailProvider, error) {
+	if c.SMTPHost == "" {
+		return nil, fmt.Errorf("SMTP_HOST is required for the smtp email provider")
+	}
+	return &smtpProvider{host: c.SMTPHost, port: c.SMTPPort, user: c.SMTPUser, password: c.SMTPPassword, from: c.SMTPFrom}, nil
+}
+
+func (p *smtpProvider) Name() string { return EmailProviderSMTP }
+
+func (p *smtpProvider) Send(ctx context.Context, email Email) error {
+	return sendBatches(email.To, smtpBatchSize, func(batch []string) error {
+		msg, err := buildMIMEMessage(p.from, batch, email)
+		if err != nil {
+			return err
+		}
+		accepted, err := p.sendBatch(ctx, batch, msg)
+		if err != nil {
+			return fmt.Errorf("smtp error: %w", err)
+		}
+		log.Printf("Email sent via SMTP to %d of %d recipients", accepted, len(batch))
+		return nil
+	})
+}
+
+// sendBatch delivers one message to several recipients in a single transaction and
+// returns how many were accepted. Recipients the server rejects are skipped; the
+// batch fails only when none is accepted.
+func (p *smtpProvider) sendBatch(ctx context.Context, recipients []string, msg []byte) (int, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(p.host, p.port))
+	if err != nil {
+		return 0, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+		return 0, err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: p.host}); err != nil {
+			return 0, err
+		}
+	}
+	// Use auth only if credentials are provided (Mailpit doesn't need auth)
+	if p.user != "" && p.password != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.user, p.password, p.host)); err != nil {
+			return 0, err
+		}
+	}
+	if err := client.Mail(p.from); err != nil {
+		return 0, err
+	}
+	accepted := 0
+	var rejected error
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			var reply *textproto.Error
+			if !errors.As(err, &reply) {
+				return 0, err
+			}
+			log.Printf("SMTP recipient %s rejected: %v", recipient, err)
+			rejected = fmt.Errorf("recipient %s rejected: %w", recipient, err)
+			continue
+		}
+		accepted++
+	}
+	if accepted == 0 {
+		return 0, rejected
+	}
+	w, err := client.Data()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	// The message is accepted once DATA completes; a failed QUIT doesn't undo that
+	client.Quit()
+	return accepted, nil
+}
+
+// buildMIMEMessage renders a text-only or multipart/alternative message. Batches
+// with several recipients are addressed to "undisclosed-recipients" so nobody
+// sees the rest of the list.
+func buildMIMEMessage(from string, recipients []string, email Email) ([]byte, error) {
+	to := "undisclosed-recipients:;"
+	if len(recipients) == 1 {
+		to = recipients[0]
+	}
+	headers := map[string]string{
+		"From":         mime.QEncoding.Encode("utf-8", emailFromName) + " <" + from + ">",
+		"To":           to,
+		"Subject":      mime.QEncoding.Encode("utf-8", singleLine(email.Subject)),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-ID":   messageID(from),
+		"MIME-Version": "1.0",
+	}
+
+	var body bytes.Buffer
+	if email.HTML == "" {
+		headers["Content-Type"] = "text/plain; charset=utf-8"
+		headers["Content-Transfer-Encoding"] = "quoted-printable"
+		if err := writeQuotedPrintable(&body, email.Text); err != nil {
+			return nil, err
+		}
+	} else {
+		writer := multipart.NewWriter(&body)
+		headers["Content-Type"] = "multipart/alternative; boundary=" + writer.Boundary()
+		for _, part := range []struct{ contentType, content string }{
+			{"text/plain; charset=utf-8", email.Text},
+			{"text/html; charset=utf-8", email.HTML},
+		} {
+			pw, err := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(pw, part.content); err != nil {
+				return nil, err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	var msg bytes.Buffer
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&msg, "%s: %s\r\n", k, headers[k])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID generates a unique Message-ID on the sender's domain
+func messageID(from string) string {
+	domain := "lighthouse.local"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
+
+// sendGridProvider sends one API request per batch with a personalization per recipient
+type sendGridProvider struct {
+	client *sendgrid.Client
+	from   string
+}
+
+func newSendGridProvider(c *config.Config) (EmailProvider, error) {
+	if c.SendGridKey == "" {
+		return nil, fmt.Errorf("SendGrid API key required for the sendgrid email provider")
+	}
+	return &sendGridProvider{client: sendgrid.NewSendClient(c.SendGridKey), from: c.SMTPFrom}, nil
+}
+
+func (p *sendGridProvider) Name() string { return EmailProviderSendGrid }
+
+func (p *sendGridProvider) Send(ctx context.Context, email Email) error {
+	return sendBatches(email.To, sendGridBatchSize, func(batch []string) error {
+		message := mail.NewV3Mail()
+		message.SetFrom(mail.NewEmail(emailFromName, p.from))
+		message.Subject = singleLine(email.Subject)
+		message.AddContent(mail.NewContent("text/plain", email.Text))
+		if email.HTML != "" {
+			message.AddContent(mail.NewContent("text/html", email.HTML))
+		}
+		for _, recipient := range batch {
+			personalization := mail.NewPersonalization()
+			personalization.AddTos(mail.NewEmail("", recipient))
+			message.AddPersonalizations(personalization)
+		}
+		resp, err := p.client.SendWithContext(ctx, message)
+		if err != nil {
+			return fmt.Errorf("sendgrid error: %w", err)
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("sendgrid returned status %d: %s", resp.StatusCode, resp.Body)
+		}
+		log.Printf("Email sent via SendGrid to %d recipients", len(batch))
+		return nil
+	})
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// InviteEmail describes an invitation to join an organization
+type InviteEmail struct {
+	To        string
+	OrgName   string
+	InvitedBy string // Inviter's email
+	Role      string
+	Link      string // Absolute, or a path on the frontend
+	ExpiresAt time.Time
+}
+
+var inviteTextTemplate = template.Must(template.New("invite_text").Parse(`{{.InvitedBy}} invited you to join {{.OrgName}} on Light House as {{.Role}}.
+
+Accept the invite: {{.Link}}
+
+This invite expires on {{.ExpiresAt.Format "Mon, 02 Jan 2006 15:04 MST"}}.`))
+
+var inviteHTMLTemplate = htmltemplate.Must(htmltemplate.New("invite_html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #1d1d1f;">
+  <p><strong>{{.InvitedBy}}</strong> invited you to join <strong>{{.OrgName}}</strong> on Light House as {{.Role}}.</p>
+  <p><a href="{{.Link}}" style="display: inline-block; padding: 10px 16px; background: #1d1d1f; color: #ffffff; text-decoration: none; border-radius: 6px;">Accept invite</a></p>
+  <p style="color: #6e6e73;">This invite expires on {{.ExpiresAt.Format "Mon, 02 Jan 2006 15:04 MST"}}.</p>
+</body>
+</html>`))
+
+// frontendLink turns a frontend path into an absolute URL when the frontend URL is known
+func frontendLink(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	if cfg == nil || cfg.FrontendURL == "" {
+		return path
+	}
+	return strings.TrimRight(cfg.FrontendURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// SendInviteEmail sends a multipart invitation email
+func SendInviteEmail(ctx context.Context, invite InviteEmail) error {
+	invite.Link = frontendLink(invite.Link)
+	var text, html bytes.Buffer
+	if err := inviteTextTemplate.Execute(&text, invite); err != nil {
+		return fmt.Errorf("failed to render invite email: %w", err)
+	}
+	if err := inviteHTMLTemplate.Execute(&html, invite); err != nil {
+		return fmt.Errorf("failed to render invite email: %w", err)
+	}
+	return SendEmail(ctx, Email{
+		To:      []string{invite.To},
+		Subject: fmt.Sprintf("You're invited to join %s on Light House", invite.OrgName),
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
@@ -0,0 +1,213 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/oFuterman/light-house/internal/models"
+)
+
+// DefaultPagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty channel config:
+//
+//	{"routing_key": "<integration key>", "events_url": "<optional override>", "severity": "critical"}
+//
+// events_url exists so the integration can be pointed at a local stand-in.
+func init() {
+	RegisterChannelType(models.ChannelTypePagerDuty, newPagerDutyChannel)
+}
+
+var pagerDutySeverities = map[string]bool{"critical": true, "error": true, "warning": true, "info": true}
+
+// pagerDutyEvent is the Events API v2 request body
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger or resolve
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // Not needed for resolve
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Group         string                 `json:"group,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// pagerDutyChannel triggers an incident on DOWN and resolves it on RECOVERY
+type pagerDutyChannel struct {
+	routingKey string
+	eventsURL  string
+	severity   string
+}
+
+func newPagerDutyChannel(ch models.NotificationChannel) (Channel, error) {
+	config := ch.Config
+	routingKey, _ := config["routing_key"].(string)
+	routingKey = strings.TrimSpace(routingKey)
+	if routingKey == "" {
+		return nil, fmt.Errorf("routing_key is required")
+	}
+
+	eventsURL := DefaultPagerDutyEventsURL
+	if _, ok := config["events_url"]; ok {
+		var err error
+		if eventsURL, err = channelURL(config, "events_url"); err != nil {
+			return nil, err
+		}
+	}
+
+	severity := "critical"
+	if s, _ := config["severity"].(string); s != "" {
+		if !pagerDutySeverities[s] {
+			return nil, fmt.Errorf("severity must be critical, error, warning or info")
+		}
+		severity = s
+	}
+
+	return &pagerDutyChannel{routingKey: routingKey, eventsURL: eventsURL, severity: severity}, nil
+}
+
+// pagerDutyDedupKey is stable per check so a RECOVERY resolves the incident its DOWN opened
+func pagerDutyDedupKey(alert models.Alert, check models.Check, eventID string) string {
+	// Each test gets its own incident so it never merges with another test's
+	if alert.AlertType == models.AlertTypeTest {
+		return "lighthouse-test-" + eventID
+	}
+	// LOG_RESOLVED, TRACE_RESOLVED and EXTERNAL_RESOLVED resolve the incident their
+	// rule's or external alert's firing alert opened
+	if alert.ExternalAlertID != nil {
+		return fmt.Sprintf("lighthouse-external-%d", *alert.ExternalAlertID)
+	}
+	if alert.LogAlertRuleID != nil {
+		return fmt.Sprintf("lighthouse-log-rule-%d", *alert.LogAlertRuleID)
+	}
+	if alert.TraceAlertRuleID != nil {
+		return fmt.Sprintf("lighthouse-trace-rule-%d", *alert.TraceAlertRuleID)
+	}
+	alertType := alert.AlertType
+	if alertType == models.AlertTypeDown || alertType == models.AlertTypeRecovery {
+		return fmt.Sprintf("lighthouse-check-%d", check.ID)
+	}
+	// STABILIZED and LATENCY_NORMAL resolve the incident their FLAPPING or LATENCY_ANOMALY alert opened
+	switch alertType {
+	case models.AlertTypeStabilized:
+		alertType = models.AlertTypeFlapping
+	case models.AlertTypeLatencyNormal:
+		alertType = models.AlertTypeLatencyAnomaly
+	}
+	// Other alert types get their own incident so they never resolve an outage
+	return fmt.Sprintf("lighthouse-check-%d-%s", check.ID, strings.ToLower(string(alertType)))
+}
+
+// Send posts the event. A test notification's incident is resolved right after it is
+// triggered so nobody is left to close it by hand.
+func (p *pagerDutyChannel) Send(ctx context.Context, n Notification) (DeliveryReceipt, error) {
+	event := p.event(n)
+	receipt, err := postJSON(ctx, p.eventsURL, event)
+	if err != nil || n.Alert.AlertType != models.AlertTypeTest {
+		return receipt, err
+	}
+	resolve := pagerDutyEvent{RoutingKey: p.routingKey, EventAction: "resolve", DedupKey: event.DedupKey}
+	if _, err := postJSON(ctx, p.eventsURL, resolve); err != nil {
+		return receipt, fmt.Errorf("test incident %s was triggered but not resolved: %w", event.DedupKey, err)
+	}
+	return receipt, nil
+}
+
+// event builds the Events API v2 body for a notification
+func (p *pagerDutyChannel) event(n Notification) pagerDutyEvent {
+	event := pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "trigger",
+		DedupKey:    pagerDutyDedupKey(n.Alert, n.Check, n.EventID),
+	}
+	switch n.Alert.AlertType {
+	case models.AlertTypeRecovery, models.AlertTypeStabilized, models.AlertTypeLogResolved, models.AlertTypeTraceResolved,
+		models.AlertTypeLatencyNormal, models.AlertTypeExternalResolved:
+		event.EventAction = "resolve"
+		return event
+	}
+
+	severity := p.severity
+	if n.Alert.AlertType != models.AlertTypeDown {
+		severity = "warning"
+	}
+	// External alerts keep the severity label they were sent with when PagerDuty knows it
+	if s, ok := n.Check.Tags["severity"].(string); ok && n.Alert.ExternalAlertID != nil && pagerDutySeverities[s] {
+		severity = s
+	}
+
+	details := map[string]interface{}{
+		"alert_id":   n.Alert.ID,
+		"alert_type": n.Alert.AlertType,
+		"check_id":   n.Check.ID,
+		"check_name": n.Check.Name,
+		"url":        n.Check.URL,
+	}
+	source := n.Check.URL
+	if n.Alert.CheckID == nil {
+		// Rule and external alerts have no URL; PagerDuty requires a source
+		delete(details, "check_id")
+		delete(details, "url")
+		source = "lighthouse"
+		if n.Check.ServiceName != "" {
+			source = n.Check.ServiceName
+		}
+	}
+	if n.Alert.LogAlertRuleID != nil {
+		details["log_alert_rule_id"] = *n.Alert.LogAlertRuleID
+	}
+	if n.Alert.TraceAlertRuleID != nil {
+		details["trace_alert_rule_id"] = *n.Alert.TraceAlertRuleID
+	}
+	if n.Alert.ExternalAlertID != nil {
+		details["external_alert_id"] = *n.Alert.ExternalAlertID
+		if n.Alert.Link != "" {
+			details["source_url"] = n.Alert.Link
+		}
+	}
+	if len(n.Alert.TraceIDs) > 0 {
+		details["trace_ids"] = []string(n.Alert.TraceIDs)
+	}
+	if n.Alert.StatusCode > 0 {
+		details["status_code"] = n.Alert.StatusCode
+	}
+	if n.Alert.ErrorMessage != "" {
+		details["error_message"] = n.Alert.ErrorMessage
+	}
+	if n.Check.Region != "" {
+		details["region"] = n.Check.Region
+	}
+	for key, value := range n.Check.Tags {
+		details["tag."+key] = value
+	}
+
+	event.Payload = &pagerDutyPayload{
+		Summary:       alertHeadline(n.Alert, n.Check),
+		Source:        source,
+		Severity:      severity,
+		Timestamp:     n.Alert.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Component:     n.Check.ServiceName,
+		Group:         n.Check.Environment,
+		Class:         string(n.Alert.AlertType),
+		CustomDetails: details,
+	}
+	if link, label := alertLink(n); link != "" {
+		event.Links = []pagerDutyLink{{Href: link, Text: label}}
+	}
+	return event
+}
@@ -0,0 +1,221 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/oFuterman/light-house/internal/models"
+)
+
+// pagerDutyServer stands in for the Events API, answering each request with the next
+// status (the last one repeats) and recording the events it received
+type pagerDutyServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	events   []pagerDutyEvent
+}
+
+func newPagerDutyServer(t *testing.T, statuses ...int) *pagerDutyServer {
+	s := &pagerDutyServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("invalid event body: %v", err)
+		}
+		s.mu.Lock()
+		status := s.statuses[min(len(s.events), len(s.statuses)-1)]
+		s.events = append(s.events, event)
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func uintPtr(v uint) *uint { return &v }
+
+func TestPagerDutySend(t *testing.T) {
+	check := models.Check{ID: 7, Name: "API", URL: "https://api.example.com/health"}
+	type wantEvent struct {
+		action   string
+		dedupKey string
+		severity string // Empty for resolve events, which carry no payload
+	}
+	tests := []struct {
+		name       string
+		alert      models.Alert
+		statuses   []int
+		want       []wantEvent
+		wantErr    bool
+		wantStatus int
+	}{
+		{
+			name:       "down triggers",
+			alert:      models.Alert{ID: 1, CheckID: uintPtr(7), AlertType: models.AlertTypeDown, StatusCode: 503},
+			statuses:   []int{http.StatusAccepted},
+			want:       []wantEvent{{"trigger", "lighthouse-check-7", "critical"}},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "recovery resolves",
+			alert:      models.Alert{ID: 2, CheckID: uintPtr(7), AlertType: models.AlertTypeRecovery},
+			statuses:   []int{http.StatusAccepted},
+			want:       []wantEvent{{"resolve", "lighthouse-check-7", ""}},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "log alert triggers as a warning",
+			alert:      models.Alert{ID: 3, LogAlertRuleID: uintPtr(4), AlertType: models.AlertTypeLogAlert},
+			statuses:   []int{http.StatusAccepted},
+			want:       []wantEvent{{"trigger", "lighthouse-log-rule-4", "warning"}},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:     "test triggers then resolves",
+			alert:    models.Alert{ID: 5, AlertType: models.AlertTypeTest},
+			statuses: []int{http.StatusAccepted},
+			want: []wantEvent{
+				{"trigger", "lighthouse-test-evt_5_1", "warning"},
+				{"resolve", "lighthouse-test-evt_5_1", ""},
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "error status fails",
+			alert:      models.Alert{ID: 6, CheckID: uintPtr(7), AlertType: models.AlertTypeDown},
+			statuses:   []int{http.StatusBadRequest},
+			want:       []wantEvent{{"trigger", "lighthouse-check-7", "critical"}},
+			wantErr:    true,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rate limited fails",
+			alert:      models.Alert{ID: 7, CheckID: uintPtr(7), AlertType: models.AlertTypeDown},
+			statuses:   []int{http.StatusTooManyRequests},
+			want:       []wantEvent{{"trigger", "lighthouse-check-7", "critical"}},
+			wantErr:    true,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:     "failed test resolve fails",
+			alert:    models.Alert{ID: 8, AlertType: models.AlertTypeTest},
+			statuses: []int{http.StatusAccepted, http.StatusInternalServerError},
+			want: []wantEvent{
+				{"trigger", "lighthouse-test-evt_5_1", "warning"},
+				{"resolve", "lighthouse-test-evt_5_1", ""},
+			},
+			wantErr:    true,
+			wantStatus: http.StatusAccepted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPagerDutyServer(t, tt.statuses...)
+			channel, err := newPagerDutyChannel(models.NotificationChannel{
+				Type:   models.ChannelTypePagerDuty,
+				Config: models.JSONMap{"routing_key": "key-123", "events_url": server.URL},
+			})
+			if err != nil {
+				t.Fatalf("newPagerDutyChannel() error = %v", err)
+			}
+
+			receipt, err := channel.Send(context.Background(), Notification{Alert: tt.alert, Check: check, EventID: "evt_5_1"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if receipt.StatusCode != tt.wantStatus {
+				t.Errorf("Send() status = %d, want %d", receipt.StatusCode, tt.wantStatus)
+			}
+			if len(server.events) != len(tt.want) {
+				t.Fatalf("got %d events, want %d", len(server.events), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := server.events[i]
+				if got.RoutingKey != "key-123" {
+					t.Errorf("event %d routing_key = %q", i, got.RoutingKey)
+				}
+				if got.EventAction != want.action || got.DedupKey != want.dedupKey {
+					t.Errorf("event %d = %s %s, want %s %s", i, got.EventAction, got.DedupKey, want.action, want.dedupKey)
+				}
+				if want.severity == "" {
+					if got.Payload != nil {
+						t.Errorf("event %d has a payload, want none", i)
+					}
+				} else if got.Payload == nil || got.Payload.Severity != want.severity {
+					t.Errorf("event %d payload = %+v, want severity %s", i, got.Payload, want.severity)
+				}
+			}
+		})
+	}
+}
+
+func TestPagerDutyDedupKey(t *testing.T) {
+	check := models.Check{ID: 7}
+	tests := []struct {
+		name     string
+		firing   models.Alert
+		resolved models.Alert
+	}{
+		{
+			name:     "outage",
+			firing:   models.Alert{AlertType: models.AlertTypeDown},
+			resolved: models.Alert{AlertType: models.AlertTypeRecovery},
+		},
+		{
+			name:     "flapping",
+			firing:   models.Alert{AlertType: models.AlertTypeFlapping},
+			resolved: models.Alert{AlertType: models.AlertTypeStabilized},
+		},
+		{
+			name:     "latency anomaly",
+			firing:   models.Alert{AlertType: models.AlertTypeLatencyAnomaly},
+			resolved: models.Alert{AlertType: models.AlertTypeLatencyNormal},
+		},
+		{
+			name:     "log alert rule",
+			firing:   models.Alert{AlertType: models.AlertTypeLogAlert, LogAlertRuleID: uintPtr(4)},
+			resolved: models.Alert{AlertType: models.AlertTypeLogResolved, LogAlertRuleID: uintPtr(4)},
+		},
+		{
+			name:     "trace alert rule",
+			firing:   models.Alert{AlertType: models.AlertTypeTraceAlert, TraceAlertRuleID: uintPtr(5)},
+			resolved: models.Alert{AlertType: models.AlertTypeTraceResolved, TraceAlertRuleID: uintPtr(5)},
+		},
+		{
+			name:     "external alert",
+			firing:   models.Alert{AlertType: models.AlertTypeExternalFiring, ExternalAlertID: uintPtr(6)},
+			resolved: models.Alert{AlertType: models.AlertTypeExternalResolved, ExternalAlertID: uintPtr(6)},
+		},
+	}
+	seen := map[string]string{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Retries and the resolution carry other event IDs but must hit the same incident
+			key := pagerDutyDedupKey(tt.firing, check, "evt_1_1")
+			if retry := pagerDutyDedupKey(tt.firing, check, "evt_1_2"); retry != key {
+				t.Errorf("retry dedup key = %q, want %q", retry, key)
+			}
+			if resolved := pagerDutyDedupKey(tt.resolved, check, "evt_2_1"); resolved != key {
+				t.Errorf("resolution dedup key = %q, want %q", resolved, key)
+			}
+			if other, ok := seen[key]; ok {
+				t.Errorf("dedup key %q shared with %s", key, other)
+			}
+			seen[key] = tt.name
+		})
+	}
+
+	regression := pagerDutyDedupKey(models.Alert{AlertType: models.AlertTypeSecurityRegression}, check, "evt_3_1")
+	if regression == pagerDutyDedupKey(models.Alert{AlertType: models.AlertTypeDown}, check, "evt_3_1") {
+		t.Error("security regression shares the outage's dedup key")
+	}
+	test := models.Alert{AlertType: models.AlertTypeTest}
+	if pagerDutyDedupKey(test, check, "evt_4_1") == pagerDutyDedupKey(test, check, "evt_4_2") {
+		t.Error("test alerts share a dedup key")
+	}
+}
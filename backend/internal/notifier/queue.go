@@ -0,0 +1,306 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultMaxAttempts is how many times a delivery is tried before it is dead-lettered.
+	// With the backoff below the last retry happens roughly an hour after the alert.
+	DefaultMaxAttempts = 8
+	// retryBaseDelay is the wait after the first failure; it doubles per attempt
+	retryBaseDelay = 30 * time.Second
+	// retryMaxDelay caps the backoff between attempts
+	retryMaxDelay = time.Hour
+	// jobLease is how long a claimed job is reserved. If the dispatcher dies mid-send,
+	// another replica picks the job up once the lease expires.
+	jobLease = 5 * time.Minute
+	// sendTimeout bounds a single delivery attempt
+	sendTimeout = 30 * time.Second
+)
+
+// EnqueueAlert writes one delivery job per channel the alert is routed to, plus jobs
+// for users whose personal subscriptions match it. Call it with the transaction that
+// creates the alert so the alert is never stored without its jobs.
+func EnqueueAlert(tx *gorm.DB, alert models.Alert, check models.Check) (int, error) {
+	channels, err := RouteAlert(tx, alert, check)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	jobs := make([]models.NotificationJob, len(channels))
+	for i := range channels {
+		jobs[i] = models.NotificationJob{
+			OrgID:         alert.OrgID,
+			AlertID:       alert.ID,
+			ChannelID:     &channels[i].ID,
+			Status:        models.NotificationJobPending,
+			MaxAttempts:   DefaultMaxAttempts,
+			NextAttemptAt: now,
+		}
+	}
+	userJobs, err := subscriberJobs(tx, alert, check, channels, now)
+	if err != nil {
+		return 0, err
+	}
+	jobs = append(jobs, userJobs...)
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+	if err := tx.Create(&jobs).Error; err != nil {
+		return 0, fmt.Errorf("failed to enqueue notifications: %w", err)
+	}
+	return len(jobs), nil
+}
+
+// retryDelay returns the backoff before the next attempt after `attempts` failures
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// ClaimDueJobs leases up to limit jobs that are due, including jobs whose previous
+// lease expired. SKIP LOCKED lets several dispatchers drain the queue concurrently
+// without ever claiming the same job twice.
+func ClaimDueJobs(db *gorm.DB, limit int) ([]models.NotificationJob, error) {
+	var jobs []models.NotificationJob
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+				models.NotificationJobPending, now, models.NotificationJobProcessing, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = models.NotificationJobProcessing
+		}
+		return tx.Model(&models.NotificationJob{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       models.NotificationJobProcessing,
+			"locked_until": now.Add(jobLease),
+		}).Error
+	})
+	return jobs, err
+}
+
+// errChannelUnavailable means the job's channel was deleted or disabled after enqueueing
+var errChannelUnavailable = errors.New("notification channel was removed or disabled")
+
+// ProcessJob attempts one delivery, logs it as a NotificationDelivery and records the
+// outcome on the job: sent, rescheduled with backoff, dead-lettered, or skipped.
+func ProcessJob(db *gorm.DB, job models.NotificationJob) (*models.NotificationDelivery, error) {
+	delivery, sendErr := deliverJob(db, job)
+	if err := db.Create(delivery).Error; err != nil {
+		log.Printf("Failed to record delivery for notification job %d: %v", job.ID, err)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":     job.Attempts + 1,
+		"locked_until": nil,
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.NotificationJobSent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	case errors.Is(sendErr, errChannelUnavailable), errors.Is(sendErr, errUserUnavailable):
+		updates["status"] = models.NotificationJobSkipped
+		updates["last_error"] = sendErr.Error()
+	case job.Attempts+1 >= job.MaxAttempts:
+		updates["status"] = models.NotificationJobDead
+		updates["last_error"] = truncateError(sendErr)
+		log.Printf("Notification job %d dead-lettered after %d attempts: %v", job.ID, job.Attempts+1, sendErr)
+	default:
+		updates["status"] = models.NotificationJobPending
+		updates["next_attempt_at"] = now.Add(retryDelay(job.Attempts + 1))
+		updates["last_error"] = truncateError(sendErr)
+	}
+	var partial *PartialSendError
+	if errors.As(sendErr, &partial) {
+		updates["delivered_recipients"] = pq.StringArray(append(job.DeliveredRecipients, partial.Delivered...))
+	}
+	if err := db.Model(&models.NotificationJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		return delivery, fmt.Errorf("failed to update notification job %d: %w", job.ID, err)
+	}
+	return delivery, sendErr
+}
+
+// errUserUnavailable means the job's user was removed (or their personal webhook
+// cleared) before delivery
+var errUserUnavailable = errors.New("user or their personal webhook no longer exists")
+
+// jobTarget resolves the sender for a job's channel or user and fills in the
+// delivery's target fields
+func jobTarget(db *gorm.DB, job models.NotificationJob, delivery *models.NotificationDelivery) (Channel, string, error) {
+	if job.UserID != nil {
+		var user models.User
+		if err := db.Where("id = ? AND org_id = ?", *job.UserID, job.OrgID).First(&user).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, "", errUserUnavailable
+			}
+			return nil, "", err
+		}
+		if job.UserChannel == models.ChannelTypeWebhook {
+			var pref models.UserNotificationPreference
+			if err := db.Where("user_id = ?", user.ID).First(&pref).Error; err != nil || pref.WebhookURL == "" {
+				return nil, "", errUserUnavailable
+			}
+			delivery.ChannelType = models.ChannelTypeWebhook
+			delivery.ChannelName = user.Email + " (personal webhook)"
+			var secrets []string
+			if pref.WebhookSigningSecret != "" {
+				secrets = []string{pref.WebhookSigningSecret}
+			}
+			return &webhookChannel{url: pref.WebhookURL, secrets: secrets}, fmt.Sprintf("evt_%d_u%d_webhook", job.AlertID, user.ID), nil
+		}
+		delivery.ChannelType = models.ChannelTypeEmail
+		delivery.ChannelName = user.Email
+		return &emailChannel{recipients: []string{user.Email}}, fmt.Sprintf("evt_%d_u%d", job.AlertID, user.ID), nil
+	}
+	if job.ChannelID == nil {
+		return nil, "", errChannelUnavailable
+	}
+
+	var channel models.NotificationChannel
+	if err := db.Unscoped().Where("id = ?", *job.ChannelID).First(&channel).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, "", errChannelUnavailable
+		}
+		return nil, "", err
+	}
+	delivery.ChannelType = channel.Type
+	delivery.ChannelName = channel.Name
+	if channel.DeletedAt.Valid || !channel.Enabled {
+		return nil, "", errChannelUnavailable
+	}
+	sender, err := NewChannel(channel)
+	if err != nil {
+		return nil, "", err
+	}
+	return sender, NotificationEventID(job.AlertID, channel.ID), nil
+}
+
+// deliverJob loads everything the job references, sends it to its target and
+// returns the (unsaved) delivery log entry for the attempt
+func deliverJob(db *gorm.DB, job models.NotificationJob) (*models.NotificationDelivery, error) {
+	delivery := &models.NotificationDelivery{
+		OrgID:       job.OrgID,
+		AlertID:     job.AlertID,
+		ChannelID:   job.ChannelID,
+		UserID:      job.UserID,
+		JobID:       &job.ID,
+		Attempt:     job.Attempts + 1,
+		RequestedBy: job.RequestedBy,
+	}
+	fail := func(err error) (*models.NotificationDelivery, error) {
+		delivery.Error = truncateError(err)
+		return delivery, err
+	}
+
+	sender, eventID, err := jobTarget(db, job, delivery)
+	if err != nil {
+		return fail(err)
+	}
+	if email, ok := sender.(*emailChannel); ok && len(job.DeliveredRecipients) > 0 {
+		sender = email.without(job.DeliveredRecipients)
+	}
+
+	var alert models.Alert
+	if err := db.First(&alert, job.AlertID).Error; err != nil {
+		return fail(fmt.Errorf("failed to load alert: %w", err))
+	}
+	check, err := alertSubject(db, alert)
+	if err != nil {
+		return fail(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	start := time.Now()
+	receipt, err := sender.Send(ctx, NewNotification(db, alert, check, eventID))
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	delivery.Request = utils.Truncate(receipt.Request, 1024) // Email requests list every recipient
+	delivery.StatusCode = receipt.StatusCode
+	if err != nil {
+		return fail(err)
+	}
+	delivery.Success = true
+	return delivery, nil
+}
+
+// EnqueueUserNotifications writes one delivery job per user, e.g. for an escalation level
+func EnqueueUserNotifications(tx *gorm.DB, alert models.Alert, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	jobs := make([]models.NotificationJob, len(userIDs))
+	for i := range userIDs {
+		jobs[i] = models.NotificationJob{
+			OrgID:         alert.OrgID,
+			AlertID:       alert.ID,
+			UserID:        &userIDs[i],
+			Status:        models.NotificationJobPending,
+			MaxAttempts:   DefaultMaxAttempts,
+			NextAttemptAt: now,
+		}
+	}
+	if err := tx.Create(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to enqueue user notifications: %w", err)
+	}
+	return nil
+}
+
+// Redeliver immediately retries a previous delivery's alert to the same channel or
+// user, outside of the normal retry schedule. It goes through a single-attempt job
+// so it is logged like any other delivery.
+func Redeliver(db *gorm.DB, alert models.Alert, previous models.NotificationDelivery, requestedBy uint) (*models.NotificationDelivery, error) {
+	lease := time.Now().Add(jobLease)
+	job := models.NotificationJob{
+		OrgID:         alert.OrgID,
+		AlertID:       alert.ID,
+		ChannelID:     previous.ChannelID,
+		UserID:        previous.UserID,
+		Status:        models.NotificationJobProcessing,
+		MaxAttempts:   1,
+		NextAttemptAt: time.Now(),
+		LockedUntil:   &lease,
+		RequestedBy:   &requestedBy,
+	}
+	if previous.UserID != nil {
+		job.UserChannel = previous.ChannelType
+	}
+	if err := db.Create(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create redelivery job: %w", err)
+	}
+	return ProcessJob(db, job)
+}
+
+// truncateError fits an error message into the LastError column
+func truncateError(err error) string {
+	return utils.Truncate(err.Error(), 1024)
+}
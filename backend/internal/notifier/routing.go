@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"fmt"
+
+	"github.com/oFuterman/light-house/internal/groups"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// RouteAlert returns the enabled channels an alert should be delivered to.
+// Orgs without routing rules broadcast to every enabled channel. Once any rule
+// exists, only channels picked by matching rules are used.
+func RouteAlert(db *gorm.DB, alert models.Alert, check models.Check) ([]models.NotificationChannel, error) {
+	var rules []models.RoutingRule
+	if err := db.Where("org_id = ? AND enabled = ?", check.OrgID, true).
+		Order("position ASC, id ASC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load routing rules: %w", err)
+	}
+
+	query := db.Where("org_id = ? AND enabled = ?", check.OrgID, true)
+	if len(rules) > 0 {
+		var checkGroupIDs []uint
+		if check.GroupID != nil {
+			ids, err := groups.AncestorIDs(db, *check.GroupID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load check groups: %w", err)
+			}
+			checkGroupIDs = ids
+		}
+		channelIDs := matchRoutingRules(rules, alert, check, checkGroupIDs)
+		if len(channelIDs) == 0 {
+			return nil, nil
+		}
+		query = query.Where("id IN ?", channelIDs)
+	}
+
+	var channels []models.NotificationChannel
+	if err := query.Order("id ASC").Find(&channels).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notification channels: %w", err)
+	}
+	return channels, nil
+}
+
+// matchRoutingRules evaluates ordered rules and collects the channel IDs of every match.
+// checkGroupIDs are the check's group and all of its ancestors.
+func matchRoutingRules(rules []models.RoutingRule, alert models.Alert, check models.Check, checkGroupIDs []uint) []uint {
+	seen := map[uint]bool{}
+	var channelIDs []uint
+	for _, rule := range rules {
+		if !rule.Matches(alert, check, checkGroupIDs) {
+			continue
+		}
+		for _, id := range rule.ChannelIDs {
+			if !seen[uint(id)] {
+				seen[uint(id)] = true
+				channelIDs = append(channelIDs, uint(id))
+			}
+		}
+		if rule.StopProcessing {
+			break
+		}
+	}
+	return channelIDs
+}
@@ -0,0 +1,123 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/groups"
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// userLocation is where a user's quiet hours are evaluated: their own timezone,
+// else the org's, else UTC
+func userLocation(pref models.UserNotificationPreference, org models.Organization) *time.Location {
+	for _, name := range []string{pref.Timezone, org.Timezone} {
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// routedEmailRecipients collects the addresses the routed email channels already reach
+func routedEmailRecipients(channels []models.NotificationChannel) map[string]bool {
+	recipients := map[string]bool{}
+	for _, ch := range channels {
+		if ch.Type != models.ChannelTypeEmail {
+			continue
+		}
+		raw, _ := ch.Config["recipients"].([]interface{})
+		for _, r := range raw {
+			if s, ok := r.(string); ok {
+				recipients[strings.ToLower(strings.TrimSpace(s))] = true
+			}
+		}
+	}
+	return recipients
+}
+
+// isCritical reports whether an alert bypasses quiet hours. External alerts that the
+// source labelled with a low severity wait like resolutions do.
+func isCritical(alert models.Alert, check models.Check) bool {
+	if !alert.AlertType.IsCritical() {
+		return false
+	}
+	if alert.ExternalAlertID != nil {
+		severity, _ := check.Tags["severity"].(string)
+		switch strings.ToLower(severity) {
+		case "warning", "info", "none":
+			return false
+		}
+	}
+	return true
+}
+
+// subscriberJobs builds delivery jobs for users whose personal subscriptions match the
+// alert. They add to the org's routing rather than replace it: users are not emailed a
+// second copy when a routed email channel already reaches them. Non-critical alerts
+// raised during a user's quiet hours are scheduled for when the quiet hours end.
+func subscriberJobs(db *gorm.DB, alert models.Alert, check models.Check, routed []models.NotificationChannel, now time.Time) ([]models.NotificationJob, error) {
+	var prefs []models.UserNotificationPreference
+	if err := db.Preload("User").
+		Joins("JOIN users ON users.id = user_notification_preferences.user_id AND users.deleted_at IS NULL").
+		Where("user_notification_preferences.org_id = ?", check.OrgID).
+		Find(&prefs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+	if len(prefs) == 0 {
+		return nil, nil
+	}
+
+	var checkGroupIDs []uint
+	if check.GroupID != nil {
+		ids, err := groups.AncestorIDs(db, *check.GroupID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load check groups: %w", err)
+		}
+		checkGroupIDs = ids
+	}
+
+	var org models.Organization
+	if err := db.First(&org, check.OrgID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load organization: %w", err)
+	}
+
+	alreadyEmailed := routedEmailRecipients(routed)
+	var jobs []models.NotificationJob
+	for i := range prefs {
+		pref := prefs[i]
+		if !pref.Matches(alert, check, checkGroupIDs) {
+			continue
+		}
+		sendAt := now
+		if !isCritical(alert, check) {
+			if until := pref.QuietUntil(userLocation(pref, org), now); !until.IsZero() {
+				sendAt = until
+			}
+		}
+		job := models.NotificationJob{
+			OrgID:         alert.OrgID,
+			AlertID:       alert.ID,
+			UserID:        &prefs[i].UserID,
+			Status:        models.NotificationJobPending,
+			MaxAttempts:   DefaultMaxAttempts,
+			NextAttemptAt: sendAt,
+		}
+		if pref.EmailEnabled && !alreadyEmailed[strings.ToLower(pref.User.Email)] {
+			email := job
+			email.UserChannel = models.ChannelTypeEmail
+			jobs = append(jobs, email)
+		}
+		if pref.WebhookURL != "" {
+			hook := job
+			hook.UserChannel = models.ChannelTypeWebhook
+			jobs = append(jobs, hook)
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,394 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+)
+
+// Default templates reproduce the built-in notifications. They are used whenever
+// an org has no custom template, or its custom template fails to render.
+const (
+	DefaultEmailSubjectTemplate = `[{{.Alert.Type}}] {{.Alert.Headline}}`
+	DefaultEmailTextTemplate    = `{{.Alert.Headline}}{{if .Alert.StatusCode}} ({{.Alert.StatusCode}}){{end}}
+{{- if .Alert.ErrorMessage}}
+
+Error: {{.Alert.ErrorMessage}}{{end}}
+
+{{if .Check.URL}}URL: {{.Check.URL}}
+{{end}}Time: {{.Alert.CreatedAt.Format "Mon, 02 Jan 2006 15:04:05 MST"}}`
+	DefaultEmailHTMLTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #1d1d1f;">
+  <h2 style="margin: 0 0 12px;">{{.Alert.Headline}}{{if .Alert.StatusCode}} ({{.Alert.StatusCode}}){{end}}</h2>
+  {{- if .Alert.ErrorMessage}}
+  <p><strong>Error:</strong> {{.Alert.ErrorMessage}}</p>
+  {{- end}}
+  <p>{{if .Check.URL}}<strong>URL:</strong> <a href="{{.Check.URL}}">{{.Check.URL}}</a><br>
+  {{end}}<strong>Time:</strong> {{.Alert.CreatedAt.Format "Mon, 02 Jan 2006 15:04:05 MST"}}</p>
+  {{- if .Check.Link}}
+  <p><a href="{{.Check.Link}}">Open in Light House</a></p>
+  {{- end}}
+</body>
+</html>`
+)
+
+// maxTemplateBytes caps the size of a single custom template
+const maxTemplateBytes = 64 * 1024
+
+// Limits on rendering a template, so a custom one can't exhaust memory or stall delivery
+const (
+	maxRenderedBytes = 256 * 1024
+	renderTimeout    = 2 * time.Second
+)
+
+var (
+	errRenderTooLarge = fmt.Errorf("rendered template is larger than %d bytes", maxRenderedBytes)
+	errRenderTimeout  = fmt.Errorf("rendering the template took longer than %s", renderTimeout)
+)
+
+// TemplateData is what notification templates can reference, e.g. {{.Check.Name}}
+type TemplateData struct {
+	Alert    TemplateAlert
+	Check    TemplateCheck
+	Org      TemplateOrg
+	Incident *TemplateIncident // nil when the alert is not part of an incident
+	EventID  string
+}
+
+type TemplateAlert struct {
+	ID           uint
+	Type         string // DOWN, RECOVERY, ...
+	Headline     string // e.g. "API is DOWN"
+	StatusCode   int
+	ErrorMessage string
+	CreatedAt    time.Time
+}
+
+type TemplateCheck struct {
+	ID          uint
+	Name        string
+	URL         string
+	ServiceName string
+	Environment string
+	Region      string
+	Tags        map[string]interface{}
+	Link        string // Dashboard link (the log search for log alerts), empty if the frontend URL is not configured
+}
+
+type TemplateOrg struct {
+	ID   uint
+	Name string
+	Slug string
+}
+
+type TemplateIncident struct {
+	ID              uint
+	Title           string
+	Status          string
+	StartedAt       time.Time
+	AcknowledgedAt  *time.Time
+	ResolvedAt      *time.Time
+	DurationSeconds *int64
+}
+
+// templateFuncs are available in every template
+var templateFuncs = map[string]interface{}{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// newTemplateData flattens a notification into the stable template data shape
+func newTemplateData(n Notification) TemplateData {
+	link, _ := alertLink(n)
+	data := TemplateData{
+		Alert: TemplateAlert{
+			ID:           n.Alert.ID,
+			Type:         string(n.Alert.AlertType),
+			Headline:     alertHeadline(n.Alert, n.Check),
+			StatusCode:   n.Alert.StatusCode,
+			ErrorMessage: n.Alert.ErrorMessage,
+			CreatedAt:    n.Alert.CreatedAt,
+		},
+		Check: TemplateCheck{
+			ID:          n.Check.ID,
+			Name:        n.Check.Name,
+			URL:         n.Check.URL,
+			ServiceName: n.Check.ServiceName,
+			Environment: n.Check.Environment,
+			Region:      n.Check.Region,
+			Tags:        n.Check.Tags,
+			Link:        link,
+		},
+		Org: TemplateOrg{
+			ID:   n.Org.ID,
+			Name: n.Org.Name,
+			Slug: n.Org.Slug,
+		},
+		EventID: n.EventID,
+	}
+	if n.Incident != nil {
+		data.Incident = &TemplateIncident{
+			ID:              n.Incident.ID,
+			Title:           n.Incident.Title,
+			Status:          string(n.Incident.Status),
+			StartedAt:       n.Incident.StartedAt,
+			AcknowledgedAt:  n.Incident.AcknowledgedAt,
+			ResolvedAt:      n.Incident.ResolvedAt,
+			DurationSeconds: n.Incident.DurationSeconds,
+		}
+	}
+	return data
+}
+
+// renderBuffer collects template output and fails the write that takes it past
+// maxRenderedBytes or its deadline, which aborts execution
+type renderBuffer struct {
+	buf      bytes.Buffer
+	deadline time.Time
+}
+
+func (b *renderBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > maxRenderedBytes {
+		return 0, errRenderTooLarge
+	}
+	if time.Now().After(b.deadline) {
+		return 0, errRenderTimeout
+	}
+	return b.buf.Write(p)
+}
+
+// execute runs a parsed template within the render limits. A template that loops
+// without writing can't be interrupted, so it is abandoned at the deadline.
+func execute(tmpl interface {
+	Execute(io.Writer, interface{}) error
+}, data TemplateData) (string, error) {
+	out := &renderBuffer{deadline: time.Now().Add(renderTimeout)}
+	done := make(chan error, 1)
+	go func() { done <- tmpl.Execute(out, data) }()
+
+	timer := time.NewTimer(renderTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", err
+		}
+		return out.buf.String(), nil
+	case <-timer.C:
+		return "", errRenderTimeout
+	}
+}
+
+// executeText renders a text/template
+func executeText(name, src string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return execute(tmpl, data)
+}
+
+// executeHTML renders an html/template, escaping every value
+func executeHTML(name, src string, data TemplateData) (string, error) {
+	tmpl, err := htmltemplate.New(name).Funcs(templateFuncs).Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return execute(tmpl, data)
+}
+
+// singleLine collapses line breaks so a rendered subject can't inject headers
+func singleLine(s string) string {
+	return strings.TrimSpace(strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == '\r' || r == '\n'
+	}), " "))
+}
+
+// customTemplates returns the notification's custom templates, or an empty set
+func customTemplates(n Notification) models.NotificationTemplate {
+	if n.Templates == nil {
+		return models.NotificationTemplate{}
+	}
+	return *n.Templates
+}
+
+// renderEmailStrict renders the email templates, returning the first error
+func renderEmailStrict(n Notification) (Email, error) {
+	custom := customTemplates(n)
+	data := newTemplateData(n)
+	var email Email
+	var err error
+
+	subject := DefaultEmailSubjectTemplate
+	if custom.EmailSubject != "" {
+		subject = custom.EmailSubject
+	}
+	if email.Subject, err = executeText("email_subject", subject, data); err != nil {
+		return email, fmt.Errorf("email_subject: %w", err)
+	}
+	email.Subject = singleLine(email.Subject)
+	if email.Subject == "" {
+		return email, fmt.Errorf("email_subject: rendered subject is empty")
+	}
+
+	text := DefaultEmailTextTemplate
+	if custom.EmailText != "" {
+		text = custom.EmailText
+	}
+	if email.Text, err = executeText("email_text", text, data); err != nil {
+		return email, fmt.Errorf("email_text: %w", err)
+	}
+
+	html := DefaultEmailHTMLTemplate
+	if custom.EmailHTML != "" {
+		html = custom.EmailHTML
+	}
+	if email.HTML, err = executeHTML("email_html", html, data); err != nil {
+		return email, fmt.Errorf("email_html: %w", err)
+	}
+	return email, nil
+}
+
+// renderEmail renders the email for a notification. A broken custom template
+// never blocks an alert: it is logged and the defaults are used instead.
+func renderEmail(n Notification) Email {
+	email, err := renderEmailStrict(n)
+	if err == nil {
+		return email
+	}
+	log.Printf("Custom email template for org %d failed, using default: %v", n.Alert.OrgID, err)
+	n.Templates = nil
+	email, err = renderEmailStrict(n)
+	if err != nil {
+		// The defaults only reference fields that always exist
+		log.Printf("Default email template failed for alert %d: %v", n.Alert.ID, err)
+	}
+	return email
+}
+
+// renderWebhookBodyStrict renders the webhook JSON body, returning any error
+func renderWebhookBodyStrict(n Notification) ([]byte, error) {
+	custom := customTemplates(n)
+	if custom.WebhookBody == "" {
+		payload := newWebhookPayload(n.Alert, n.Check)
+		payload.EventID = n.EventID
+		return json.Marshal(payload)
+	}
+	body, err := executeText("webhook_body", custom.WebhookBody, newTemplateData(n))
+	if err != nil {
+		return nil, fmt.Errorf("webhook_body: %w", err)
+	}
+	if !json.Valid([]byte(body)) {
+		return nil, fmt.Errorf("webhook_body: rendered body is not valid JSON")
+	}
+	return []byte(body), nil
+}
+
+// renderWebhookBody renders the webhook body, falling back to the default payload
+func renderWebhookBody(n Notification) ([]byte, error) {
+	body, err := renderWebhookBodyStrict(n)
+	if err == nil || n.Templates == nil || n.Templates.WebhookBody == "" {
+		return body, err
+	}
+	log.Printf("Custom webhook template for org %d failed, using default: %v", n.Alert.OrgID, err)
+	n.Templates = nil
+	return renderWebhookBodyStrict(n)
+}
+
+// TemplatePreview is every notification format rendered for one notification
+type TemplatePreview struct {
+	EmailSubject string `json:"email_subject"`
+	EmailText    string `json:"email_text"`
+	EmailHTML    string `json:"email_html"`
+	WebhookBody  string `json:"webhook_body"`
+}
+
+// PreviewTemplates renders templates against a notification without falling back,
+// so template errors are reported
+func PreviewTemplates(templates models.NotificationTemplate, n Notification) (TemplatePreview, error) {
+	n.Templates = &templates
+	email, err := renderEmailStrict(n)
+	if err != nil {
+		return TemplatePreview{}, err
+	}
+	body, err := renderWebhookBodyStrict(n)
+	if err != nil {
+		return TemplatePreview{}, err
+	}
+	return TemplatePreview{
+		EmailSubject: email.Subject,
+		EmailText:    email.Text,
+		EmailHTML:    email.HTML,
+		WebhookBody:  string(body),
+	}, nil
+}
+
+// ValidateTemplates checks custom templates parse and render against sample data
+func ValidateTemplates(templates models.NotificationTemplate, org models.Organization) error {
+	for name, src := range map[string]string{
+		"email_subject": templates.EmailSubject,
+		"email_text":    templates.EmailText,
+		"email_html":    templates.EmailHTML,
+		"webhook_body":  templates.WebhookBody,
+	} {
+		if len(src) > maxTemplateBytes {
+			return fmt.Errorf("%s: template is too large (max %d bytes)", name, maxTemplateBytes)
+		}
+	}
+	// Render both with and without an incident so optional fields are guarded
+	sample := SampleNotification(org)
+	if _, err := PreviewTemplates(templates, sample); err != nil {
+		return err
+	}
+	sample.Incident = nil
+	_, err := PreviewTemplates(templates, sample)
+	return err
+}
+
+// SampleNotification is a realistic DOWN notification for previews and validation
+func SampleNotification(org models.Organization) Notification {
+	now := time.Now().UTC().Truncate(time.Second)
+	incidentID := uint(1)
+	check := models.Check{
+		ID:          1,
+		OrgID:       org.ID,
+		Name:        "Example API",
+		URL:         "https://api.example.com/health",
+		ServiceName: "api",
+		Environment: "production",
+		Region:      "us-east-1",
+		Tags:        models.JSONMap{"team": "platform"},
+	}
+	alert := models.Alert{
+		ID:           1,
+		CreatedAt:    now,
+		OrgID:        org.ID,
+		CheckID:      &check.ID,
+		AlertType:    models.AlertTypeDown,
+		StatusCode:   503,
+		ErrorMessage: "Service Unavailable",
+		IncidentID:   &incidentID,
+	}
+	incident := models.NewIncident(alert, check)
+	incident.ID = incidentID
+	return Notification{
+		Alert:    alert,
+		Check:    check,
+		Org:      org,
+		Incident: &incident,
+		EventID:  NotificationEventID(alert.ID, 1),
+	}
+}
@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// TestResult is the outcome of sending a test notification through one channel
+type TestResult struct {
+	ChannelID   uint                           `json:"channel_id"`
+	ChannelName string                         `json:"channel_name"`
+	ChannelType models.NotificationChannelType `json:"channel_type"`
+	Success     bool                           `json:"success"`
+	StatusCode  int                            `json:"status_code,omitempty"`
+	Request     string                         `json:"request,omitempty"`
+	Error       string                         `json:"error,omitempty"`
+	DurationMs  int64                          `json:"duration_ms"`
+}
+
+// NewTestNotification builds a synthetic alert that is clearly labelled as a test,
+// rendered with the org's templates like a real one
+func NewTestNotification(db *gorm.DB, org models.Organization) Notification {
+	now := time.Now().UTC().Truncate(time.Second)
+	check := models.Check{
+		OrgID: org.ID,
+		Name:  "Light House test notification",
+		URL:   "https://example.com/light-house-test",
+	}
+	alert := models.Alert{
+		CreatedAt:    now,
+		OrgID:        org.ID,
+		AlertType:    models.AlertTypeTest,
+		ErrorMessage: "This is a test notification. No check is down and no action is needed.",
+	}
+	n := Notification{Alert: alert, Check: check, Org: org}
+	var templates models.NotificationTemplate
+	if err := db.Where("org_id = ?", org.ID).First(&templates).Error; err == nil {
+		n.Templates = &templates
+	}
+	return n
+}
+
+// SendTestNotifications delivers n through every channel concurrently and waits for the
+// results, which are returned in channel order. Each send gets the dispatcher's timeout.
+func SendTestNotifications(ctx context.Context, n Notification, channels []models.NotificationChannel) []TestResult {
+	results := make([]TestResult, len(channels))
+	var wg sync.WaitGroup
+	for i, ch := range channels {
+		wg.Add(1)
+		go func(i int, ch models.NotificationChannel) {
+			defer wg.Done()
+			result := TestResult{ChannelID: ch.ID, ChannelName: ch.Name, ChannelType: ch.Type}
+			start := time.Now()
+			defer func() {
+				result.DurationMs = time.Since(start).Milliseconds()
+				results[i] = result
+			}()
+
+			sender, err := NewChannel(ch)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			test := n
+			test.EventID = fmt.Sprintf("evt_test_%d_%d", start.UnixNano(), ch.ID)
+			sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+			defer cancel()
+			receipt, err := sender.Send(sendCtx, test)
+			result.StatusCode = receipt.StatusCode
+			result.Request = receipt.Request
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			result.Success = true
+		}(i, ch)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+
+	"github.com/oFuterman/light-house/internal/models"
+)
+
+// UsageEmail tells an org's owners that a resource crossed a plan usage level
+type UsageEmail struct {
+	To          []string
+	OrgName     string
+	PlanName    string
+	Resource    string // Human label, e.g. "Log volume"
+	Level       models.UsageLevel
+	Percent     int    // Usage as a percentage of the plan limit
+	Consequence string // What happens at (or past) the limit
+	Link        string // Absolute, or a path on the frontend
+}
+
+// Headline is the one-line summary used as the subject and first line
+func (u UsageEmail) Headline() string {
+	switch u.Level {
+	case models.UsageLevelLimit:
+		return fmt.Sprintf("%s has reached the %s plan limit", u.Resource, u.PlanName)
+	case models.UsageLevelOverage:
+		return fmt.Sprintf("%s is over the %s plan limit (%d%%)", u.Resource, u.PlanName, u.Percent)
+	}
+	return fmt.Sprintf("%s is at %d%% of the %s plan limit", u.Resource, u.Percent, u.PlanName)
+}
+
+var usageTextTemplate = template.Must(template.New("usage_text").Parse(`{{.Headline}} for {{.OrgName}}.
+
+{{if eq .Level "warning"}}At the limit: {{end}}{{.Consequence}}
+
+Review usage or upgrade: {{.Link}}
+
+Usage limits reset at the start of each month (UTC).`))
+
+var usageHTMLTemplate = htmltemplate.Must(htmltemplate.New("usage_html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #1d1d1f;">
+  <p><strong>{{.Headline}}</strong> for {{.OrgName}}.</p>
+  <p>{{if eq .Level "warning"}}At the limit: {{end}}{{.Consequence}}</p>
+  <p><a href="{{.Link}}" style="display: inline-block; padding: 10px 16px; background: #1d1d1f; color: #ffffff; text-decoration: none; border-radius: 6px;">Review usage</a></p>
+  <p style="color: #6e6e73;">Usage limits reset at the start of each month (UTC).</p>
+</body>
+</html>`))
+
+// SendUsageEmail sends a multipart plan usage notification
+func SendUsageEmail(ctx context.Context, usage UsageEmail) error {
+	usage.Link = frontendLink(usage.Link)
+	var text, html bytes.Buffer
+	if err := usageTextTemplate.Execute(&text, usage); err != nil {
+		return fmt.Errorf("failed to render usage email: %w", err)
+	}
+	if err := usageHTMLTemplate.Execute(&html, usage); err != nil {
+		return fmt.Errorf("failed to render usage email: %w", err)
+	}
+	return SendEmail(ctx, Email{
+		To:      usage.To,
+		Subject: fmt.Sprintf("%s for %s", usage.Headline(), usage.OrgName),
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
@@ -0,0 +1,140 @@
+package oncall
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StartEscalation attaches the check's escalation policy to a new DOWN alert.
+// Level 1 is notified on the next escalation worker tick.
+func StartEscalation(db *gorm.DB, alert models.Alert, check models.Check) error {
+	if check.EscalationPolicyID == nil || alert.AlertType != models.AlertTypeDown {
+		return nil
+	}
+	now := time.Now()
+	escalation := models.AlertEscalation{
+		OrgID:            alert.OrgID,
+		AlertID:          alert.ID,
+		CheckID:          check.ID,
+		PolicyID:         *check.EscalationPolicyID,
+		Status:           models.EscalationActive,
+		NextEscalationAt: &now,
+	}
+	return db.Create(&escalation).Error
+}
+
+// ResolveEscalations stops escalating the check's open alerts once it recovers
+func ResolveEscalations(db *gorm.DB, checkID uint) error {
+	return db.Model(&models.AlertEscalation{}).
+		Where("check_id = ? AND status = ?", checkID, models.EscalationActive).
+		Updates(map[string]interface{}{
+			"status":             models.EscalationResolved,
+			"next_escalation_at": nil,
+		}).Error
+}
+
+// Acknowledge marks an alert acknowledged and stops its escalation.
+// Acknowledging an already acknowledged alert is a no-op.
+func Acknowledge(db *gorm.DB, alert *models.Alert, userID uint) error {
+	if alert.AcknowledgedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(alert).Updates(map[string]interface{}{
+			"acknowledged_at":    now,
+			"acknowledged_by_id": userID,
+		}).Error; err != nil {
+			return err
+		}
+		alert.AcknowledgedAt = &now
+		alert.AcknowledgedByID = &userID
+		return tx.Model(&models.AlertEscalation{}).
+			Where("alert_id = ? AND status = ?", alert.ID, models.EscalationActive).
+			Updates(map[string]interface{}{
+				"status":             models.EscalationAcknowledged,
+				"next_escalation_at": nil,
+			}).Error
+	})
+}
+
+// ProcessDueEscalations advances every active escalation whose wait has elapsed.
+// Rows are locked with SKIP LOCKED so replicas never notify the same level twice.
+func ProcessDueEscalations(db *gorm.DB, limit int) (int, error) {
+	processed := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var escalations []models.AlertEscalation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_escalation_at <= ?", models.EscalationActive, time.Now()).
+			Order("next_escalation_at ASC").
+			Limit(limit).
+			Find(&escalations).Error; err != nil {
+			return err
+		}
+		for _, escalation := range escalations {
+			// Nested transaction (savepoint) so one failure doesn't abort the batch
+			if err := tx.Transaction(func(inner *gorm.DB) error {
+				return advance(inner, escalation)
+			}); err != nil {
+				log.Printf("Error escalating alert %d: %v", escalation.AlertID, err)
+				continue
+			}
+			processed++
+		}
+		return nil
+	})
+	return processed, err
+}
+
+// advance notifies the next level of an escalation, or marks it exhausted
+func advance(tx *gorm.DB, escalation models.AlertEscalation) error {
+	var alert models.Alert
+	if err := tx.First(&alert, escalation.AlertID).Error; err != nil {
+		return fmt.Errorf("failed to load alert: %w", err)
+	}
+	if alert.AcknowledgedAt != nil {
+		return tx.Model(&escalation).Updates(map[string]interface{}{
+			"status":             models.EscalationAcknowledged,
+			"next_escalation_at": nil,
+		}).Error
+	}
+
+	nextLevel := escalation.CurrentLevel + 1
+	var level models.EscalationLevel
+	err := tx.Where("policy_id = ? AND level = ?", escalation.PolicyID, nextLevel).First(&level).Error
+	if err == gorm.ErrRecordNotFound {
+		log.Printf("Escalation for alert %d exhausted after level %d", alert.ID, escalation.CurrentLevel)
+		return tx.Model(&escalation).Updates(map[string]interface{}{
+			"status":             models.EscalationExhausted,
+			"next_escalation_at": nil,
+		}).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load escalation level: %w", err)
+	}
+
+	now := time.Now()
+	userIDs, err := LevelTargets(tx, escalation.OrgID, level, now)
+	if err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		log.Printf("Escalation level %d of policy %d has nobody to notify for alert %d", level.Level, escalation.PolicyID, alert.ID)
+	}
+	if err := notifier.EnqueueUserNotifications(tx, alert, userIDs); err != nil {
+		return err
+	}
+
+	next := now.Add(time.Duration(level.EscalateAfterMinutes) * time.Minute)
+	log.Printf("Escalated alert %d to level %d (%d users)", alert.ID, level.Level, len(userIDs))
+	return tx.Model(&escalation).Updates(map[string]interface{}{
+		"current_level":      level.Level,
+		"next_escalation_at": next,
+	}).Error
+}
@@ -0,0 +1,133 @@
+package oncall
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// On-call sources
+const (
+	SourceRotation = "rotation"
+	SourceOverride = "override"
+)
+
+// Shift is who is on call for a schedule at a point in time
+type Shift struct {
+	ScheduleID  uint       `json:"schedule_id"`
+	UserID      *uint      `json:"user_id"` // nil when nobody is on call
+	Source      string     `json:"source,omitempty"`
+	OverrideID  *uint      `json:"override_id,omitempty"`
+	ShiftStart  *time.Time `json:"shift_start,omitempty"`
+	NextHandoff *time.Time `json:"next_handoff,omitempty"`
+}
+
+// Location loads the schedule's timezone, falling back to UTC
+func Location(schedule models.OnCallSchedule) *time.Location {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// handoff returns the start of shift number k
+func handoff(schedule models.OnCallSchedule, loc *time.Location, k int) time.Time {
+	start := schedule.RotationStart.In(loc)
+	if schedule.ShiftLengthHours%24 == 0 {
+		// Whole-day shifts keep their wall-clock handoff time across DST changes
+		return start.AddDate(0, 0, k*schedule.ShiftLengthHours/24)
+	}
+	return start.Add(time.Duration(k*schedule.ShiftLengthHours) * time.Hour)
+}
+
+// RotationAt returns the rotation shift covering `at`, ignoring overrides.
+// Before RotationStart, or with no participants, nobody is on call.
+func RotationAt(schedule models.OnCallSchedule, at time.Time) Shift {
+	shift := Shift{ScheduleID: schedule.ID}
+	if len(schedule.ParticipantIDs) == 0 || schedule.ShiftLengthHours <= 0 || at.Before(schedule.RotationStart) {
+		return shift
+	}
+
+	loc := Location(schedule)
+	length := time.Duration(schedule.ShiftLengthHours) * time.Hour
+	k := int(at.Sub(schedule.RotationStart) / length)
+	// The estimate can be one shift off around DST changes
+	for k > 0 && handoff(schedule, loc, k).After(at) {
+		k--
+	}
+	for !handoff(schedule, loc, k+1).After(at) {
+		k++
+	}
+
+	userID := uint(schedule.ParticipantIDs[k%len(schedule.ParticipantIDs)])
+	start := handoff(schedule, loc, k)
+	next := handoff(schedule, loc, k+1)
+	shift.UserID = &userID
+	shift.Source = SourceRotation
+	shift.ShiftStart = &start
+	shift.NextHandoff = &next
+	return shift
+}
+
+// WhoIsOnCall resolves the on-call user for a schedule at `at`, applying overrides.
+// When overrides overlap, the most recently created one wins.
+func WhoIsOnCall(db *gorm.DB, schedule models.OnCallSchedule, at time.Time) (Shift, error) {
+	var override models.OnCallOverride
+	err := db.Where("schedule_id = ? AND starts_at <= ? AND ends_at > ?", schedule.ID, at, at).
+		Order("created_at DESC").
+		First(&override).Error
+	if err == gorm.ErrRecordNotFound {
+		return RotationAt(schedule, at), nil
+	}
+	if err != nil {
+		return Shift{}, fmt.Errorf("failed to load overrides: %w", err)
+	}
+
+	userID := override.UserID
+	start := override.StartsAt
+	end := override.EndsAt
+	return Shift{
+		ScheduleID:  schedule.ID,
+		UserID:      &userID,
+		Source:      SourceOverride,
+		OverrideID:  &override.ID,
+		ShiftStart:  &start,
+		NextHandoff: &end,
+	}, nil
+}
+
+// LevelTargets resolves the users an escalation level notifies at `at`:
+// its direct users plus whoever is on call on its schedules, deduplicated.
+func LevelTargets(db *gorm.DB, orgID uint, level models.EscalationLevel, at time.Time) ([]uint, error) {
+	seen := map[uint]bool{}
+	var userIDs []uint
+	add := func(id uint) {
+		if !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+	for _, id := range level.UserIDs {
+		add(uint(id))
+	}
+
+	if len(level.ScheduleIDs) > 0 {
+		var schedules []models.OnCallSchedule
+		if err := db.Where("org_id = ? AND id IN ?", orgID, []int64(level.ScheduleIDs)).Find(&schedules).Error; err != nil {
+			return nil, fmt.Errorf("failed to load schedules: %w", err)
+		}
+		for _, schedule := range schedules {
+			shift, err := WhoIsOnCall(db, schedule, at)
+			if err != nil {
+				return nil, err
+			}
+			if shift.UserID != nil {
+				add(*shift.UserID)
+			}
+		}
+	}
+	return userIDs, nil
+}
@@ -57,6 +57,14 @@ func Setup(app *fiber.App, db *gorm.DB, cfg *config.Config) {
 		handlers.IngestLog(db),
 	)
 
+	// Inbound Alertmanager and Grafana webhooks (API key auth - must be registered before protected group)
+	integrations := v1.Group("/integrations",
+		middleware.APIKeyAuthWithScope(db, models.ScopeAlertsWrite, models.ScopeAll),
+		middleware.RateLimitByAPIKey(300, time.Minute), // 300 req/min per org
+	)
+	integrations.Post("/alertmanager", handlers.ReceiveAlertmanagerWebhook(db))
+	integrations.Post("/grafana", handlers.ReceiveGrafanaWebhook(db))
+
 	// Stripe webhook (public, verified by signature - must be registered before protected group)
 	v1.Post("/billing/webhook", handlers.HandleStripeWebhook(db))
 
@@ -65,6 +73,8 @@ func Setup(app *fiber.App, db *gorm.DB, cfg *config.Config) {
 
 	// Current user route
 	protected.Get("/me", handlers.GetMe(db))
+	protected.Get("/me/notification-preferences", handlers.GetNotificationPreferences(db))
+	protected.Put("/me/notification-preferences", handlers.UpdateNotificationPreferences(db))
 
 	// Organization routes
 	orgs := protected.Group("/organizations")
@@ -97,20 +107,126 @@ func Setup(app *fiber.App, db *gorm.DB, cfg *config.Config) {
 	checks.Get("/", handlers.ListChecks(db))
 	checks.Post("/", handlers.CreateCheck(db))
 	checks.Post("/search", handlers.SearchChecks(db))
+	checks.Post("/import/preview", handlers.PreviewCheckImport(db))
+	checks.Post("/import/accept", handlers.AcceptCheckImport(db))
 	checks.Get("/:id", handlers.GetCheck(db))
 	checks.Put("/:id", handlers.UpdateCheck(db))
 	checks.Delete("/:id", handlers.DeleteCheck(db))
 	checks.Get("/:id/results", handlers.GetCheckResults(db))
 	checks.Post("/:id/results/search", handlers.SearchCheckResults(db))
 	checks.Get("/:id/summary", handlers.GetCheckSummary(db))
+	checks.Get("/:id/baseline", handlers.GetCheckBaseline(db))
 	checks.Get("/:id/alerts", handlers.GetCheckAlerts(db))
+	checks.Get("/:id/revisions", handlers.ListCheckRevisions(db))
+	checks.Get("/:id/revisions/:version", handlers.GetCheckRevision(db))
+	checks.Post("/:id/revisions/:version/restore", handlers.RestoreCheckRevision(db))
+
+	// Check group routes
+	checkGroups := protected.Group("/check-groups")
+	checkGroups.Get("/", handlers.ListCheckGroups(db))
+	checkGroups.Post("/", handlers.CreateCheckGroup(db))
+	checkGroups.Get("/:id", handlers.GetCheckGroup(db))
+	checkGroups.Put("/:id", handlers.UpdateCheckGroup(db))
+	checkGroups.Delete("/:id", handlers.DeleteCheckGroup(db))
+	checkGroups.Get("/:id/status", handlers.GetCheckGroupStatus(db))
+	checkGroups.Post("/:id/bulk", handlers.BulkCheckGroupAction(db))
+
+	// Maintenance window routes
+	maintenance := protected.Group("/maintenance-windows")
+	maintenance.Get("/", handlers.ListMaintenanceWindows(db))
+	maintenance.Post("/", handlers.CreateMaintenanceWindow(db))
+	maintenance.Delete("/:id", handlers.DeleteMaintenanceWindow(db))
 
 	// Alert routes (org-wide)
 	protected.Get("/alerts", handlers.GetOrgAlerts(db))
+	protected.Get("/alerts/:id/deliveries", handlers.GetAlertDeliveries(db))
+	protected.Post("/alerts/:id/deliveries/:deliveryId/redeliver", middleware.RequireAdmin(), handlers.RedeliverAlertNotification(db))
+	protected.Post("/alerts/:id/acknowledge", handlers.AcknowledgeAlert(db))
+
+	// Incident routes
+	incidentRoutes := protected.Group("/incidents")
+	incidentRoutes.Get("/", handlers.ListIncidents(db))
+	incidentRoutes.Get("/stats", handlers.GetIncidentStats(db))
+	incidentRoutes.Get("/:id", handlers.GetIncident(db))
+	incidentRoutes.Post("/:id/acknowledge", handlers.AcknowledgeIncident(db))
+	incidentRoutes.Post("/:id/resolve", handlers.ResolveIncident(db))
+	incidentRoutes.Put("/:id/assignee", handlers.AssignIncident(db))
+	incidentRoutes.Post("/:id/notes", handlers.AddIncidentNote(db))
 
 	// Notification settings routes (admin only)
 	protected.Get("/notification-settings", handlers.GetNotificationSettings(db))
 	protected.Put("/notification-settings", middleware.RequireAdmin(), handlers.UpdateNotificationSettings(db))
+	protected.Post("/notification-settings/test", middleware.RequireAdmin(), handlers.TestNotificationSettings(db))
+
+	// Notification template routes (admin only)
+	templates := protected.Group("/notification-templates", middleware.RequireAdmin())
+	templates.Get("/", handlers.GetNotificationTemplates(db))
+	templates.Put("/", handlers.UpdateNotificationTemplates(db))
+	templates.Post("/preview", handlers.PreviewNotificationTemplates(db))
+
+	// Notification channel routes (admin only)
+	channels := protected.Group("/notification-channels", middleware.RequireAdmin())
+	channels.Get("/", handlers.ListNotificationChannels(db))
+	channels.Get("/types", handlers.ListNotificationChannelTypes())
+	channels.Post("/", handlers.CreateNotificationChannel(db))
+	channels.Get("/:id", handlers.GetNotificationChannel(db))
+	channels.Put("/:id", handlers.UpdateNotificationChannel(db))
+	channels.Delete("/:id", handlers.DeleteNotificationChannel(db))
+	channels.Post("/:id/rotate-secret", handlers.RotateNotificationChannelSecret(db))
+
+	// Uptime digest routes (admin only for changes)
+	protected.Get("/digests/preview", handlers.PreviewDigest(db))
+	digests := protected.Group("/digest-subscriptions")
+	digests.Get("/", handlers.ListDigestSubscriptions(db))
+	digests.Post("/", middleware.RequireAdmin(), handlers.CreateDigestSubscription(db))
+	digests.Put("/:id", middleware.RequireAdmin(), handlers.UpdateDigestSubscription(db))
+	digests.Delete("/:id", middleware.RequireAdmin(), handlers.DeleteDigestSubscription(db))
+	digests.Post("/:id/send", middleware.RequireAdmin(), handlers.SendDigestNow(db))
+
+	// Alert routing rule routes (admin only)
+	routingRules := protected.Group("/routing-rules", middleware.RequireAdmin())
+	routingRules.Get("/", handlers.ListRoutingRules(db))
+	routingRules.Post("/", handlers.CreateRoutingRule(db))
+	routingRules.Put("/order", handlers.ReorderRoutingRules(db))
+	routingRules.Put("/:id", handlers.UpdateRoutingRule(db))
+	routingRules.Delete("/:id", handlers.DeleteRoutingRule(db))
+
+	// Log alert rule routes (admin only for changes)
+	logAlertRules := protected.Group("/log-alert-rules")
+	logAlertRules.Get("/", handlers.ListLogAlertRules(db))
+	logAlertRules.Post("/", middleware.RequireAdmin(), handlers.CreateLogAlertRule(db))
+	logAlertRules.Get("/:id", handlers.GetLogAlertRule(db))
+	logAlertRules.Put("/:id", middleware.RequireAdmin(), handlers.UpdateLogAlertRule(db))
+	logAlertRules.Delete("/:id", middleware.RequireAdmin(), handlers.DeleteLogAlertRule(db))
+
+	// Trace alert rule routes (admin only for changes)
+	traceAlertRules := protected.Group("/trace-alert-rules")
+	traceAlertRules.Get("/", handlers.ListTraceAlertRules(db))
+	traceAlertRules.Post("/", middleware.RequireAdmin(), handlers.CreateTraceAlertRule(db))
+	traceAlertRules.Get("/:id", handlers.GetTraceAlertRule(db))
+	traceAlertRules.Put("/:id", middleware.RequireAdmin(), handlers.UpdateTraceAlertRule(db))
+	traceAlertRules.Delete("/:id", middleware.RequireAdmin(), handlers.DeleteTraceAlertRule(db))
+
+	// On-call schedule routes (admin only for changes)
+	protected.Get("/on-call", handlers.WhoIsOnCall(db))
+	schedules := protected.Group("/on-call-schedules")
+	schedules.Get("/", handlers.ListOnCallSchedules(db))
+	schedules.Post("/", middleware.RequireAdmin(), handlers.CreateOnCallSchedule(db))
+	schedules.Get("/:id", handlers.GetOnCallSchedule(db))
+	schedules.Put("/:id", middleware.RequireAdmin(), handlers.UpdateOnCallSchedule(db))
+	schedules.Delete("/:id", middleware.RequireAdmin(), handlers.DeleteOnCallSchedule(db))
+	schedules.Get("/:id/on-call", handlers.GetScheduleOnCall(db))
+	schedules.Get("/:id/overrides", handlers.ListOnCallOverrides(db))
+	schedules.Post("/:id/overrides", middleware.RequireAdmin(), handlers.CreateOnCallOverride(db))
+	schedules.Delete("/:id/overrides/:overrideId", middleware.RequireAdmin(), handlers.DeleteOnCallOverride(db))
+
+	// Escalation policy routes (admin only for changes)
+	escalationPolicies := protected.Group("/escalation-policies")
+	escalationPolicies.Get("/", handlers.ListEscalationPolicies(db))
+	escalationPolicies.Post("/", middleware.RequireAdmin(), handlers.CreateEscalationPolicy(db))
+	escalationPolicies.Get("/:id", handlers.GetEscalationPolicy(db))
+	escalationPolicies.Put("/:id", middleware.RequireAdmin(), handlers.UpdateEscalationPolicy(db))
+	escalationPolicies.Delete("/:id", middleware.RequireAdmin(), handlers.DeleteEscalationPolicy(db))
 
 	// API Key routes (admin only for create/delete)
 	apiKeys := protected.Group("/api-keys")
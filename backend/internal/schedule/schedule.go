@@ -0,0 +1,71 @@
+// Package schedule runs rows that come due on a schedule, such as alert rules and
+// digest subscriptions, so that several replicas can share the work.
+package schedule
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Due describes a table of enabled rows that each carry the time they are next due
+type Due[T any] struct {
+	Column  string   // Time the row is next due, e.g. "next_evaluation_at"
+	Preload []string // Relations Run needs
+
+	// Interval moves every claimed row to now+Interval. When it is zero, Reschedule
+	// must update each row's Column itself.
+	Interval   time.Duration
+	ID         func(row T) uint
+	Reschedule func(tx *gorm.DB, row *T, now time.Time) error
+
+	// Run processes one claimed row, logging its own failures
+	Run func(db *gorm.DB, row T, now time.Time)
+}
+
+// ProcessDue claims up to limit enabled rows whose due time has passed and runs them.
+// Rows are rescheduled before running (under SKIP LOCKED) so replicas never run one twice.
+func (d Due[T]) ProcessDue(db *gorm.DB, limit int) (int, error) {
+	var due []T
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		for _, relation := range d.Preload {
+			query = query.Preload(relation)
+		}
+		if err := query.Where("enabled = ? AND "+d.Column+" <= ?", true, now).
+			Order(d.Column + " ASC").
+			Limit(limit).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+		if d.Interval == 0 {
+			for i := range due {
+				if err := d.Reschedule(tx, &due[i], now); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		ids := make([]uint, len(due))
+		for i := range due {
+			ids[i] = d.ID(due[i])
+		}
+		var model T
+		return tx.Model(&model).
+			Where("id IN ?", ids).
+			Update(d.Column, now.Add(d.Interval)).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range due {
+		d.Run(db, row, now)
+	}
+	return len(due), nil
+}
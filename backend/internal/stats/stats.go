@@ -0,0 +1,66 @@
+// Package stats computes check run statistics shared by the API and digests
+package stats
+
+import (
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// CheckStats aggregates a check's runs within a time window
+type CheckStats struct {
+	TotalRuns        int     `json:"total_runs"`
+	SuccessfulRuns   int     `json:"successful_runs"`
+	FailedRuns       int     `json:"failed_runs"`
+	UptimePercentage float64 `json:"uptime_percentage"`
+	AvgResponseMs    int     `json:"avg_response_ms"`
+	P95ResponseMs    int     `json:"p95_response_ms"`
+}
+
+// ForCheck computes run statistics for results created in [since, until).
+// A zero until means "up to now".
+func ForCheck(db *gorm.DB, checkID uint, since, until time.Time) (CheckStats, error) {
+	query := db.Where("check_id = ? AND created_at >= ?", checkID, since)
+	if !until.IsZero() {
+		query = query.Where("created_at < ?", until)
+	}
+	// Ordered by response time for the p95 calculation
+	var results []models.CheckResult
+	if err := query.Select("success", "response_time_ms").
+		Order("response_time_ms ASC").
+		Find(&results).Error; err != nil {
+		return CheckStats{}, err
+	}
+	return FromResults(results), nil
+}
+
+// FromResults computes statistics from results sorted by response time ascending
+func FromResults(results []models.CheckResult) CheckStats {
+	var stats CheckStats
+	stats.TotalRuns = len(results)
+	if stats.TotalRuns == 0 {
+		return stats
+	}
+	var totalResponseMs int64
+	for _, r := range results {
+		if r.Success {
+			stats.SuccessfulRuns++
+		}
+		totalResponseMs += r.ResponseTimeMs
+	}
+	stats.FailedRuns = stats.TotalRuns - stats.SuccessfulRuns
+	stats.UptimePercentage = float64(stats.SuccessfulRuns) / float64(stats.TotalRuns) * 100
+	stats.AvgResponseMs = int(totalResponseMs / int64(stats.TotalRuns))
+	stats.P95ResponseMs = int(results[P95Index(stats.TotalRuns)].ResponseTimeMs)
+	return stats
+}
+
+// P95Index returns the index for the 95th percentile in a sorted slice
+func P95Index(length int) int {
+	idx := int(float64(length) * 0.95)
+	if idx >= length {
+		idx = length - 1
+	}
+	return idx
+}
@@ -0,0 +1,208 @@
+package tracealerts
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"github.com/oFuterman/light-house/internal/notifier"
+	"github.com/oFuterman/light-house/internal/schedule"
+	"github.com/oFuterman/light-house/internal/utils"
+	"gorm.io/gorm"
+)
+
+// EvaluationInterval is how often each enabled rule is evaluated
+const EvaluationInterval = time.Minute
+
+// Aggregate summarises the spans a rule matched in one window
+type Aggregate struct {
+	Spans      int64
+	Errors     int64
+	Percentile *float64 // Duration percentile in ms; nil for error rate rules or no spans
+}
+
+// Value is the rule's metric for the aggregate
+func (a Aggregate) Value(metric models.TraceAlertMetric) float64 {
+	if metric == models.TraceMetricErrorRate {
+		if a.Spans == 0 {
+			return 0
+		}
+		return float64(a.Errors) * 100 / float64(a.Spans)
+	}
+	if a.Percentile == nil {
+		return 0
+	}
+	return *a.Percentile
+}
+
+// spans scopes a query to the spans a rule watches between from and to
+func spans(db *gorm.DB, rule models.TraceAlertRule, from, to time.Time) *gorm.DB {
+	query := db.Model(&models.TraceSpan{}).
+		Where("org_id = ? AND service_name = ? AND start_time >= ? AND start_time < ?", rule.OrgID, rule.ServiceName, from, to)
+	if rule.Operation != "" {
+		query = query.Where("operation = ?", rule.Operation)
+	}
+	if rule.Environment != "" {
+		query = query.Where("environment = ?", rule.Environment)
+	}
+	return query
+}
+
+// Measure aggregates the spans a rule watches between from and to
+func Measure(db *gorm.DB, rule models.TraceAlertRule, from, to time.Time) (Aggregate, error) {
+	var agg Aggregate
+	selects := "COUNT(*) AS spans, COUNT(*) FILTER (WHERE status = ?) AS errors"
+	args := []interface{}{models.SpanStatusError}
+	if p := rule.Metric.Percentile(); p > 0 {
+		selects += ", percentile_cont(?) WITHIN GROUP (ORDER BY duration_ms) AS percentile"
+		args = append(args, p)
+	}
+	err := spans(db, rule, from, to).Select(selects, args...).Scan(&agg).Error
+	return agg, err
+}
+
+// ExampleTraces returns up to TraceAlertExampleTraces trace IDs that illustrate a breach:
+// the slowest traces above the threshold for duration rules, the latest failing ones otherwise
+func ExampleTraces(db *gorm.DB, rule models.TraceAlertRule, from, to time.Time) ([]string, error) {
+	query := spans(db, rule, from, to).Group("trace_id")
+	if rule.Metric == models.TraceMetricErrorRate {
+		query = query.Where("status = ?", models.SpanStatusError).Order("MAX(start_time) DESC")
+	} else {
+		query = query.Where("duration_ms > ?", rule.Threshold).Order("MAX(duration_ms) DESC")
+	}
+	var traceIDs []string
+	err := query.Limit(models.TraceAlertExampleTraces).Pluck("trace_id", &traceIDs).Error
+	return traceIDs, err
+}
+
+// Describe explains an evaluation, e.g. "p95 duration 1840ms over the last 5m (threshold: 1000ms, 312 spans)"
+func Describe(rule models.TraceAlertRule, agg Aggregate) string {
+	window := utils.FormatWindow(rule.WindowSeconds)
+	value := agg.Value(rule.Metric)
+	if rule.Metric == models.TraceMetricErrorRate {
+		return fmt.Sprintf("Error rate %.1f%% over the last %s (threshold: %g%%, %d of %d spans failed)",
+			value, window, rule.Threshold, agg.Errors, agg.Spans)
+	}
+	label := strings.TrimSuffix(string(rule.Metric), "_duration")
+	return fmt.Sprintf("%s duration %.0fms over the last %s (threshold: %gms, %d spans)",
+		label, value, window, rule.Threshold, agg.Spans)
+}
+
+// Evaluate aggregates the spans a rule watches over its window ending at now. When
+// the outcome differs from the rule's firing state, it raises a TRACE_ALERT (with
+// example traces) or TRACE_RESOLVED alert and queues its notifications. Windows with
+// fewer than MinSpans spans are too small to judge and leave the state unchanged.
+func Evaluate(db *gorm.DB, rule models.TraceAlertRule, now time.Time) error {
+	updates := map[string]interface{}{"last_evaluated_at": now}
+	from := now.Add(-rule.Window())
+	agg, err := Measure(db, rule, from, now)
+	if err != nil {
+		updates["last_error"] = utils.Truncate(err.Error(), 1024)
+		if updateErr := db.Model(&rule).Updates(updates).Error; updateErr != nil {
+			log.Printf("Error updating trace alert rule %d: %v", rule.ID, updateErr)
+		}
+		return err
+	}
+	updates["last_span_count"] = agg.Spans
+	updates["last_error"] = ""
+	if agg.Spans < int64(rule.MinSpans) {
+		updates["last_value"] = nil
+		return db.Model(&rule).Updates(updates).Error
+	}
+	value := agg.Value(rule.Metric)
+	updates["last_value"] = value
+
+	breached := value > rule.Threshold
+	if breached == rule.Firing {
+		return db.Model(&rule).Updates(updates).Error
+	}
+
+	alert := models.Alert{
+		OrgID:            rule.OrgID,
+		TraceAlertRuleID: &rule.ID,
+		AlertType:        models.AlertTypeTraceResolved,
+		ErrorMessage:     Describe(rule, agg),
+	}
+	if breached {
+		alert.AlertType = models.AlertTypeTraceAlert
+		updates["last_triggered_at"] = now
+		traceIDs, err := ExampleTraces(db, rule, from, now)
+		if err != nil {
+			log.Printf("Failed to load example traces for trace alert rule %d: %v", rule.ID, err)
+		}
+		if len(traceIDs) > 0 {
+			alert.TraceIDs = traceIDs
+			alert.ErrorMessage += ". Example traces: " + strings.Join(traceIDs, ", ")
+		}
+	}
+	updates["firing"] = breached
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// The rule may have been resolved by disabling it since it was claimed
+		result := tx.Model(&rule).Where("firing = ?", rule.Firing).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errFiringChanged
+		}
+		if err := tx.Create(&alert).Error; err != nil {
+			return err
+		}
+		// Delivery happens in the notification dispatcher so failed sends are retried
+		_, err := notifier.EnqueueAlert(tx, alert, rule.Subject())
+		return err
+	})
+	if err == errFiringChanged {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	log.Printf("Alert created: trace_rule=%d type=%s value=%.2f", rule.ID, alert.AlertType, value)
+	return nil
+}
+
+// errFiringChanged rolls back an evaluation whose rule changed firing state concurrently
+var errFiringChanged = errors.New("rule firing state changed during evaluation")
+
+// Resolve raises a TRACE_RESOLVED alert for a firing rule that is being disabled or
+// deleted, since it won't be evaluated again, and clears its firing state. Call it in
+// the transaction that changes the rule, with the rule's row locked.
+func Resolve(tx *gorm.DB, rule models.TraceAlertRule, reason string) error {
+	if !rule.Firing {
+		return nil
+	}
+	alert := models.Alert{
+		OrgID:            rule.OrgID,
+		TraceAlertRuleID: &rule.ID,
+		AlertType:        models.AlertTypeTraceResolved,
+		ErrorMessage:     reason,
+	}
+	if err := tx.Create(&alert).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&rule).Update("firing", false).Error; err != nil {
+		return err
+	}
+	_, err := notifier.EnqueueAlert(tx, alert, rule.Subject())
+	return err
+}
+
+// Rules are claimed and rescheduled by schedule.Due so replicas never evaluate one twice
+var due = schedule.Due[models.TraceAlertRule]{
+	Column:   "next_evaluation_at",
+	Interval: EvaluationInterval,
+	ID:       func(rule models.TraceAlertRule) uint { return rule.ID },
+	Run: func(db *gorm.DB, rule models.TraceAlertRule, now time.Time) {
+		if err := Evaluate(db, rule, now); err != nil {
+			log.Printf("Trace alert rule %d for org %d failed: %v", rule.ID, rule.OrgID, err)
+		}
+	},
+}
+
+// ProcessDue evaluates up to limit enabled rules whose next evaluation has come
+func ProcessDue(db *gorm.DB, limit int) (int, error) {
+	return due.ProcessDue(db, limit)
+}
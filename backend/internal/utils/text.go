@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Truncate cuts s to at most n bytes for a sized column, without splitting a
+// UTF-8 sequence (Postgres rejects invalid UTF-8)
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// FormatWindow renders a number of seconds in the largest whole unit, e.g. "5m" or "90s"
+func FormatWindow(seconds int) string {
+	switch {
+	case seconds%3600 == 0:
+		return fmt.Sprintf("%dh", seconds/3600)
+	case seconds%60 == 0:
+		return fmt.Sprintf("%dm", seconds/60)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
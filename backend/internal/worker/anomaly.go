@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// updateAnomaly folds a successful run's response time into the check's baseline for
+// the current hour of the week and, when anomaly detection is on, moves the check in
+// and out of the anomalous state. The state only changes after AnomalyRuns consecutive
+// runs agree, and each change sends a single LATENCY_ANOMALY / LATENCY_NORMAL alert.
+// Failed runs are left to DOWN alerts; their timings say nothing about normal latency.
+func updateAnomaly(db *gorm.DB, check *models.Check, result models.CheckResult, now time.Time) {
+	if !check.AnomalyDetection {
+		// Detection was turned off while anomalous; drop the state quietly
+		if check.IsAnomalous || check.AnomalyStreak != 0 {
+			db.Model(&models.Check{}).Where("id = ?", check.ID).
+				Updates(map[string]interface{}{"is_anomalous": false, "anomaly_streak": 0})
+		}
+		return
+	}
+	if !result.Success {
+		return
+	}
+
+	bucket := models.BaselineBucket(now)
+	var baseline models.ResponseTimeBaseline
+	if err := db.Where("check_id = ? AND bucket = ?", check.ID, bucket).
+		Attrs(models.ResponseTimeBaseline{CheckID: check.ID, Bucket: bucket}).
+		FirstOrInit(&baseline).Error; err != nil {
+		log.Printf("Error loading response time baseline for check %d: %v", check.ID, err)
+		return
+	}
+
+	// Judge the run against the baseline as it was before this run
+	threshold := check.AnomalyThreshold()
+	ms := float64(result.ResponseTimeMs)
+	ready := baseline.Ready()
+	mean, stdDev, score := baseline.Mean, baseline.StdDev(), baseline.Score(ms)
+
+	baseline.Observe(ms, threshold)
+	if err := db.Save(&baseline).Error; err != nil {
+		log.Printf("Error saving response time baseline for check %d: %v", check.ID, err)
+		return
+	}
+	if !ready {
+		return
+	}
+
+	// Only slowdowns count; a faster than usual response is never an anomaly
+	anomalous := score > threshold
+	streak := 0
+	if anomalous != check.IsAnomalous {
+		streak = check.AnomalyStreak + 1
+	}
+	runs := check.AnomalyRuns()
+	if streak < runs {
+		if streak != check.AnomalyStreak {
+			if err := db.Model(&models.Check{}).Where("id = ?", check.ID).
+				Update("anomaly_streak", streak).Error; err != nil {
+				log.Printf("Error updating anomaly streak for check %d: %v", check.ID, err)
+			}
+		}
+		return
+	}
+
+	if err := db.Model(&models.Check{}).Where("id = ?", check.ID).
+		Updates(map[string]interface{}{"is_anomalous": anomalous, "anomaly_streak": 0}).Error; err != nil {
+		log.Printf("Error updating anomaly state for check %d: %v", check.ID, err)
+		return
+	}
+	alertType := models.AlertTypeLatencyNormal
+	msg := fmt.Sprintf("Response time %dms is back within the baseline of %.0fms ± %.0fms for this hour of the week (%d consecutive runs)",
+		result.ResponseTimeMs, mean, stdDev, runs)
+	if anomalous {
+		alertType = models.AlertTypeLatencyAnomaly
+		msg = fmt.Sprintf("Response time %dms is %.1fσ above the baseline of %.0fms ± %.0fms for this hour of the week (%d consecutive runs)",
+			result.ResponseTimeMs, score, mean, stdDev, runs)
+	}
+	raiseAlert(db, *check, alertType, result.StatusCode, msg, now)
+}
@@ -1,17 +1,19 @@
 package worker
 
 import (
+    "fmt"
     "log"
     "net/http"
     "time"
 
+    "github.com/oFuterman/light-house/internal/groups"
+    "github.com/oFuterman/light-house/internal/incidents"
     "github.com/oFuterman/light-house/internal/models"
     "github.com/oFuterman/light-house/internal/notifier"
+    "github.com/oFuterman/light-house/internal/oncall"
     "gorm.io/gorm"
 )
 
-const alertSuppressionWindow = 15 * time.Minute
-
 // AlertMetadata contains info needed for sending notifications
 type AlertMetadata struct {
     Alert     models.Alert
@@ -60,20 +62,43 @@ func isStatusUp(statusCode int) bool {
     return statusCode >= 200 && statusCode < 300
 }
 
-// shouldTriggerAlert determines if an alert should be created based on status transition and suppression window
-func shouldTriggerAlert(prevStatus *int, newStatusCode int, lastAlertAt *time.Time) (shouldAlert bool, alertType models.AlertType) {
-    newIsUp := isStatusUp(newStatusCode)
+// previousUpState returns the check's last known up/down state, or nil if it has never run.
+// Checks last run before LastSuccess was tracked fall back to their last status code.
+func previousUpState(check models.Check) *bool {
+    if check.LastSuccess != nil {
+        return check.LastSuccess
+    }
+    if check.LastStatus != nil {
+        up := isStatusUp(*check.LastStatus)
+        return &up
+    }
+    return nil
+}
+
+// notifiedUpState returns the state announced by the check's last DOWN/RECOVERY alert.
+// Checks that have not alerted since this was tracked fall back to their previous state.
+func notifiedUpState(check models.Check) *bool {
+    if check.LastNotifiedUp != nil {
+        return check.LastNotifiedUp
+    }
+    return previousUpState(check)
+}
+
+// shouldTriggerAlert determines if an alert should be created based on the last notified state
+// and the check's suppression window. A change inside the window is deferred, not dropped:
+// it alerts on the first run after the window if the check is still in the new state.
+func shouldTriggerAlert(prevUp *bool, newIsUp bool, lastAlertAt *time.Time, window time.Duration) (shouldAlert bool, alertType models.AlertType) {
     // Determine previous state (nil = first check, treat as UP to avoid false DOWN alert)
     prevIsUp := true
-    if prevStatus != nil {
-        prevIsUp = isStatusUp(*prevStatus)
+    if prevUp != nil {
+        prevIsUp = *prevUp
     }
     // No state change = no alert
     if prevIsUp == newIsUp {
         return false, ""
     }
     // Check suppression window
-    if lastAlertAt != nil && time.Since(*lastAlertAt) < alertSuppressionWindow {
+    if lastAlertAt != nil && time.Since(*lastAlertAt) < window {
         return false, ""
     }
     // Determine alert type based on transition
@@ -86,31 +111,74 @@ func shouldTriggerAlert(prevStatus *int, newStatusCode int, lastAlertAt *time.Ti
     return false, ""
 }
 
-// createAlert inserts an alert and updates the check's LastAlertAt
-func createAlert(db *gorm.DB, check models.Check, alertType models.AlertType, statusCode int, errorMsg string) *AlertMetadata {
+// createAlert inserts an alert and, for up/down transitions, updates the check's LastAlertAt and LastNotifiedUp.
+// Call it inside the transaction that enqueues the alert's notifications.
+func createAlert(tx *gorm.DB, check models.Check, alertType models.AlertType, statusCode int, errorMsg string) (*AlertMetadata, error) {
     now := time.Now()
     alert := models.Alert{
         OrgID:        check.OrgID,
-        CheckID:      check.ID,
+        CheckID:      &check.ID,
         AlertType:    alertType,
         StatusCode:   statusCode,
         ErrorMessage: errorMsg,
     }
-    if err := db.Create(&alert).Error; err != nil {
-        log.Printf("Error creating alert for check %d: %v", check.ID, err)
-        return nil
+    if err := tx.Create(&alert).Error; err != nil {
+        return nil, fmt.Errorf("failed to create alert: %w", err)
     }
-    // Update check's LastAlertAt
-    if err := db.Model(&models.Check{}).Where("id = ?", check.ID).Update("last_alert_at", now).Error; err != nil {
-        log.Printf("Error updating LastAlertAt for check %d: %v", check.ID, err)
+    // Update check's LastAlertAt (only up/down transitions feed the suppression window)
+    if alertType == models.AlertTypeDown || alertType == models.AlertTypeRecovery {
+        updates := map[string]interface{}{
+            "last_alert_at":    now,
+            "last_notified_up": alertType == models.AlertTypeRecovery,
+        }
+        if err := tx.Model(&models.Check{}).Where("id = ?", check.ID).Updates(updates).Error; err != nil {
+            return nil, fmt.Errorf("failed to update LastAlertAt: %w", err)
+        }
     }
-    log.Printf("Alert created: check=%d type=%s status=%d", check.ID, alertType, statusCode)
     return &AlertMetadata{
         Alert:     alert,
         CheckName: check.Name,
         CheckURL:  check.URL,
         OrgID:     check.OrgID,
+    }, nil
+}
+
+// raiseAlert creates an alert, opens an incident for DOWN alerts and queues notifications
+// unless the check is in maintenance. It returns the alert, or nil if none was created.
+func raiseAlert(db *gorm.DB, check models.Check, alertType models.AlertType, statusCode int, errorMsg string, now time.Time) *models.Alert {
+    // Maintenance windows on the check or any of its groups silence alerts.
+    // A lookup error fails open so a DB hiccup never hides a real outage.
+    inMaintenance, err := groups.InMaintenance(db, check, now)
+    if err != nil {
+        log.Printf("Error checking maintenance windows for check %d: %v", check.ID, err)
+    }
+    if inMaintenance {
+        log.Printf("Check %d is in maintenance, suppressing %s alert", check.ID, alertType)
+        return nil
+    }
+    // The alert and its delivery jobs are written together so a crash or enqueue
+    // failure never leaves an alert nobody is told about; the dispatcher retries sends
+    var metadata *AlertMetadata
+    err = db.Transaction(func(tx *gorm.DB) error {
+        var err error
+        if metadata, err = createAlert(tx, check, alertType, statusCode, errorMsg); err != nil {
+            return err
+        }
+        _, err = notifier.EnqueueAlert(tx, metadata.Alert, check)
+        return err
+    })
+    if err != nil {
+        log.Printf("Error creating %s alert for check %d: %v", alertType, check.ID, err)
+        return nil
+    }
+    log.Printf("Alert created: check=%d type=%s status=%d", check.ID, alertType, statusCode)
+    if _, err := incidents.OpenForAlert(db, &metadata.Alert, check); err != nil {
+        log.Printf("Failed to open incident for check %d: %v", check.ID, err)
+    }
+    if err := oncall.StartEscalation(db, metadata.Alert, check); err != nil {
+        log.Printf("Failed to start escalation for check %d: %v", check.ID, err)
     }
+    return &metadata.Alert
 }
 
 // runCheck executes a single HTTP check and stores the result
@@ -152,27 +220,62 @@ func runCheck(db *gorm.DB, check models.Check) {
         } else {
             log.Printf("Check %d (%s) returned: %d in %dms", check.ID, check.Name, resp.StatusCode, responseTime)
         }
+        if check.SecurityAudit {
+            result.SecurityGrade, result.SecurityFindings = auditSecurity(check.URL, resp)
+        }
+        // Crawl checks are only UP when the root page and every crawled page load
+        if check.CheckType == models.CheckTypeCrawl && result.Success {
+            result.PagesCrawled, result.BrokenLinks = crawlSite(resp, check.CrawlMaxDepth, check.CrawlMaxPages)
+            if len(result.BrokenLinks) > 0 {
+                result.Success = false
+                result.ErrorMessage = brokenLinksMessage(result.BrokenLinks)
+                errorMsg = result.ErrorMessage
+            }
+            log.Printf("Check %d (%s) crawled %d pages, %d broken", check.ID, check.Name, result.PagesCrawled, len(result.BrokenLinks))
+        }
     }
     // Store the result
     if err := db.Create(&result).Error; err != nil {
         log.Printf("Error storing result for check %d: %v", check.ID, err)
         return
     }
-    // Check if we should trigger an alert
-    if shouldAlert, alertType := shouldTriggerAlert(check.LastStatus, result.StatusCode, check.LastAlertAt); shouldAlert {
-        if metadata := createAlert(db, check, alertType, result.StatusCode, errorMsg); metadata != nil {
-            go func() {
-                if err := notifier.SendAllNotifications(db, metadata.Alert, check); err != nil {
-                    log.Printf("Failed to send notifications for check %d: %v", check.ID, err)
-                }
-            }()
+    // Check if we should trigger an alert (flapping checks get one FLAPPING / STABILIZED pair instead)
+    prevUp := previousUpState(check)
+    var raised *models.Alert
+    flapping := updateFlapping(db, &check, result, now)
+    if !flapping {
+        if shouldAlert, alertType := shouldTriggerAlert(notifiedUpState(check), result.Success, check.LastAlertAt, check.SuppressionWindow()); shouldAlert {
+            raised = raiseAlert(db, check, alertType, result.StatusCode, errorMsg, now)
+        }
+    }
+    // Stop paging and close the incident as soon as the check is back up, even if the RECOVERY alert is suppressed.
+    // Flapping checks keep their incident open until they stabilize and recover.
+    recovered := (prevUp != nil && !*prevUp) || (raised != nil && raised.AlertType == models.AlertTypeRecovery)
+    if result.Success && recovered && !flapping {
+        if err := oncall.ResolveEscalations(db, check.ID); err != nil {
+            log.Printf("Error resolving escalations for check %d: %v", check.ID, err)
+        }
+        if err := incidents.ResolveForCheck(db, check.ID, raised, now); err != nil {
+            log.Printf("Error resolving incident for check %d: %v", check.ID, err)
         }
     }
+    // Compare the response time with the check's baseline for this hour of the week
+    updateAnomaly(db, &check, result, now)
+    // Alert when the security grade gets worse than the last audited run
+    if models.SecurityGradeDropped(check.LastSecurityGrade, result.SecurityGrade) {
+        msg := fmt.Sprintf("Security grade dropped from %s to %s: %s",
+            check.LastSecurityGrade, result.SecurityGrade, failedFindingSummary(result.SecurityFindings))
+        raiseAlert(db, check, models.AlertTypeSecurityRegression, result.StatusCode, msg, now)
+    }
     // Update the check's last status and last_checked_at
     updates := map[string]interface{}{
         "last_status":     result.StatusCode,
+        "last_success":    result.Success,
         "last_checked_at": now,
     }
+    if result.SecurityGrade != "" {
+        updates["last_security_grade"] = result.SecurityGrade
+    }
     if err := db.Model(&models.Check{}).Where("id = ?", check.ID).Updates(updates).Error; err != nil {
         log.Printf("Error updating check %d status: %v", check.ID, err)
     }
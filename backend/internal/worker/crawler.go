@@ -0,0 +1,145 @@
+package worker
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"golang.org/x/net/html"
+)
+
+const (
+	// crawlPageTimeout bounds each page fetch so one slow page can't stall the crawl
+	crawlPageTimeout = 10 * time.Second
+	// crawlBudget bounds the whole crawl; pages not reached in time are not checked
+	crawlBudget = 2 * time.Minute
+	// maxCrawlBodyBytes caps how much of each page is parsed for links
+	maxCrawlBodyBytes = 2 << 20
+)
+
+// crawlTarget is a queued page and the page that linked to it
+type crawlTarget struct {
+	URL     string
+	Depth   int
+	FoundOn string
+}
+
+// crawlSite follows same-origin links from an already-fetched root page, breadth first,
+// up to maxDepth links away and maxPages pages in total (including the root).
+// It returns the number of pages fetched and the ones that were broken.
+func crawlSite(root *http.Response, maxDepth, maxPages int) (int, models.BrokenLinks) {
+	broken := models.BrokenLinks{}
+	if root.Request == nil {
+		return 1, broken
+	}
+	origin := root.Request.URL
+	visited := map[string]bool{normalizeCrawlURL(origin): true}
+
+	var queue []crawlTarget
+	enqueue := func(page *url.URL, body io.Reader, depth int) {
+		if depth >= maxDepth {
+			return
+		}
+		for _, link := range extractLinks(page, body) {
+			if !sameOrigin(origin, link) {
+				continue
+			}
+			key := normalizeCrawlURL(link)
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			queue = append(queue, crawlTarget{URL: key, Depth: depth + 1, FoundOn: page.String()})
+		}
+	}
+
+	if isHTML(root) {
+		enqueue(origin, root.Body, 0)
+	}
+
+	client := &http.Client{Timeout: crawlPageTimeout}
+	deadline := time.Now().Add(crawlBudget)
+	pages := 1
+	for len(queue) > 0 && pages < maxPages && time.Now().Before(deadline) {
+		target := queue[0]
+		queue = queue[1:]
+		pages++
+
+		resp, err := client.Get(target.URL)
+		if err != nil {
+			broken = append(broken, models.BrokenLink{URL: target.URL, Error: err.Error(), FoundOn: target.FoundOn})
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			broken = append(broken, models.BrokenLink{
+				URL:        target.URL,
+				StatusCode: resp.StatusCode,
+				Error:      http.StatusText(resp.StatusCode),
+				FoundOn:    target.FoundOn,
+			})
+		} else if isHTML(resp) && sameOrigin(origin, resp.Request.URL) {
+			enqueue(resp.Request.URL, resp.Body, target.Depth)
+		}
+		resp.Body.Close()
+	}
+	return pages, broken
+}
+
+// brokenLinksMessage summarizes broken links for the result's error message
+func brokenLinksMessage(broken models.BrokenLinks) string {
+	if len(broken) == 1 {
+		return fmt.Sprintf("1 broken link: %s", broken[0].URL)
+	}
+	return fmt.Sprintf("%d broken links, first: %s", len(broken), broken[0].URL)
+}
+
+// extractLinks returns the absolute http(s) URLs of all <a href> links on a page
+func extractLinks(page *url.URL, body io.Reader) []*url.URL {
+	var links []*url.URL
+	tokenizer := html.NewTokenizer(io.LimitReader(body, maxCrawlBodyBytes))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			if string(name) != "a" || !hasAttr {
+				continue
+			}
+			for {
+				key, value, more := tokenizer.TagAttr()
+				if string(key) == "href" {
+					if link, err := page.Parse(strings.TrimSpace(string(value))); err == nil &&
+						(link.Scheme == "http" || link.Scheme == "https") {
+						links = append(links, link)
+					}
+				}
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
+
+// sameOrigin reports whether two URLs share scheme and host
+func sameOrigin(a, b *url.URL) bool {
+	return a.Scheme == b.Scheme && strings.EqualFold(a.Host, b.Host)
+}
+
+// normalizeCrawlURL strips fragments so page#a and page#b are crawled once
+func normalizeCrawlURL(u *url.URL) string {
+	normalized := *u
+	normalized.Fragment = ""
+	normalized.RawFragment = ""
+	return normalized.String()
+}
+
+// isHTML reports whether a response is an HTML page worth parsing for links
+func isHTML(resp *http.Response) bool {
+	return strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/html")
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"log"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/digest"
+	"github.com/oFuterman/light-house/internal/logalerts"
+	"github.com/oFuterman/light-house/internal/oncall"
+	"github.com/oFuterman/light-house/internal/tracealerts"
+	"gorm.io/gorm"
+)
+
+const (
+	escalationInterval  = 30 * time.Second
+	escalationBatchSize = 50
+	digestInterval      = time.Minute
+	digestBatchSize     = 20
+	logAlertInterval    = 15 * time.Second
+	logAlertBatchSize   = 50
+	traceAlertInterval  = 15 * time.Second
+	traceAlertBatchSize = 50
+)
+
+// processDueFunc handles up to limit due items and returns how many it handled
+type processDueFunc func(db *gorm.DB, limit int) (int, error)
+
+// runDueWorker calls process every interval, draining full batches until a short
+// one shows nothing more is due
+func runDueWorker(db *gorm.DB, name string, interval time.Duration, batchSize int, process processDueFunc) {
+	log.Printf("Starting %s worker...", name)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for {
+			processed, err := process(db, batchSize)
+			if err != nil {
+				log.Printf("Error in %s worker: %v", name, err)
+				break
+			}
+			if processed < batchSize {
+				break
+			}
+		}
+	}
+}
+
+// StartEscalationWorker advances escalation policies for unacknowledged DOWN alerts
+func StartEscalationWorker(db *gorm.DB) {
+	runDueWorker(db, "escalation", escalationInterval, escalationBatchSize, oncall.ProcessDueEscalations)
+}
+
+// StartDigestWorker sends scheduled uptime digests
+func StartDigestWorker(db *gorm.DB) {
+	runDueWorker(db, "digest", digestInterval, digestBatchSize, digest.ProcessDue)
+}
+
+// StartLogAlertWorker evaluates log alert rules as they come due
+func StartLogAlertWorker(db *gorm.DB) {
+	runDueWorker(db, "log alert", logAlertInterval, logAlertBatchSize, logalerts.ProcessDue)
+}
+
+// StartTraceAlertWorker evaluates trace alert rules as they come due
+func StartTraceAlertWorker(db *gorm.DB) {
+	runDueWorker(db, "trace alert", traceAlertInterval, traceAlertBatchSize, tracealerts.ProcessDue)
+}
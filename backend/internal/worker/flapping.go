@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+	"gorm.io/gorm"
+)
+
+// stateChangeRate returns the percentage of consecutive recent results that changed
+// state, and how many results it was measured over (the latest result included)
+func stateChangeRate(db *gorm.DB, checkID uint) (rate int, samples int, err error) {
+	var successes []bool
+	if err := db.Model(&models.CheckResult{}).
+		Where("check_id = ?", checkID).
+		Order("created_at DESC").
+		Limit(models.FlapWindowResults).
+		Pluck("success", &successes).Error; err != nil {
+		return 0, 0, err
+	}
+	if len(successes) < 2 {
+		return 0, len(successes), nil
+	}
+	changes := 0
+	for i := 1; i < len(successes); i++ {
+		if successes[i] != successes[i-1] {
+			changes++
+		}
+	}
+	return changes * 100 / (len(successes) - 1), len(successes), nil
+}
+
+// updateFlapping moves the check in and out of the FLAPPING state and sends the single
+// FLAPPING / STABILIZED notification for each move. It returns whether the check is
+// flapping after this run; up/down alerting is paused while it is, and resumes from the
+// last notified state once it stabilizes (so a check that settles DOWN still opens an incident).
+func updateFlapping(db *gorm.DB, check *models.Check, result models.CheckResult, now time.Time) bool {
+	threshold := check.FlapThreshold()
+	if threshold == 0 {
+		// Detection was turned off while flapping; resume normal alerting quietly
+		if check.IsFlapping {
+			db.Model(&models.Check{}).Where("id = ?", check.ID).
+				Updates(map[string]interface{}{"is_flapping": false, "flapping_since": nil})
+		}
+		return false
+	}
+
+	rate, samples, err := stateChangeRate(db, check.ID)
+	if err != nil {
+		log.Printf("Error measuring state changes for check %d: %v", check.ID, err)
+		return check.IsFlapping
+	}
+
+	switch {
+	case !check.IsFlapping && samples == models.FlapWindowResults && rate >= threshold:
+		if err := db.Model(&models.Check{}).Where("id = ?", check.ID).
+			Updates(map[string]interface{}{"is_flapping": true, "flapping_since": now}).Error; err != nil {
+			log.Printf("Error marking check %d as flapping: %v", check.ID, err)
+			return false
+		}
+		msg := fmt.Sprintf("Changed state in %d%% of the last %d checks; up/down alerts are paused until it stabilizes", rate, samples)
+		raiseAlert(db, *check, models.AlertTypeFlapping, result.StatusCode, msg, now)
+		return true
+
+	case check.IsFlapping && rate*2 < threshold:
+		// Hysteresis: stabilizing needs half the entry rate so a check hovering at the threshold doesn't toggle
+		if err := db.Model(&models.Check{}).Where("id = ?", check.ID).
+			Updates(map[string]interface{}{"is_flapping": false, "flapping_since": nil}).Error; err != nil {
+			log.Printf("Error clearing flapping state for check %d: %v", check.ID, err)
+			return true
+		}
+		state := "DOWN"
+		if result.Success {
+			state = "UP"
+		}
+		msg := fmt.Sprintf("Stabilized %s", state)
+		if check.FlappingSince != nil {
+			msg = fmt.Sprintf("Stabilized %s after flapping for %s", state, now.Sub(*check.FlappingSince).Round(time.Minute))
+		}
+		if !result.Success && result.ErrorMessage != "" {
+			msg += ": " + result.ErrorMessage
+		}
+		raiseAlert(db, *check, models.AlertTypeStabilized, result.StatusCode, msg, now)
+		return false
+	}
+	return check.IsFlapping
+}
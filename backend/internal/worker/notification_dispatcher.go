@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/notifier"
+	"gorm.io/gorm"
+)
+
+const (
+	dispatchInterval  = 5 * time.Second
+	dispatchBatchSize = 20
+)
+
+// StartNotificationDispatcher drains the notification outbox. It is safe to run
+// on every replica; jobs are claimed with row locks so each is sent by one dispatcher.
+func StartNotificationDispatcher(db *gorm.DB) {
+	log.Println("Starting notification dispatcher...")
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		dispatchDueNotifications(db)
+	}
+}
+
+// dispatchDueNotifications claims and sends batches until nothing is due
+func dispatchDueNotifications(db *gorm.DB) {
+	for {
+		jobs, err := notifier.ClaimDueJobs(db, dispatchBatchSize)
+		if err != nil {
+			log.Printf("Error claiming notification jobs: %v", err)
+			return
+		}
+		if len(jobs) == 0 {
+			return
+		}
+		var wg sync.WaitGroup
+		for _, job := range jobs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := notifier.ProcessJob(db, job); err != nil {
+					log.Printf("Notification job %d (alert %d) failed: %v", job.ID, job.AlertID, err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
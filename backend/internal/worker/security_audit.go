@@ -0,0 +1,207 @@
+package worker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/models"
+)
+
+// hstsMinMaxAge is the minimum HSTS max-age (180 days) considered adequate
+const hstsMinMaxAge = 180 * 24 * 60 * 60
+
+// severityPenalty is how many points a failed finding costs out of 100
+var severityPenalty = map[string]int{
+	models.SeverityHigh:   30,
+	models.SeverityMedium: 15,
+	models.SeverityLow:    5,
+}
+
+// auditSecurity grades the security headers and HTTPS hygiene of a completed check response.
+// checkURL is the configured URL; resp is the final response after redirects.
+func auditSecurity(checkURL string, resp *http.Response) (string, models.SecurityFindings) {
+	findings := models.SecurityFindings{
+		auditHTTPSRedirect(checkURL),
+		auditHSTS(resp),
+		auditCSP(resp),
+		auditContentTypeOptions(resp),
+	}
+	findings = append(findings, auditCookies(resp)...)
+	return securityGrade(findings), findings
+}
+
+// securityGrade converts findings into an A-F letter grade
+func securityGrade(findings models.SecurityFindings) string {
+	score := 100
+	for _, f := range findings {
+		if !f.Passed {
+			score -= severityPenalty[f.Severity]
+		}
+	}
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+// auditHTTPSRedirect verifies that the plain HTTP version of the URL redirects to HTTPS
+func auditHTTPSRedirect(checkURL string) models.SecurityFinding {
+	finding := models.SecurityFinding{Check: models.SecurityCheckHTTPSRedirect, Severity: models.SeverityHigh}
+
+	u, err := url.Parse(checkURL)
+	if err != nil {
+		finding.Message = "check URL could not be parsed"
+		return finding
+	}
+	u.Scheme = "http"
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		// Nothing listening on plain HTTP is just as safe as a redirect
+		finding.Passed = true
+		finding.Message = "plain HTTP is not served"
+		return finding
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		location, err := resp.Location()
+		if err == nil && location.Scheme == "https" {
+			finding.Passed = true
+			finding.Message = fmt.Sprintf("plain HTTP redirects to HTTPS (%d)", resp.StatusCode)
+			return finding
+		}
+		finding.Message = "plain HTTP redirects, but not to HTTPS"
+		return finding
+	}
+	finding.Message = fmt.Sprintf("plain HTTP is served without redirecting to HTTPS (%d)", resp.StatusCode)
+	return finding
+}
+
+// auditHSTS checks for a Strict-Transport-Security header with an adequate max-age
+func auditHSTS(resp *http.Response) models.SecurityFinding {
+	finding := models.SecurityFinding{Check: models.SecurityCheckHSTS, Severity: models.SeverityHigh}
+
+	if resp.Request == nil || resp.Request.URL.Scheme != "https" {
+		finding.Message = "response was not served over HTTPS"
+		return finding
+	}
+	header := resp.Header.Get("Strict-Transport-Security")
+	if header == "" {
+		finding.Message = "Strict-Transport-Security header is missing"
+		return finding
+	}
+
+	maxAge := -1
+	for _, directive := range strings.Split(header, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if strings.EqualFold(name, "max-age") {
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				maxAge = n
+			}
+		}
+	}
+	if maxAge < hstsMinMaxAge {
+		finding.Severity = models.SeverityLow
+		finding.Message = fmt.Sprintf("Strict-Transport-Security max-age is below %d seconds", hstsMinMaxAge)
+		return finding
+	}
+	finding.Passed = true
+	finding.Message = "Strict-Transport-Security is set"
+	return finding
+}
+
+// auditCSP checks for an enforced Content-Security-Policy
+func auditCSP(resp *http.Response) models.SecurityFinding {
+	finding := models.SecurityFinding{Check: models.SecurityCheckCSP, Severity: models.SeverityMedium}
+
+	if resp.Header.Get("Content-Security-Policy") != "" {
+		finding.Passed = true
+		finding.Message = "Content-Security-Policy is set"
+		return finding
+	}
+	if resp.Header.Get("Content-Security-Policy-Report-Only") != "" {
+		finding.Severity = models.SeverityLow
+		finding.Message = "Content-Security-Policy is only in report-only mode"
+		return finding
+	}
+	finding.Message = "Content-Security-Policy header is missing"
+	return finding
+}
+
+// auditContentTypeOptions checks for X-Content-Type-Options: nosniff
+func auditContentTypeOptions(resp *http.Response) models.SecurityFinding {
+	finding := models.SecurityFinding{Check: models.SecurityCheckContentTypeOptions, Severity: models.SeverityMedium}
+
+	if strings.EqualFold(strings.TrimSpace(resp.Header.Get("X-Content-Type-Options")), "nosniff") {
+		finding.Passed = true
+		finding.Message = "X-Content-Type-Options is nosniff"
+		return finding
+	}
+	finding.Message = "X-Content-Type-Options: nosniff is missing"
+	return finding
+}
+
+// auditCookies checks every Set-Cookie for the Secure and HttpOnly flags.
+// No findings are returned when the response sets no cookies.
+func auditCookies(resp *http.Response) models.SecurityFindings {
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return nil
+	}
+
+	var insecure, scriptable []string
+	for _, cookie := range cookies {
+		if !cookie.Secure {
+			insecure = append(insecure, cookie.Name)
+		}
+		if !cookie.HttpOnly {
+			scriptable = append(scriptable, cookie.Name)
+		}
+	}
+
+	secure := models.SecurityFinding{Check: models.SecurityCheckCookieSecure, Severity: models.SeverityMedium, Passed: len(insecure) == 0}
+	if secure.Passed {
+		secure.Message = "all cookies are Secure"
+	} else {
+		secure.Message = "cookies missing the Secure flag: " + strings.Join(insecure, ", ")
+	}
+
+	httpOnly := models.SecurityFinding{Check: models.SecurityCheckCookieHTTPOnly, Severity: models.SeverityLow, Passed: len(scriptable) == 0}
+	if httpOnly.Passed {
+		httpOnly.Message = "all cookies are HttpOnly"
+	} else {
+		httpOnly.Message = "cookies missing the HttpOnly flag: " + strings.Join(scriptable, ", ")
+	}
+
+	return models.SecurityFindings{secure, httpOnly}
+}
+
+// failedFindingSummary lists the failed checks for alert messages
+func failedFindingSummary(findings models.SecurityFindings) string {
+	var failed []string
+	for _, f := range findings {
+		if !f.Passed {
+			failed = append(failed, f.Message)
+		}
+	}
+	return strings.Join(failed, "; ")
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"log"
+	"time"
+
+	"github.com/oFuterman/light-house/internal/billing"
+	"gorm.io/gorm"
+)
+
+const (
+	usageThresholdInterval  = 15 * time.Minute
+	usageThresholdBatchSize = 100
+)
+
+// StartUsageThresholdWorker tells org owners when plan usage reaches 80%, 100% or goes over a limit
+func StartUsageThresholdWorker(db *gorm.DB) {
+	log.Println("Starting usage threshold worker...")
+	ticker := time.NewTicker(usageThresholdInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := billing.ProcessUsageThresholds(db, usageThresholdBatchSize); err != nil {
+			log.Printf("Error evaluating usage thresholds: %v", err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package webhook verifies the signed webhooks Light House sends to webhook
+// notification channels and users' personal webhooks.
+//
+// Every delivery is a JSON POST carrying three headers:
+//
+//	X-Lighthouse-Signature: t=1700000000,v1=5257a869...
+//	X-Lighthouse-Event-Id:  evt_42_7
+//	X-Lighthouse-Schema:    1
+//
+// The signature is a hex HMAC-SHA256 of "<t>.<raw body>" keyed with the channel's
+// signing secret, or for a personal webhook the secret shown in the user's
+// notification preferences. While a secret is being rotated the header carries one
+// v1 entry per active secret; a request is valid if any of them matches.
+//
+// To guard against replays, reject requests whose timestamp is too old (Verify does
+// this) and remember recently seen event IDs: retries of the same alert to the same
+// channel reuse the event ID.
+//
+// Typical use in an HTTP handler:
+//
+//	body, err := webhook.VerifyRequest(r, os.Getenv("LIGHTHOUSE_WEBHOOK_SECRET"), webhook.DefaultTolerance)
+//	if err != nil {
+//		http.Error(w, "invalid signature", http.StatusUnauthorized)
+//		return
+//	}
+//	var payload webhook.Payload
+//	_ = json.Unmarshal(body, &payload)
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header names set on every webhook delivery
+const (
+	SignatureHeader = "X-Lighthouse-Signature"
+	EventIDHeader   = "X-Lighthouse-Event-Id"
+	SchemaHeader    = "X-Lighthouse-Schema"
+)
+
+// SchemaVersion is the version of Payload. It only changes on breaking changes;
+// new fields may be added within a version.
+const SchemaVersion = "1"
+
+// DefaultTolerance is the maximum accepted age (or clock skew) of a signature timestamp
+const DefaultTolerance = 5 * time.Minute
+
+// maxBodyBytes caps how much of a request body VerifyRequest reads
+const maxBodyBytes = 1 << 20
+
+var (
+	ErrMissingSignature   = errors.New("webhook: missing signature header")
+	ErrInvalidHeader      = errors.New("webhook: malformed signature header")
+	ErrTimestampTolerance = errors.New("webhook: timestamp outside tolerance")
+	ErrSignatureMismatch  = errors.New("webhook: no matching signature")
+)
+
+// Payload is the JSON body of a webhook delivery (schema version 1)
+type Payload struct {
+	SchemaVersion string    `json:"schema_version"`
+	EventID       string    `json:"event_id"`
+	Event         string    `json:"event"` // Alert type, e.g. DOWN or RECOVERY
+	AlertID       uint      `json:"alert_id"`
+	CheckID       uint      `json:"check_id"`
+	CheckName     string    `json:"check_name"`
+	CheckURL      string    `json:"check_url"`
+	StatusCode    int       `json:"status_code"`
+	ErrorMessage  string    `json:"error_message,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+	// Log, trace and external alerts have no check: CheckID is 0 and CheckName is
+	// the rule name or the external alert's alertname
+	LogAlertRuleID   *uint             `json:"log_alert_rule_id,omitempty"`
+	Link             string            `json:"link,omitempty"` // Log search the alert was raised for, or the external source URL
+	TraceAlertRuleID *uint             `json:"trace_alert_rule_id,omitempty"`
+	TraceIDs         []string          `json:"trace_ids,omitempty"` // Example slow or failing traces
+	ExternalAlertID  *uint             `json:"external_alert_id,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"` // Alertmanager or Grafana labels
+}
+
+// Sign computes the v1 signature of body at timestamp t
+func Sign(secret string, t time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", t.Unix())
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaderValue builds the signature header for a body signed with each secret
+func SignatureHeaderValue(secrets []string, t time.Time, body []byte) string {
+	parts := []string{"t=" + strconv.FormatInt(t.Unix(), 10)}
+	for _, secret := range secrets {
+		parts = append(parts, "v1="+Sign(secret, t, body))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Verify checks a signature header against the raw body. A tolerance of zero
+// disables the timestamp check, which should only be done in tests.
+func Verify(header string, body []byte, secret string, tolerance time.Duration) error {
+	if header == "" {
+		return ErrMissingSignature
+	}
+
+	var timestamp int64 = -1
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidHeader
+		}
+		switch key {
+		case "t":
+			ts, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return ErrInvalidHeader
+			}
+			timestamp = ts
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp < 0 || len(signatures) == 0 {
+		return ErrInvalidHeader
+	}
+
+	signedAt := time.Unix(timestamp, 0)
+	if tolerance > 0 {
+		age := time.Since(signedAt)
+		if age > tolerance || age < -tolerance {
+			return ErrTimestampTolerance
+		}
+	}
+
+	expected, _ := hex.DecodeString(Sign(secret, signedAt, body))
+	for _, sig := range signatures {
+		given, err := hex.DecodeString(sig)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(expected, given) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// VerifyRequest reads the request body and verifies its signature.
+// It returns the body so the caller can decode it.
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("webhook: reading body: %w", err)
+	}
+	if err := Verify(r.Header.Get(SignatureHeader), body, secret, tolerance); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
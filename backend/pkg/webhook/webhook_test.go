@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret    = "whsec_current"
+	testOldSecret = "whsec_previous"
+)
+
+var testBody = []byte(`{"schema_version":"1","event_id":"evt_42_7","event":"DOWN"}`)
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		header    string
+		body      []byte
+		secret    string
+		tolerance time.Duration
+		want      error
+	}{
+		{
+			name:      "valid signature",
+			header:    SignatureHeaderValue([]string{testSecret}, now, testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+		},
+		{
+			name:      "wrong secret",
+			header:    SignatureHeaderValue([]string{"whsec_other"}, now, testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrSignatureMismatch,
+		},
+		{
+			name:      "tampered body",
+			header:    SignatureHeaderValue([]string{testSecret}, now, testBody),
+			body:      []byte(string(testBody) + " "),
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrSignatureMismatch,
+		},
+		{
+			name:      "signature not hex",
+			header:    "t=" + strconv.FormatInt(now.Unix(), 10) + ",v1=zz",
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrSignatureMismatch,
+		},
+		{
+			name:      "missing header",
+			header:    "",
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrMissingSignature,
+		},
+		{
+			name:      "missing timestamp",
+			header:    "v1=" + Sign(testSecret, now, testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrInvalidHeader,
+		},
+		{
+			name:      "malformed timestamp",
+			header:    "t=yesterday,v1=" + Sign(testSecret, now, testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrInvalidHeader,
+		},
+		{
+			name:      "missing signature",
+			header:    "t=" + strconv.FormatInt(now.Unix(), 10),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrInvalidHeader,
+		},
+		{
+			name:      "part without value",
+			header:    SignatureHeaderValue([]string{testSecret}, now, testBody) + ",v1",
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrInvalidHeader,
+		},
+		{
+			name:      "stale timestamp",
+			header:    SignatureHeaderValue([]string{testSecret}, now.Add(-DefaultTolerance-time.Minute), testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrTimestampTolerance,
+		},
+		{
+			name:      "timestamp in the future",
+			header:    SignatureHeaderValue([]string{testSecret}, now.Add(DefaultTolerance+time.Minute), testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrTimestampTolerance,
+		},
+		{
+			name:      "old timestamp within tolerance",
+			header:    SignatureHeaderValue([]string{testSecret}, now.Add(-DefaultTolerance+time.Minute), testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+		},
+		{
+			name:      "zero tolerance skips the timestamp check",
+			header:    SignatureHeaderValue([]string{testSecret}, now.Add(-24*time.Hour), testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: 0,
+		},
+		{
+			name:      "rotation verifies with the new secret",
+			header:    SignatureHeaderValue([]string{testSecret, testOldSecret}, now, testBody),
+			body:      testBody,
+			secret:    testSecret,
+			tolerance: DefaultTolerance,
+		},
+		{
+			name:      "rotation verifies with the previous secret",
+			header:    SignatureHeaderValue([]string{testSecret, testOldSecret}, now, testBody),
+			body:      testBody,
+			secret:    testOldSecret,
+			tolerance: DefaultTolerance,
+		},
+		{
+			name:      "previous secret no longer sent",
+			header:    SignatureHeaderValue([]string{testSecret}, now, testBody),
+			body:      testBody,
+			secret:    testOldSecret,
+			tolerance: DefaultTolerance,
+			want:      ErrSignatureMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.header, tt.body, tt.secret, tt.tolerance)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureHeaderValue(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	got := SignatureHeaderValue([]string{testSecret, testOldSecret}, at, testBody)
+	want := "t=1700000000,v1=" + Sign(testSecret, at, testBody) + ",v1=" + Sign(testOldSecret, at, testBody)
+	if got != want {
+		t.Errorf("SignatureHeaderValue() = %q, want %q", got, want)
+	}
+	if Sign(testSecret, at, testBody) == Sign(testSecret, at.Add(time.Second), testBody) {
+		t.Error("Sign() does not cover the timestamp")
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "/hooks/lighthouse", bytes.NewReader(testBody))
+	r.Header.Set(SignatureHeader, SignatureHeaderValue([]string{testSecret}, time.Now(), testBody))
+	body, err := VerifyRequest(r, testSecret, DefaultTolerance)
+	if err != nil {
+		t.Fatalf("VerifyRequest() error = %v", err)
+	}
+	if !bytes.Equal(body, testBody) {
+		t.Errorf("VerifyRequest() body = %q, want %q", body, testBody)
+	}
+
+	r = httptest.NewRequest("POST", "/hooks/lighthouse", bytes.NewReader(testBody))
+	if _, err := VerifyRequest(r, testSecret, DefaultTolerance); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("VerifyRequest() without signature error = %v, want %v", err, ErrMissingSignature)
+	}
+}
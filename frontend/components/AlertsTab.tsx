@@ -1,21 +1,40 @@
 "use client";
 
 import { useCheckAlerts } from "@/hooks/useCheckAlerts";
+import { AlertType } from "@/lib/api";
 
 interface AlertsTabProps {
   checkId: string;
   windowHours: number;
 }
 
-function AlertTypeBadge({ type }: { type: "DOWN" | "RECOVERY" }) {
-  const isDown = type === "DOWN";
+const FIRING_STYLE = "bg-red-100 text-red-800";
+const RESOLVED_STYLE = "bg-green-100 text-green-800";
+const INFO_STYLE = "bg-gray-100 text-gray-700";
+
+const ALERT_TYPE_STYLES: Record<AlertType, string> = {
+  DOWN: FIRING_STYLE,
+  SECURITY_REGRESSION: FIRING_STYLE,
+  FLAPPING: FIRING_STYLE,
+  LOG_ALERT: FIRING_STYLE,
+  TRACE_ALERT: FIRING_STYLE,
+  LATENCY_ANOMALY: FIRING_STYLE,
+  EXTERNAL_FIRING: FIRING_STYLE,
+  RECOVERY: RESOLVED_STYLE,
+  STABILIZED: RESOLVED_STYLE,
+  LOG_RESOLVED: RESOLVED_STYLE,
+  TRACE_RESOLVED: RESOLVED_STYLE,
+  LATENCY_NORMAL: RESOLVED_STYLE,
+  EXTERNAL_RESOLVED: RESOLVED_STYLE,
+  TEST: INFO_STYLE,
+};
+
+function AlertTypeBadge({ type }: { type: AlertType }) {
+  // Types added to the backend later render as informational until mapped here
+  const style = ALERT_TYPE_STYLES[type] ?? INFO_STYLE;
   return (
-    <span
-      className={`inline-flex items-center px-2 py-0.5 rounded text-xs font-medium ${
-        isDown ? "bg-red-100 text-red-800" : "bg-green-100 text-green-800"
-      }`}
-    >
-      {type}
+    <span className={`inline-flex items-center px-2 py-0.5 rounded text-xs font-medium ${style}`}>
+      {type.replace(/_/g, " ")}
     </span>
   );
 }
@@ -2,12 +2,13 @@
 
 import { useState, useCallback, useEffect, useRef } from "react";
 import Link from "next/link";
+import { useSearchParams } from "next/navigation";
 import { LogEntry } from "@/lib/api";
 import { Loading } from "@/components/ui/Loading";
 import { ErrorState } from "@/components/ui/ErrorState";
 import { LogsSearchBar } from "@/components/LogsSearchBar";
 import { useLogsSearch } from "@/hooks/useLogsSearch";
-import { LogFilter, filtersToSearchRequest, createFilter, isDuplicateFilter, SortConfig } from "@/lib/logs-filter";
+import { LogFilter, filtersToSearchRequest, searchRequestToFilters, createFilter, isDuplicateFilter, SortConfig } from "@/lib/logs-filter";
 import { TimeRange } from "@/components/TimeRangePicker";
 
 // Level colors - left border style like Datadog
@@ -385,9 +386,26 @@ export function LogsContent() {
     isTimestamp: boolean;
   } | null>(null);
 
+  const searchParams = useSearchParams();
+
   const { data: logs, total, isLoading, isLoadingMore, error, search, refetch, loadMore, hasMore } = useLogsSearch();
   const loadMoreTriggerRef = useRef<HTMLDivElement>(null);
 
+  // Apply a search from the URL (e.g., a link from a log alert)
+  useEffect(() => {
+    const param = searchParams.get("search");
+    if (!param) return;
+    try {
+      const linked = searchRequestToFilters(JSON.parse(param));
+      setFilters(linked.filters);
+      if (linked.timeRange) {
+        setTimeRange(linked.timeRange);
+      }
+    } catch {
+      // Ignore malformed links and keep the default search
+    }
+  }, [searchParams]);
+
   // Auto-execute search when filters, time range, or sort changes
   const executeSearch = useCallback(() => {
     const request = filtersToSearchRequest(filters, timeRange, sortConfig);
@@ -65,6 +65,7 @@ export const API_KEY_SCOPES: APIKeyScopeInfo[] = [
   { value: "checks:read", label: "Read Checks", description: "View uptime checks" },
   { value: "checks:write", label: "Write Checks", description: "Create/update checks" },
   { value: "alerts:read", label: "Read Alerts", description: "View alerts" },
+  { value: "alerts:write", label: "Write Alerts", description: "Send Alertmanager and Grafana alerts" },
   { value: "*", label: "Full Access", description: "All permissions" },
 ];
 
@@ -178,12 +179,34 @@ export interface NotificationSettings {
   webhook_url?: string | null;
 }
 
+// Mirrors the backend's alert types: firing types, the types that resolve them, and TEST
+export type AlertType =
+  | "DOWN"
+  | "RECOVERY"
+  | "SECURITY_REGRESSION"
+  | "FLAPPING"
+  | "STABILIZED"
+  | "TEST"
+  | "LOG_ALERT"
+  | "LOG_RESOLVED"
+  | "TRACE_ALERT"
+  | "TRACE_RESOLVED"
+  | "LATENCY_ANOMALY"
+  | "LATENCY_NORMAL"
+  | "EXTERNAL_FIRING"
+  | "EXTERNAL_RESOLVED";
+
 export interface Alert {
   id: number;
   created_at: string;
-  check_id: number;
+  check_id: number | null; // null for log, trace and external alerts
   check_name?: string;
-  alert_type: "DOWN" | "RECOVERY";
+  log_alert_rule_id?: number;
+  link?: string; // Log search path for log alerts, source URL for external alerts
+  trace_alert_rule_id?: number;
+  trace_ids?: string[]; // Example slow or failing traces for trace alerts
+  external_alert_id?: number; // Set for alerts received from Alertmanager or Grafana
+  alert_type: AlertType;
   status_code: number;
   error_message?: string;
 }
@@ -118,6 +118,36 @@ export function filtersToSearchRequest(
   };
 }
 
+// Inverse of filtersToSearchRequest, used for links such as those in log alerts.
+// Conditions the filter bar cannot express (e.g. != or prefix) are dropped.
+export function searchRequestToFilters(request: SearchRequest): { filters: LogFilter[]; timeRange: TimeRange | null } {
+  const filters: LogFilter[] = [];
+
+  for (const condition of request.filters || []) {
+    const op = condition.op === "=" ? "eq" : condition.op;
+    const expected = condition.field === "message" ? "contains" : "eq";
+    if (op === expected && typeof condition.value === "string") {
+      filters.push(createFilter(condition.field, condition.value));
+    }
+  }
+  for (const tag of request.tags || []) {
+    if (tag.op === "eq" || tag.op === "=") {
+      filters.push({ id: generateFilterId(), field: tag.key, value: tag.value, isTag: true });
+    }
+  }
+
+  let timeRange: TimeRange | null = null;
+  if (request.time_range?.from && request.time_range?.to) {
+    const from = new Date(request.time_range.from);
+    const to = new Date(request.time_range.to);
+    if (!isNaN(from.getTime()) && !isNaN(to.getTime())) {
+      timeRange = { from, to };
+    }
+  }
+
+  return { filters, timeRange };
+}
+
 export function filterToString(filter: LogFilter): string {
   // Use display label for standard fields
   const displayField = STANDARD_FIELDS.find((f) => f.key === filter.field)?.label || filter.field;